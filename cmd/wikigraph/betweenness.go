@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	btwTop     int
+	btwSamples int
+	btwWorkers int
+	btwSeed    uint64
+	btwFormat  string
+)
+
+var betweennessCmd = &cobra.Command{
+	Use:   "betweenness",
+	Short: "Find bridge pages by betweenness centrality",
+	Long: `Find the pages that sit on the most shortest paths.
+
+Betweenness is estimated with Brandes' algorithm from a random sample of
+source pages, processed in parallel. More samples give a better estimate;
+--samples 0 computes it exactly, which is only practical on small graphs.
+Press Ctrl-C to stop a long run.
+
+Examples:
+  wikigraph betweenness
+  wikigraph betweenness --samples 5000 --top 50
+  wikigraph betweenness --samples 0 --format json`,
+	Args: cobra.NoArgs,
+	RunE: runBetweenness,
+}
+
+func init() {
+	rootCmd.AddCommand(betweennessCmd)
+
+	betweennessCmd.Flags().IntVarP(&btwTop, "top", "n", 20, "number of pages to list")
+	betweennessCmd.Flags().IntVar(&btwSamples, "samples", 1000, "number of source pivots (0 = exact)")
+	betweennessCmd.Flags().IntVar(&btwWorkers, "workers", 0, "parallel workers (default: number of CPUs)")
+	betweennessCmd.Flags().Uint64Var(&btwSeed, "seed", 1, "random seed for choosing pivots")
+	betweennessCmd.Flags().StringVarP(&btwFormat, "format", "f", "text", "output format: text, json")
+}
+
+type betweennessOutput struct {
+	Pages      []rankedPageOutput `json:"pages"`
+	Samples    int                `json:"samples"`
+	Exact      bool               `json:"exact"`
+	DurationMs int64              `json:"duration_ms"`
+}
+
+func runBetweenness(cmd *cobra.Command, args []string) error {
+	if btwSamples < 0 {
+		return fmt.Errorf("--samples must not be negative")
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{
+		CachePath:   graphCachePath(),
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	stderr := cmd.ErrOrStderr()
+	lastPercent := -1
+
+	start := time.Now()
+	c, err := graph.Betweenness(ctx, g.Adjacency(), graph.BetweennessOptions{
+		Samples: btwSamples,
+		Workers: btwWorkers,
+		Seed:    btwSeed,
+		Progress: func(done, total int) {
+			if percent := done * 100 / total; percent != lastPercent {
+				lastPercent = percent
+				fmt.Fprintf(stderr, "\rProcessed %d/%d pivots (%d%%)", done, total, percent)
+			}
+		},
+	})
+	fmt.Fprintln(stderr)
+	if errors.Is(err, context.Canceled) {
+		return fmt.Errorf("interrupted after %s", time.Since(start).Round(time.Second))
+	}
+	if err != nil {
+		return fmt.Errorf("computing betweenness: %w", err)
+	}
+
+	out := betweennessOutput{
+		Samples:    c.Samples,
+		Exact:      c.Exact,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	for i, p := range c.Top(btwTop) {
+		out.Pages = append(out.Pages, rankedPageOutput{Rank: i + 1, Title: p.Title, Score: p.Score})
+	}
+
+	if btwFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, p := range out.Pages {
+		fmt.Printf("%4d. %.6f  %s\n", p.Rank, p.Score, p.Title)
+	}
+	fmt.Println()
+	kind := "estimated"
+	if out.Exact {
+		kind = "exact"
+	}
+	fmt.Printf("Betweenness %s from %d pivots in %dms\n", kind, out.Samples, out.DurationMs)
+
+	return nil
+}
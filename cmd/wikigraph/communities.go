@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	communitiesResolution float64
+	communitiesReciprocal float64
+	communitiesSeed       uint64
+	communitiesTop        int
+	communitiesFormat     string
+	communitiesNoSave     bool
+)
+
+var communitiesCmd = &cobra.Command{
+	Use:   "communities",
+	Short: "Detect communities of closely linked pages",
+	Long: `Detect communities of closely linked pages with the Louvain method.
+
+Links are treated as undirected, and pages that link to each other are
+joined more strongly than one-way links. The assignments are saved to the
+database, where the API serves them on pages, on the communities endpoints
+and as colours on connections.
+
+Examples:
+  wikigraph communities
+  wikigraph communities --resolution 1.5 --top 25
+  wikigraph communities --no-save --format json`,
+	Args: cobra.NoArgs,
+	RunE: runCommunities,
+}
+
+func init() {
+	rootCmd.AddCommand(communitiesCmd)
+
+	communitiesCmd.Flags().Float64Var(&communitiesResolution, "resolution", 1, "higher values give more, smaller communities")
+	communitiesCmd.Flags().Float64Var(&communitiesReciprocal, "reciprocal-weight", 2, "weight of pages linking to each other (one-way links weigh 1)")
+	communitiesCmd.Flags().Uint64Var(&communitiesSeed, "seed", 0, "seed for the order pages are visited in")
+	communitiesCmd.Flags().IntVarP(&communitiesTop, "top", "n", 10, "number of largest communities to list")
+	communitiesCmd.Flags().StringVarP(&communitiesFormat, "format", "f", "text", "output format: text, json")
+	communitiesCmd.Flags().BoolVar(&communitiesNoSave, "no-save", false, "do not save the assignments to the database")
+}
+
+type communitiesOutput struct {
+	Nodes       int               `json:"nodes"`
+	Communities int               `json:"communities"`
+	Modularity  float64           `json:"modularity"`
+	Levels      int               `json:"levels"`
+	Largest     []communityOutput `json:"largest"`
+	Saved       bool              `json:"saved"`
+	DurationMs  int64             `json:"duration_ms"`
+}
+
+type communityOutput struct {
+	ID      int    `json:"id"`
+	Size    int    `json:"size"`
+	Example string `json:"example"`
+}
+
+func runCommunities(cmd *cobra.Command, args []string) error {
+	if communitiesResolution <= 0 {
+		return fmt.Errorf("--resolution must be positive")
+	}
+	if communitiesReciprocal <= 0 {
+		return fmt.Errorf("--reciprocal-weight must be positive")
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	c := cache.New(db)
+	loader := graph.NewLoaderWithConfig(c, graph.LoaderConfig{
+		CachePath:   graphCachePath(),
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	comms, err := graph.DetectCommunities(ctx, g.Adjacency(), graph.CommunityOptions{
+		ReciprocalWeight: communitiesReciprocal,
+		Resolution:       communitiesResolution,
+		Seed:             communitiesSeed,
+	})
+	if err != nil {
+		return fmt.Errorf("detecting communities: %w", err)
+	}
+
+	out := communitiesOutput{
+		Nodes:       g.NodeCount(),
+		Communities: comms.Count(),
+		Modularity:  comms.Modularity,
+		Levels:      comms.Levels,
+	}
+
+	// Community IDs are ordered by size, so the largest come first.
+	top := min(max(communitiesTop, 0), comms.Count())
+	out.Largest = make([]communityOutput, top)
+	for i := range out.Largest {
+		out.Largest[i] = communityOutput{ID: i, Size: comms.Size(i)}
+	}
+	found := 0
+	for title, id := range comms.Assignments() {
+		if found == top {
+			break
+		}
+		if id < top && out.Largest[id].Example == "" {
+			out.Largest[id].Example = title
+			found++
+		}
+	}
+
+	if !communitiesNoSave {
+		sizes := make([]int, comms.Count())
+		for i := range sizes {
+			sizes[i] = comms.Size(i)
+		}
+		if err := c.ReplaceCommunities(sizes, comms.Assignments()); err != nil {
+			return fmt.Errorf("saving communities: %w", err)
+		}
+		out.Saved = true
+	}
+	out.DurationMs = time.Since(start).Milliseconds()
+
+	if communitiesFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Pages:       %s\n", formatNumber(out.Nodes))
+	fmt.Printf("Communities: %s\n", formatNumber(out.Communities))
+	fmt.Printf("Modularity:  %.4f\n", out.Modularity)
+	fmt.Printf("Levels:      %d\n", out.Levels)
+
+	fmt.Printf("\nLargest communities:\n")
+	for _, info := range out.Largest {
+		fmt.Printf("  %4d. %10s pages  (e.g. %s)\n", info.ID, formatNumber(info.Size), info.Example)
+	}
+
+	if out.Saved {
+		fmt.Printf("\nSaved assignments to the database.\n")
+	}
+	fmt.Printf("Computed in %dms\n", out.DurationMs)
+
+	return nil
+}
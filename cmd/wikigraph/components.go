@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	componentsTop    int
+	componentsFormat string
+)
+
+var componentsCmd = &cobra.Command{
+	Use:   "components",
+	Short: "Report the strongly connected components of the graph",
+	Long: `Report the strongly connected components of the graph.
+
+Two pages are in the same strongly connected component when each can be
+reached from the other. Pages outside the giant component often explain
+why no path is found between them.
+
+Examples:
+  wikigraph components
+  wikigraph components --top 25 --format json`,
+	Args: cobra.NoArgs,
+	RunE: runComponents,
+}
+
+func init() {
+	rootCmd.AddCommand(componentsCmd)
+
+	componentsCmd.Flags().IntVarP(&componentsTop, "top", "n", 10, "number of largest components to list")
+	componentsCmd.Flags().StringVarP(&componentsFormat, "format", "f", "text", "output format: text, json")
+}
+
+type componentsOutput struct {
+	Nodes        int               `json:"nodes"`
+	Components   int               `json:"components"`
+	GiantSize    int               `json:"giant_size"`
+	GiantPercent float64           `json:"giant_percent"`
+	Singletons   int               `json:"singletons"`
+	Largest      []componentOutput `json:"largest"`
+	DurationMs   int64             `json:"duration_ms"`
+}
+
+type componentOutput struct {
+	ID      int    `json:"id"`
+	Size    int    `json:"size"`
+	Example string `json:"example"`
+}
+
+func runComponents(cmd *cobra.Command, args []string) error {
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{
+		CachePath:   graphCachePath(),
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	sccs, err := graph.StronglyConnectedComponents(ctx, g.Adjacency())
+	if err != nil {
+		return fmt.Errorf("computing components: %w", err)
+	}
+
+	out := componentsOutput{
+		Nodes:      g.NodeCount(),
+		Components: sccs.Count(),
+		Singletons: sccs.Singletons(),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	for _, info := range sccs.Largest(componentsTop) {
+		out.Largest = append(out.Largest, componentOutput{ID: info.ID, Size: info.Size, Example: info.Example})
+	}
+	if giant := sccs.Largest(1); len(giant) > 0 {
+		out.GiantSize = giant[0].Size
+		out.GiantPercent = 100 * float64(giant[0].Size) / float64(out.Nodes)
+	}
+
+	if componentsFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Pages:      %s\n", formatNumber(out.Nodes))
+	fmt.Printf("Components: %s\n", formatNumber(out.Components))
+	fmt.Printf("Giant:      %s pages (%.1f%%)\n", formatNumber(out.GiantSize), out.GiantPercent)
+	fmt.Printf("Singletons: %s\n", formatNumber(out.Singletons))
+
+	fmt.Printf("\nLargest components:\n")
+	for i, info := range out.Largest {
+		fmt.Printf("  %2d. %10s pages  (e.g. %s)\n", i+1, formatNumber(info.Size), info.Example)
+	}
+	fmt.Printf("\nComputed in %dms\n", out.DurationMs)
+
+	return nil
+}
@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	diffPairs       string
+	diffTop         int
+	diffLimit       int
+	diffMaxDepth    int
+	diffMaxExplored int
+	diffFormat      string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff <before> [after]",
+	Short: "Compare the graph between two snapshots or two crawl dates",
+	Long: `Compare the graph between two snapshots or two crawl dates.
+
+Each side is one of:
+
+  now         the graph in the database today (the default for after)
+  cache       the graph cache file from the config
+  a time      the graph in the database as of that time, such as 2025-03-01
+              (the end of that day, UTC) or 2025-03-01T12:00:00Z
+  a path      a graph cache file, such as a copy of the cache saved after
+              an earlier crawl
+
+The graph as of a time is rebuilt from the link history the database
+keeps. Links removed before it started recording them are missing from
+earlier graphs.
+
+The report lists added and removed pages and edges, the pages whose degree
+changed most and, with --pairs, the pairs whose shortest path length
+changed.
+
+Examples:
+  wikigraph diff 2025-03-01
+  wikigraph diff 2025-03-01 2025-04-01 --pairs pairs.csv
+  wikigraph diff graph-march.cache cache --limit 0 --format json`,
+	Args: cobra.RangeArgs(1, 2),
+	RunE: runDiff,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffPairs, "pairs", "", "CSV file of from,to pairs whose path lengths to compare (- for stdin)")
+	diffCmd.Flags().IntVar(&diffTop, "top", 20, "number of pages with the biggest degree changes to list")
+	diffCmd.Flags().IntVar(&diffLimit, "limit", 50, "most pages and edges to list per change (0 = all)")
+	diffCmd.Flags().IntVarP(&diffMaxDepth, "max-depth", "d", 6, "maximum path length to search for --pairs")
+	diffCmd.Flags().IntVar(&diffMaxExplored, "max-explored", 0, "stop each --pairs search after expanding this many pages (0 = no cap)")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "text", "output format: text, json")
+}
+
+type diffOutput struct {
+	Before        diffSide            `json:"before"`
+	After         diffSide            `json:"after"`
+	AddedPages    diffList[string]    `json:"added_pages"`
+	RemovedPages  diffList[string]    `json:"removed_pages"`
+	AddedEdges    diffList[[2]string] `json:"added_edges"`
+	RemovedEdges  diffList[[2]string] `json:"removed_edges"`
+	DegreeChanges []degreeChangeEntry `json:"degree_changes"`
+	PathChanges   []pathChangeEntry   `json:"path_changes,omitempty"`
+	PairsCompared int                 `json:"pairs_compared,omitempty"`
+	DurationMs    int64               `json:"duration_ms"`
+}
+
+type diffSide struct {
+	Source string `json:"source"`
+	Nodes  int    `json:"nodes"`
+	Edges  int    `json:"edges"`
+}
+
+type diffList[T any] struct {
+	Count int `json:"count"`
+	Items []T `json:"items"`
+}
+
+// newDiffList keeps empty lists as [] rather than null in JSON output.
+func newDiffList[T any](count int, items []T) diffList[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return diffList[T]{Count: count, Items: items}
+}
+
+type degreeChangeEntry struct {
+	Title     string `json:"title"`
+	InBefore  int    `json:"in_before"`
+	InAfter   int    `json:"in_after"`
+	OutBefore int    `json:"out_before"`
+	OutAfter  int    `json:"out_after"`
+}
+
+type pathChangeEntry struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Before    *int   `json:"before"`
+	After     *int   `json:"after"`
+	Truncated bool   `json:"truncated,omitempty"`
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	if diffFormat != "text" && diffFormat != "json" {
+		return fmt.Errorf("unknown format %q (want text or json)", diffFormat)
+	}
+
+	var pairs []graph.PathPair
+	if diffPairs != "" {
+		var err error
+		if pairs, err = readPairs(diffPairs); err != nil {
+			return err
+		}
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{Backend: graph.BackendCSR})
+
+	afterArg := "now"
+	if len(args) == 2 {
+		afterArg = args[1]
+	}
+	before, err := loadDiffSide(loader, args[0])
+	if err != nil {
+		return err
+	}
+	defer before.Close()
+	after, err := loadDiffSide(loader, afterArg)
+	if err != nil {
+		return err
+	}
+	defer after.Close()
+
+	// Cache files keep links under canonical titles, so pairs naming
+	// redirects are resolved first.
+	for i, p := range pairs {
+		pairs[i] = graph.PathPair{From: after.Canonical(p.From), To: after.Canonical(p.To)}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	d, err := graph.Diff(ctx, before, after, graph.DiffOptions{
+		Limit:            diffLimit,
+		TopDegreeChanges: diffTop,
+		Pairs:            pairs,
+		MaxDepth:         diffMaxDepth,
+		MaxExplored:      diffMaxExplored,
+	})
+	if err != nil {
+		return fmt.Errorf("comparing graphs: %w", err)
+	}
+
+	out := diffOutput{
+		Before:        diffSide{Source: args[0], Nodes: before.NodeCount(), Edges: before.EdgeCount()},
+		After:         diffSide{Source: afterArg, Nodes: after.NodeCount(), Edges: after.EdgeCount()},
+		AddedPages:    newDiffList(d.AddedPageCount, d.AddedPages),
+		RemovedPages:  newDiffList(d.RemovedPageCount, d.RemovedPages),
+		AddedEdges:    newDiffList(d.AddedEdgeCount, d.AddedEdges),
+		RemovedEdges:  newDiffList(d.RemovedEdgeCount, d.RemovedEdges),
+		DegreeChanges: []degreeChangeEntry{},
+		PairsCompared: len(pairs),
+		DurationMs:    time.Since(start).Milliseconds(),
+	}
+	for _, c := range d.DegreeChanges {
+		out.DegreeChanges = append(out.DegreeChanges, degreeChangeEntry(c))
+	}
+	for _, c := range d.PathChanges {
+		out.PathChanges = append(out.PathChanges, pathChangeEntry{
+			From:      c.From,
+			To:        c.To,
+			Before:    hopsOrNil(c.Before),
+			After:     hopsOrNil(c.After),
+			Truncated: c.Truncated,
+		})
+	}
+
+	if diffFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+	return outputDiffText(out)
+}
+
+// loadDiffSide loads one side of a diff as described in the diff help.
+func loadDiffSide(loader *graph.Loader, arg string) (*graph.CSR, error) {
+	switch arg {
+	case "now":
+		view, err := loader.LoadView()
+		if err != nil {
+			return nil, fmt.Errorf("loading graph: %w", err)
+		}
+		return view.(*graph.CSR), nil
+	case "cache":
+		arg = graphCachePath()
+	default:
+		if t, ok := parseDiffTime(arg); ok {
+			g, err := loader.LoadAt(t)
+			if err != nil {
+				return nil, fmt.Errorf("loading graph as of %s: %w", arg, err)
+			}
+			return g, nil
+		}
+	}
+
+	g, _, err := graph.LoadCSRFromCache(arg)
+	if err != nil {
+		return nil, fmt.Errorf("loading graph cache %s: %w", arg, err)
+	}
+	return g, nil
+}
+
+// parseDiffTime reads a diff side as a time. A bare date stands for the
+// end of that day in UTC.
+func parseDiffTime(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t.Add(24*time.Hour - time.Second), true
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", time.DateTime} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func hopsOrNil(hops int) *int {
+	if hops < 0 {
+		return nil
+	}
+	return &hops
+}
+
+func outputDiffText(out diffOutput) error {
+	fmt.Printf("Before: %s (%s nodes, %s edges)\n", out.Before.Source, formatNumber(out.Before.Nodes), formatNumber(out.Before.Edges))
+	fmt.Printf("After:  %s (%s nodes, %s edges)\n", out.After.Source, formatNumber(out.After.Nodes), formatNumber(out.After.Edges))
+	fmt.Println()
+	fmt.Printf("Pages: +%s -%s\n", formatNumber(out.AddedPages.Count), formatNumber(out.RemovedPages.Count))
+	fmt.Printf("Edges: +%s -%s\n", formatNumber(out.AddedEdges.Count), formatNumber(out.RemovedEdges.Count))
+
+	listPages := func(heading string, l diffList[string]) {
+		if l.Count == 0 {
+			return
+		}
+		fmt.Printf("\n%s%s:\n", heading, shownOf(len(l.Items), l.Count))
+		for _, title := range l.Items {
+			fmt.Printf("  %s\n", title)
+		}
+	}
+	listEdges := func(heading string, l diffList[[2]string]) {
+		if l.Count == 0 {
+			return
+		}
+		fmt.Printf("\n%s%s:\n", heading, shownOf(len(l.Items), l.Count))
+		for _, e := range l.Items {
+			fmt.Printf("  %s → %s\n", e[0], e[1])
+		}
+	}
+	listPages("Added pages", out.AddedPages)
+	listPages("Removed pages", out.RemovedPages)
+	listEdges("Added edges", out.AddedEdges)
+	listEdges("Removed edges", out.RemovedEdges)
+
+	if len(out.DegreeChanges) > 0 {
+		fmt.Println("\nBiggest degree changes:")
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		for _, c := range out.DegreeChanges {
+			fmt.Fprintf(tw, "  %s\tin %d → %d\tout %d → %d\n", c.Title, c.InBefore, c.InAfter, c.OutBefore, c.OutAfter)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if out.PairsCompared > 0 {
+		fmt.Printf("\nPath lengths changed for %d of %d pairs", len(out.PathChanges), out.PairsCompared)
+		if len(out.PathChanges) == 0 {
+			fmt.Println()
+		} else {
+			fmt.Println(":")
+		}
+		hops := func(h *int) string {
+			if h == nil {
+				return "no path"
+			}
+			return fmt.Sprintf("%d hops", *h)
+		}
+		for _, c := range out.PathChanges {
+			note := ""
+			if c.Truncated {
+				note = " (search stopped early)"
+			}
+			fmt.Printf("  %s → %s: %s → %s%s\n", c.From, c.To, hops(c.Before), hops(c.After), note)
+		}
+	}
+
+	fmt.Printf("\nCompared in %dms\n", out.DurationMs)
+	return nil
+}
+
+// shownOf notes how many of a list's entries are shown when it is cut off.
+func shownOf(shown, total int) string {
+	if shown >= total {
+		return ""
+	}
+	return fmt.Sprintf(" (first %s of %s)", formatNumber(shown), formatNumber(total))
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	distanceExact       bool
+	distanceMaxExplored int
+	distanceFormat      string
+)
+
+var distanceCmd = &cobra.Command{
+	Use:   "distance <from> <to>",
+	Short: "Estimate the number of hops between two pages",
+	Long: `Estimate the number of hops between two pages without searching.
+
+Lower and upper bounds come from the landmark distance tables stored next
+to the graph cache (graph.landmarks in the config), so an estimate takes
+microseconds however far apart the pages are. With --exact, a bidirectional
+BFS settles the distance whenever the bounds differ.
+
+Examples:
+  wikigraph distance "Cat" "Philosophy"
+  wikigraph distance "Cat" "Philosophy" --exact
+  wikigraph distance "Cat" "Philosophy" --format json`,
+	Args: cobra.ExactArgs(2),
+	RunE: runDistance,
+}
+
+func init() {
+	rootCmd.AddCommand(distanceCmd)
+
+	distanceCmd.Flags().BoolVar(&distanceExact, "exact", false, "search for the exact distance when the bounds differ")
+	distanceCmd.Flags().IntVar(&distanceMaxExplored, "max-explored", 0, "stop the exact search after expanding this many pages (0 = no cap)")
+	distanceCmd.Flags().StringVarP(&distanceFormat, "format", "f", "text", "output format: text, json")
+}
+
+type distanceOutput struct {
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	Lower           *int              `json:"lower"`
+	Upper           *int              `json:"upper"`
+	Exact           bool              `json:"exact"`
+	Unreachable     bool              `json:"unreachable"`
+	Searched        bool              `json:"searched"`
+	Explored        int               `json:"explored,omitempty"`
+	Truncated       bool              `json:"truncated,omitempty"`
+	BudgetExhausted bool              `json:"budget_exhausted,omitempty"`
+	Redirects       map[string]string `json:"redirects,omitempty"`
+	DurationUs      int64             `json:"duration_us"`
+}
+
+// setBounds records a lower and upper bound; negative values are unknown.
+func (o *distanceOutput) setBounds(lower, upper int) {
+	o.Lower, o.Upper = nil, nil
+	if lower >= 0 {
+		o.Lower = &lower
+	}
+	if upper >= 0 {
+		o.Upper = &upper
+	}
+	o.Exact = lower >= 0 && lower == upper
+}
+
+func runDistance(cmd *cobra.Command, args []string) error {
+	landmarkOpts, err := landmarkOptions()
+	if err != nil {
+		return err
+	}
+	if landmarkOpts.Count <= 0 {
+		return fmt.Errorf("distance estimates need graph.landmarks to be positive")
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	cachePath := graphCachePath()
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{
+		CachePath:   cachePath,
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	landmarks, err := graph.CachedLandmarks(ctx, g.Adjacency(), cachePath, landmarkOpts)
+	if err != nil {
+		return fmt.Errorf("computing landmarks: %w", err)
+	}
+
+	out := distanceOutput{Redirects: make(map[string]string)}
+	for i, title := range args {
+		canonical := g.Canonical(title)
+		if canonical != title {
+			out.Redirects[title] = canonical
+		}
+		if !g.HasNode(canonical) {
+			return fmt.Errorf("page %q is not in the graph", title)
+		}
+		args[i] = canonical
+	}
+	out.From, out.To = args[0], args[1]
+	if len(out.Redirects) == 0 {
+		out.Redirects = nil
+	}
+
+	start := time.Now()
+	est, _ := landmarks.Estimate(out.From, out.To)
+	if est.Unreachable {
+		out.Unreachable, out.Exact = true, true
+	} else {
+		out.setBounds(est.Lower, est.Upper)
+	}
+
+	if distanceExact && !out.Exact {
+		result := g.FindPathBidirectionalContext(ctx, out.From, out.To, -1, distanceMaxExplored)
+		out.Searched = true
+		out.Explored = result.Explored
+		out.Truncated = result.Truncated
+		out.BudgetExhausted = result.BudgetExhausted
+		switch {
+		case result.Found:
+			out.setBounds(result.Hops, result.Hops)
+		case !result.Truncated:
+			out.Unreachable, out.Exact = true, true
+		}
+	}
+	out.DurationUs = time.Since(start).Microseconds()
+
+	if distanceFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, title := range slices.Sorted(maps.Keys(out.Redirects)) {
+		fmt.Printf("%q redirects to %q\n", title, out.Redirects[title])
+	}
+	switch {
+	case out.Unreachable:
+		fmt.Printf("%q cannot reach %q\n", out.From, out.To)
+	case out.Exact:
+		fmt.Printf("%q is %d hops from %q\n", out.To, *out.Lower, out.From)
+	case out.Upper != nil:
+		fmt.Printf("%q is %d to %d hops from %q\n", out.To, *out.Lower, *out.Upper, out.From)
+	default:
+		fmt.Printf("%q is at least %d hops from %q\n", out.To, *out.Lower, out.From)
+	}
+	if out.Searched {
+		fmt.Printf("Explored %s nodes in %dµs\n", formatNumber(out.Explored), out.DurationUs)
+		if out.Truncated {
+			fmt.Println("Search stopped early; the bounds above are all that is known")
+		}
+	} else {
+		fmt.Printf("Estimated in %dµs\n", out.DurationUs)
+	}
+	return nil
+}
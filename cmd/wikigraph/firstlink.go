@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/fetcher"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/scraper"
+)
+
+var (
+	firstLinkMaxSteps int
+	firstLinkFetch    bool
+	firstLinkFormat   string
+)
+
+var firstLinkCmd = &cobra.Command{
+	Use:   "first-link <title>...",
+	Short: "Follow the first link of each page until the chain loops",
+	Long: `Follow the first link of each page until the chain loops, as in
+"Getting to Philosophy".
+
+A page's first link is the first link in its article text that is outside
+parentheses and italics; links in infoboxes, tables, hatnotes and
+navigation boxes are skipped. Redirects are followed to the page they lead
+to. The chain ends in a cycle, at a page with no such link, at a page that
+has not been fetched, or after --max-steps links.
+
+Link order is recorded when a page is fetched. Pages fetched by older
+versions end the chain as "unordered" until they are fetched again. With
+--fetch, pages on the chain whose first link is unknown are fetched from
+Wikipedia as the chain reaches them.
+
+Examples:
+  wikigraph first-link "Cat"
+  wikigraph first-link "Cat" --fetch
+  wikigraph first-link "Cat" "Dog" "Quantum mechanics" --format json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFirstLink,
+}
+
+func init() {
+	rootCmd.AddCommand(firstLinkCmd)
+
+	firstLinkCmd.Flags().IntVar(&firstLinkMaxSteps, "max-steps", 100, "stop a chain after following this many links")
+	firstLinkCmd.Flags().BoolVar(&firstLinkFetch, "fetch", false, "fetch pages on the chain whose first link is unknown")
+	firstLinkCmd.Flags().StringVarP(&firstLinkFormat, "format", "f", "text", "output format: text, json")
+}
+
+type firstLinkOutput struct {
+	Chains      []firstLinkChain `json:"chains"`
+	ConvergesTo map[string]int   `json:"converges_to"`
+}
+
+type firstLinkChain struct {
+	Start          string   `json:"start"`
+	RedirectedFrom string   `json:"redirected_from,omitempty"`
+	Path           []string `json:"path"`
+	Steps          int      `json:"steps"`
+	Cycle          []string `json:"cycle,omitempty"`
+	ConvergesTo    string   `json:"converges_to"`
+	End            string   `json:"end"`
+}
+
+func runFirstLink(cmd *cobra.Command, args []string) error {
+	if firstLinkMaxSteps < 1 {
+		return fmt.Errorf("--max-steps must be positive")
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	c := cache.New(db)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var scr *scraper.Scraper
+	if firstLinkFetch {
+		f := fetcher.New(fetcher.Config{
+			RateLimit:      cfg.Scraper.RateLimit,
+			RequestTimeout: cfg.Scraper.RequestTimeout,
+			UserAgent:      cfg.Scraper.UserAgent,
+			BaseURL:        cfg.Scraper.WikipediaAPIURL,
+		})
+		scr = scraper.New(c, f, scraper.Config{MaxDepth: 1, BatchSize: 1})
+	}
+
+	// prepare returns the page a title leads to once redirects are
+	// followed. With --fetch it first fetches the page if its first link
+	// is unknown.
+	fetched := make(map[string]bool)
+	var prepare func(title string) (string, error)
+	prepare = func(title string) (string, error) {
+		resolved, err := c.ResolveRedirect(title)
+		if err != nil || scr == nil || fetched[resolved] {
+			return resolved, err
+		}
+		fetched[resolved] = true
+
+		_, status, err := c.GetFirstLink(resolved)
+		if err != nil {
+			return "", err
+		}
+		if status == cache.FirstLinkFound || status == cache.FirstLinkNone {
+			return resolved, nil
+		}
+		if verbose {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Fetching %q\n", resolved)
+		}
+		// A page that cannot be fetched ends the chain as not fetched.
+		if _, err := scr.Refetch(ctx, resolved); err != nil {
+			slog.Warn("fetching page", "title", resolved, "error", err)
+		}
+		return prepare(resolved)
+	}
+
+	// Chains from several starts often merge, so each page is looked up
+	// once.
+	type step struct {
+		next   string
+		status cache.FirstLinkStatus
+	}
+	steps := make(map[string]step)
+	var last cache.FirstLinkStatus
+	next := func(title string) (string, bool, error) {
+		s, ok := steps[title]
+		if !ok {
+			target, status, err := c.GetFirstLink(title)
+			if err != nil {
+				return "", false, err
+			}
+			if status == cache.FirstLinkFound {
+				if target, err = prepare(target); err != nil {
+					return "", false, err
+				}
+			}
+			s = step{target, status}
+			steps[title] = s
+		}
+		last = s.status
+		return s.next, s.status == cache.FirstLinkFound, nil
+	}
+
+	out := firstLinkOutput{ConvergesTo: make(map[string]int)}
+	for _, title := range args {
+		start, err := prepare(title)
+		if err != nil {
+			return err
+		}
+		chain, err := graph.FollowChain(start, firstLinkMaxSteps, next)
+		if err != nil {
+			return fmt.Errorf("following first links from %q: %w", start, err)
+		}
+
+		result := firstLinkChain{
+			Start:       start,
+			Path:        chain.Path,
+			Steps:       chain.Steps(),
+			Cycle:       chain.Cycle(),
+			ConvergesTo: chain.ConvergesTo(),
+		}
+		if start != title {
+			result.RedirectedFrom = title
+		}
+		switch {
+		case chain.CycleAt >= 0:
+			result.End = "cycle"
+		case chain.Truncated:
+			result.End = "max_steps"
+		default:
+			result.End = string(last)
+		}
+		out.Chains = append(out.Chains, result)
+		out.ConvergesTo[result.ConvergesTo]++
+	}
+
+	if firstLinkFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for i, chain := range out.Chains {
+		if i > 0 {
+			fmt.Println()
+		}
+		if chain.RedirectedFrom != "" {
+			fmt.Printf("%q redirects to %q\n", chain.RedirectedFrom, chain.Start)
+		}
+		line := strings.Join(chain.Path, " → ")
+		if chain.Cycle != nil {
+			line += " ↺ " + chain.ConvergesTo
+		}
+		fmt.Println(line)
+
+		switch chain.End {
+		case "cycle":
+			fmt.Printf("  converges to %s after %d steps (cycle of %d)\n",
+				chain.ConvergesTo, chain.Steps, len(chain.Cycle))
+		case "max_steps":
+			fmt.Printf("  stopped after %d steps without looping\n", chain.Steps)
+		case string(cache.FirstLinkNone):
+			fmt.Printf("  ends at %s, which has no link outside parentheses and italics\n", chain.ConvergesTo)
+		case string(cache.FirstLinkUnordered):
+			fmt.Printf("  ends at %s, whose link order is unknown; run with --fetch to continue\n", chain.ConvergesTo)
+		default:
+			fmt.Printf("  ends at %s, which has not been fetched\n", chain.ConvergesTo)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	graphStatsPathSamples int
+	graphStatsWedges      int
+	graphStatsExact       bool
+	graphStatsTop         int
+	graphStatsSeed        uint64
+	graphStatsFormat      string
+)
+
+var graphStatsCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Show graph shape statistics",
+	Long: `Show statistics about the shape of the link graph.
+
+Reports degree distributions, the top hubs and authorities (HITS), the
+reciprocity of links, the clustering coefficient, dangling pages and an
+estimate of the diameter and average path length from a BFS over a sample
+of source pages.
+
+CSV output has one section,key,value row per figure, which makes it easy
+to track how the graph changes between crawls.
+
+Examples:
+  wikigraph stats graph
+  wikigraph stats graph --samples 500 --exact-clustering
+  wikigraph stats graph --format csv > stats-$(date +%F).csv`,
+	Args: cobra.NoArgs,
+	RunE: runGraphStats,
+}
+
+func init() {
+	statsCmd.AddCommand(graphStatsCmd)
+
+	graphStatsCmd.Flags().IntVar(&graphStatsPathSamples, "samples", 100, "number of BFS sources for path lengths")
+	graphStatsCmd.Flags().IntVar(&graphStatsWedges, "wedges", 100000, "number of wedges sampled for clustering")
+	graphStatsCmd.Flags().BoolVar(&graphStatsExact, "exact-clustering", false, "count every triangle instead of sampling")
+	graphStatsCmd.Flags().IntVarP(&graphStatsTop, "top", "n", 10, "number of hubs and authorities to list")
+	graphStatsCmd.Flags().Uint64Var(&graphStatsSeed, "seed", 0, "seed for the samples")
+	graphStatsCmd.Flags().StringVarP(&graphStatsFormat, "format", "f", "text", "output format: text, json, csv")
+}
+
+type graphStatsOutput struct {
+	Nodes             int                `json:"nodes"`
+	Edges             int                `json:"edges"`
+	InDegree          degreeOutput       `json:"in_degree"`
+	OutDegree         degreeOutput       `json:"out_degree"`
+	Hubs              []rankedPageOutput `json:"hubs"`
+	Authorities       []rankedPageOutput `json:"authorities"`
+	Reciprocity       float64            `json:"reciprocity"`
+	Clustering        float64            `json:"clustering"`
+	ClusteringExact   bool               `json:"clustering_exact"`
+	Dangling          int                `json:"dangling"`
+	UnfetchedTargets  int64              `json:"unfetched_targets"`
+	Orphans           int                `json:"orphans"`
+	Diameter          int                `json:"diameter"`
+	AveragePathLength float64            `json:"average_path_length"`
+	Reachability      float64            `json:"reachability"`
+	PathSamples       int                `json:"path_samples"`
+	DurationMs        int64              `json:"duration_ms"`
+}
+
+type degreeOutput struct {
+	Mean        float64            `json:"mean"`
+	Max         int                `json:"max"`
+	Percentiles []percentileOutput `json:"percentiles"`
+	Histogram   []bucketOutput     `json:"histogram"`
+}
+
+type percentileOutput struct {
+	Percentile float64 `json:"percentile"`
+	Degree     int     `json:"degree"`
+}
+
+type bucketOutput struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Count int `json:"count"`
+}
+
+func runGraphStats(cmd *cobra.Command, args []string) error {
+	switch graphStatsFormat {
+	case "text", "json", "csv":
+	default:
+		return fmt.Errorf("unknown format %q (want text, json or csv)", graphStatsFormat)
+	}
+	if graphStatsPathSamples < 1 || graphStatsWedges < 1 || graphStatsTop < 1 {
+		return fmt.Errorf("--samples, --wedges and --top must be positive")
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	c := cache.New(db)
+	loader := graph.NewLoaderWithConfig(c, graph.LoaderConfig{
+		CachePath:   graphCachePath(),
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	stats, err := graph.ComputeStatistics(ctx, g.Adjacency(), graph.StatisticsOptions{
+		PathSamples:       graphStatsPathSamples,
+		ClusteringSamples: graphStatsWedges,
+		ExactClustering:   graphStatsExact,
+		Top:               graphStatsTop,
+		Seed:              graphStatsSeed,
+	})
+	if err != nil {
+		return fmt.Errorf("computing statistics: %w", err)
+	}
+
+	unfetched, err := c.CountUnfetchedTargets()
+	if err != nil {
+		return err
+	}
+
+	out := newGraphStatsOutput(stats, unfetched)
+	out.DurationMs = time.Since(start).Milliseconds()
+
+	switch graphStatsFormat {
+	case "json":
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case "csv":
+		return writeGraphStatsCSV(out)
+	}
+
+	fmt.Printf("Pages:        %s\n", formatNumber(out.Nodes))
+	fmt.Printf("Links:        %s\n", formatNumber(out.Edges))
+	fmt.Printf("Dangling:     %s (%s targets never fetched)\n", formatNumber(out.Dangling), formatNumber(int(out.UnfetchedTargets)))
+	fmt.Printf("Orphans:      %s\n", formatNumber(out.Orphans))
+	fmt.Printf("Reciprocity:  %.2f%%\n", 100*out.Reciprocity)
+	if out.ClusteringExact {
+		fmt.Printf("Clustering:   %.4f\n", out.Clustering)
+	} else {
+		fmt.Printf("Clustering:   %.4f (from %s wedges)\n", out.Clustering, formatNumber(graphStatsWedges))
+	}
+
+	fmt.Printf("\nPaths (from %d sampled sources):\n", out.PathSamples)
+	fmt.Printf("  Diameter:     >= %d\n", out.Diameter)
+	fmt.Printf("  Average:      %.2f\n", out.AveragePathLength)
+	fmt.Printf("  Reachability: %.1f%%\n", 100*out.Reachability)
+
+	printDegreeOutput("In-degree", out.InDegree)
+	printDegreeOutput("Out-degree", out.OutDegree)
+
+	printRankedPages("Top hubs", out.Hubs)
+	printRankedPages("Top authorities", out.Authorities)
+
+	fmt.Printf("\nComputed in %dms\n", out.DurationMs)
+	return nil
+}
+
+func newGraphStatsOutput(s *graph.Statistics, unfetched int64) graphStatsOutput {
+	return graphStatsOutput{
+		Nodes:             s.Nodes,
+		Edges:             s.Edges,
+		InDegree:          newDegreeOutput(s.InDegree),
+		OutDegree:         newDegreeOutput(s.OutDegree),
+		Hubs:              newRankedPageOutputs(s.Hubs),
+		Authorities:       newRankedPageOutputs(s.Authorities),
+		Reciprocity:       s.Reciprocity,
+		Clustering:        s.Clustering,
+		ClusteringExact:   s.ClusteringExact,
+		Dangling:          s.Dangling,
+		UnfetchedTargets:  unfetched,
+		Orphans:           s.Orphans,
+		Diameter:          s.Diameter,
+		AveragePathLength: s.AveragePathLength,
+		Reachability:      s.Reachability,
+		PathSamples:       s.PathSamples,
+	}
+}
+
+func newDegreeOutput(d graph.DegreeDistribution) degreeOutput {
+	out := degreeOutput{Mean: d.Mean, Max: d.Max}
+	for _, p := range d.Percentiles {
+		out.Percentiles = append(out.Percentiles, percentileOutput{Percentile: p.Percentile, Degree: p.Degree})
+	}
+	for _, b := range d.Histogram {
+		out.Histogram = append(out.Histogram, bucketOutput{Min: b.Min, Max: b.Max, Count: b.Count})
+	}
+	return out
+}
+
+func newRankedPageOutputs(pages []graph.RankedPage) []rankedPageOutput {
+	out := make([]rankedPageOutput, len(pages))
+	for i, p := range pages {
+		out[i] = rankedPageOutput{Rank: i + 1, Title: p.Title, Score: p.Score}
+	}
+	return out
+}
+
+func printDegreeOutput(name string, d degreeOutput) {
+	fmt.Printf("\n%s: mean %.2f, max %s\n", name, d.Mean, formatNumber(d.Max))
+	for _, p := range d.Percentiles {
+		fmt.Printf("  p%-5s %s\n", strconv.FormatFloat(p.Percentile, 'f', -1, 64), formatNumber(p.Degree))
+	}
+	for _, b := range d.Histogram {
+		fmt.Printf("  %-9s %s\n", bucketLabel(b), formatNumber(b.Count))
+	}
+}
+
+func printRankedPages(name string, pages []rankedPageOutput) {
+	fmt.Printf("\n%s:\n", name)
+	for _, p := range pages {
+		fmt.Printf("  %2d. %.6f  %s\n", p.Rank, p.Score, p.Title)
+	}
+}
+
+func bucketLabel(b bucketOutput) string {
+	if b.Min == b.Max {
+		return strconv.Itoa(b.Min)
+	}
+	return fmt.Sprintf("%d-%d", b.Min, b.Max)
+}
+
+// writeGraphStatsCSV writes one section,key,value row per figure.
+func writeGraphStatsCSV(out graphStatsOutput) error {
+	w := csv.NewWriter(os.Stdout)
+	ftoa := func(f float64) string { return strconv.FormatFloat(f, 'g', -1, 64) }
+
+	rows := [][]string{
+		{"section", "key", "value"},
+		{"summary", "nodes", strconv.Itoa(out.Nodes)},
+		{"summary", "edges", strconv.Itoa(out.Edges)},
+		{"summary", "dangling", strconv.Itoa(out.Dangling)},
+		{"summary", "unfetched_targets", strconv.FormatInt(out.UnfetchedTargets, 10)},
+		{"summary", "orphans", strconv.Itoa(out.Orphans)},
+		{"summary", "reciprocity", ftoa(out.Reciprocity)},
+		{"summary", "clustering", ftoa(out.Clustering)},
+		{"summary", "clustering_exact", strconv.FormatBool(out.ClusteringExact)},
+		{"summary", "diameter", strconv.Itoa(out.Diameter)},
+		{"summary", "average_path_length", ftoa(out.AveragePathLength)},
+		{"summary", "reachability", ftoa(out.Reachability)},
+		{"summary", "path_samples", strconv.Itoa(out.PathSamples)},
+	}
+	for _, d := range []struct {
+		section string
+		degrees degreeOutput
+	}{{"in_degree", out.InDegree}, {"out_degree", out.OutDegree}} {
+		rows = append(rows,
+			[]string{d.section, "mean", ftoa(d.degrees.Mean)},
+			[]string{d.section, "max", strconv.Itoa(d.degrees.Max)},
+		)
+		for _, p := range d.degrees.Percentiles {
+			rows = append(rows, []string{d.section, "p" + ftoa(p.Percentile), strconv.Itoa(p.Degree)})
+		}
+		for _, b := range d.degrees.Histogram {
+			rows = append(rows, []string{d.section + "_histogram", bucketLabel(b), strconv.Itoa(b.Count)})
+		}
+	}
+	for _, p := range out.Hubs {
+		rows = append(rows, []string{"hubs", p.Title, ftoa(p.Score)})
+	}
+	for _, p := range out.Authorities {
+		rows = append(rows, []string{"authorities", p.Title, ftoa(p.Score)})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return fmt.Errorf("writing csv: %w", err)
+	}
+	return nil
+}
@@ -1,8 +1,14 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
+	"os/signal"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
 
@@ -14,9 +20,23 @@ import (
 )
 
 var (
-	pathMaxDepth    int
-	bidirectional   bool
-	outputFormat    string
+	pathMaxDepth  int
+	pathAlgorithm string
+	bidirectional bool
+	outputFormat  string
+	pathAll       bool
+	pathK         int
+	pathMaxPaths  int
+	pathDAG       bool
+
+	pathExclude        []string
+	pathExcludePattern []string
+	pathVia            []string
+	pathMaxDegree      int
+	pathMaxExplored    int
+
+	pathPairs  string
+	pathMatrix bool
 )
 
 var pathCmd = &cobra.Command{
@@ -27,11 +47,40 @@ var pathCmd = &cobra.Command{
 The pages must already be in the local database. Use 'wikigraph fetch'
 to crawl pages first.
 
+--algorithm alt runs an A* search guided by distances to and from a set of
+landmark pages (graph.landmarks in the config). The distance tables are
+computed on first use and stored next to the graph cache. Compare the
+explored counts of bfs, bidirectional and alt to see which suits a query.
+
+--dag lists the union of every shortest path as links grouped by page,
+which stays short where --all would run into thousands of paths.
+
+--pairs reads from,to rows from a CSV file ("-" for standard input) and
+answers them all, running one BFS from each distinct source. The results
+are listed per pair, or with --matrix as a table of hop counts with one row
+per source and one column per target. --format csv writes either as CSV.
+
 Examples:
   wikigraph path "Albert Einstein" "Physics"
   wikigraph path "Go (programming language)" "Python" --max-depth 10
-  wikigraph path "Cat" "Dog" --bidirectional`,
-	Args: cobra.ExactArgs(2),
+  wikigraph path "Cat" "Dog" --bidirectional
+  wikigraph path "Cat" "Dog" --algorithm alt
+  wikigraph path "Cat" "Dog" --all --max-paths 20
+  wikigraph path "Cat" "Dog" --k 5
+  wikigraph path "Cat" "Dog" --dag
+  wikigraph path "Cat" "Dog" --exclude "Philosophy" --exclude-pattern "^List of"
+  wikigraph path "Cat" "Dog" --via "Mammal" --max-degree 5000
+  wikigraph path --pairs pairs.csv --format csv > paths.csv
+  wikigraph path --pairs pairs.csv --matrix`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if pathPairs != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("--pairs takes no page arguments")
+			}
+			return nil
+		}
+		return cobra.ExactArgs(2)(cmd, args)
+	},
 	RunE: runPath,
 }
 
@@ -39,26 +88,104 @@ func init() {
 	rootCmd.AddCommand(pathCmd)
 
 	pathCmd.Flags().IntVarP(&pathMaxDepth, "max-depth", "d", 6, "maximum path length to search")
-	pathCmd.Flags().BoolVarP(&bidirectional, "bidirectional", "b", false, "use bidirectional search")
-	pathCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "output format: text, json")
+	pathCmd.Flags().StringVarP(&pathAlgorithm, "algorithm", "a", "bfs", "search algorithm: bfs, bidirectional, alt")
+	pathCmd.Flags().BoolVarP(&bidirectional, "bidirectional", "b", false, "use bidirectional search (same as --algorithm bidirectional)")
+	pathCmd.Flags().StringVarP(&outputFormat, "format", "f", "text", "output format: text, json (csv with --pairs)")
+	pathCmd.Flags().BoolVar(&pathAll, "all", false, "list every shortest path")
+	pathCmd.Flags().IntVar(&pathK, "k", 0, "list the k shortest loopless paths")
+	pathCmd.Flags().IntVar(&pathMaxPaths, "max-paths", 100, "maximum number of paths listed by --all")
+	pathCmd.Flags().BoolVar(&pathDAG, "dag", false, "list the links on every shortest path")
+	pathCmd.Flags().StringArrayVar(&pathExclude, "exclude", nil, "page the path must avoid (repeatable)")
+	pathCmd.Flags().StringArrayVar(&pathExcludePattern, "exclude-pattern", nil, "regular expression for titles to avoid (repeatable)")
+	pathCmd.Flags().StringArrayVar(&pathVia, "via", nil, "page the path must pass through, in order (repeatable)")
+	pathCmd.Flags().IntVar(&pathMaxDegree, "max-degree", 0, "skip pages with more than this many out-links (0 = no cap)")
+	pathCmd.Flags().IntVar(&pathMaxExplored, "max-explored", 0, "stop after expanding this many pages (0 = no cap)")
+	pathCmd.Flags().StringVar(&pathPairs, "pairs", "", "CSV file of from,to pairs to answer in one batch (- for stdin)")
+	pathCmd.Flags().BoolVar(&pathMatrix, "matrix", false, "with --pairs, print a source-by-target matrix of hop counts")
 }
 
 type pathOutput struct {
-	Found      bool     `json:"found"`
-	From       string   `json:"from"`
-	To         string   `json:"to"`
-	Path       []string `json:"path,omitempty"`
-	Hops       int      `json:"hops"`
-	Explored   int      `json:"explored"`
-	DurationMs int64    `json:"duration_ms"`
-	Algorithm  string   `json:"algorithm"`
-	Nodes      int      `json:"nodes"`
-	Edges      int      `json:"edges"`
+	Found           bool              `json:"found"`
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	Path            []string          `json:"path,omitempty"`
+	Paths           [][]string        `json:"paths,omitempty"`
+	DAG             *dagOutput        `json:"dag,omitempty"`
+	Hops            int               `json:"hops"`
+	Explored        int               `json:"explored"`
+	Truncated       bool              `json:"truncated"`
+	BudgetExhausted bool              `json:"budget_exhausted"`
+	Redirects       map[string]string `json:"redirects,omitempty"`
+	DurationMs      int64             `json:"duration_ms"`
+	Algorithm       string            `json:"algorithm"`
+	Nodes           int               `json:"nodes"`
+	Edges           int               `json:"edges"`
+}
+
+// dagOutput is the union of every shortest path: the pages on one, by
+// distance from the source, and the links between them that lie on one.
+type dagOutput struct {
+	Nodes []dagNode `json:"nodes"`
+	Edges []dagEdge `json:"edges"`
+}
+
+type dagNode struct {
+	Title string `json:"title"`
+	Hops  int    `json:"hops"`
+}
+
+type dagEdge struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
 }
 
 func runPath(cmd *cobra.Command, args []string) error {
+	if pathPairs != "" {
+		return runPathPairs(cmd)
+	}
+	if pathMatrix {
+		return fmt.Errorf("--matrix needs --pairs")
+	}
 	from, to := args[0], args[1]
 
+	multiple := pathAll || pathK > 0 || pathDAG
+	if pathAll && pathK > 0 || pathDAG && (pathAll || pathK > 0) {
+		return fmt.Errorf("--all, --k and --dag cannot be used together")
+	}
+
+	algorithm := pathAlgorithm
+	if bidirectional {
+		algorithm = "bidirectional"
+	}
+	switch algorithm {
+	case "bfs", "bidirectional":
+	case "alt":
+		if multiple {
+			return fmt.Errorf("--algorithm alt cannot be combined with --all, --k or --dag")
+		}
+	default:
+		return fmt.Errorf("unknown algorithm %q (want bfs, bidirectional or alt)", algorithm)
+	}
+
+	opts := graph.PathOptions{
+		MaxDepth:      pathMaxDepth,
+		Bidirectional: algorithm == "bidirectional",
+		Exclude:       pathExclude,
+		Waypoints:     pathVia,
+		MaxDegree:     pathMaxDegree,
+		MaxExplored:   pathMaxExplored,
+	}
+	for _, pattern := range pathExcludePattern {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid --exclude-pattern %q: %w", pattern, err)
+		}
+		opts.ExcludePatterns = append(opts.ExcludePatterns, re)
+	}
+	if opts.HasConstraints() && (multiple || algorithm == "alt") {
+		return fmt.Errorf("--exclude, --exclude-pattern, --via and --max-degree cannot be combined with --all, --k, --dag or --algorithm alt")
+	}
+
 	db, err := database.Open(cfg.Database.Path)
 	if err != nil {
 		return fmt.Errorf("opening database: %w", err)
@@ -69,11 +196,16 @@ func runPath(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("running migrations: %w", err)
 	}
 
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
 	c := cache.New(db)
-	loader := graph.NewLoader(c)
+	loader := graph.NewLoaderWithConfig(c, graph.LoaderConfig{Backend: backend})
 
 	loadStart := time.Now()
-	g, err := loader.Load()
+	g, err := loader.LoadView()
 	if err != nil {
 		return fmt.Errorf("loading graph: %w", err)
 	}
@@ -88,29 +220,104 @@ func runPath(cmd *cobra.Command, args []string) error {
 			g.NodeCount(), g.EdgeCount(), loadDuration.Truncate(time.Millisecond))
 	}
 
+	// Redirect titles are searched as the pages they lead to.
+	redirects := make(map[string]string)
+	canonical := func(title string) string {
+		if c := g.Canonical(title); c != title {
+			redirects[title] = c
+			return c
+		}
+		return title
+	}
+	from, to = canonical(from), canonical(to)
+	for i, title := range opts.Waypoints {
+		opts.Waypoints[i] = canonical(title)
+	}
+	for i, title := range opts.Exclude {
+		opts.Exclude[i] = canonical(title)
+	}
+
+	// Ctrl-C stops the search and reports what was explored so far.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var landmarks *graph.Landmarks
+	if algorithm == "alt" {
+		landmarkOpts, err := landmarkOptions()
+		if err != nil {
+			return err
+		}
+		if landmarkOpts.Count <= 0 {
+			return fmt.Errorf("--algorithm alt needs graph.landmarks to be positive")
+		}
+		landmarkStart := time.Now()
+		landmarks, err = graph.CachedLandmarks(ctx, g.Adjacency(), graphCachePath(), landmarkOpts)
+		if err != nil {
+			return fmt.Errorf("computing landmarks: %w", err)
+		}
+		if verbose {
+			fmt.Fprintf(cmd.ErrOrStderr(), "Prepared %d landmarks in %s\n",
+				landmarks.Count(), time.Since(landmarkStart).Truncate(time.Millisecond))
+		}
+	}
+
 	searchStart := time.Now()
 	var result graph.PathResult
-	algorithm := "bfs"
+	var multi graph.MultiPathResult
 
-	if bidirectional {
-		algorithm = "bidirectional"
-		result = g.FindPathBidirectional(from, to)
-	} else {
-		result = g.FindPathWithLimit(from, to, pathMaxDepth)
+	switch {
+	case pathAll:
+		algorithm = "bfs"
+		multi = graph.AllShortestPaths(ctx, g.Adjacency(), from, to, pathMaxDepth, pathMaxPaths, pathMaxExplored)
+	case pathK > 0:
+		algorithm = "yen"
+		multi = graph.KShortestPaths(ctx, g.Adjacency(), from, to, pathK, pathMaxDepth, pathMaxExplored)
+	case pathDAG:
+		algorithm = "bfs"
+		multi = graph.ShortestPathDAG(ctx, g.Adjacency(), from, to, pathMaxDepth, pathMaxExplored)
+	case opts.HasConstraints():
+		result = graph.FindPathWithOptions(ctx, g.Adjacency(), from, to, opts)
+	case algorithm == "alt":
+		result = landmarks.FindPath(ctx, from, to, pathMaxDepth, pathMaxExplored)
+	case algorithm == "bidirectional":
+		result = g.FindPathBidirectionalContext(ctx, from, to, -1, pathMaxExplored)
+	default:
+		result = g.FindPathContext(ctx, from, to, pathMaxDepth, pathMaxExplored)
+	}
+	if multiple {
+		result = graph.PathResult{
+			Found:           multi.Found,
+			Hops:            multi.Hops,
+			Explored:        multi.Explored,
+			Truncated:       multi.Truncated,
+			BudgetExhausted: multi.BudgetExhausted,
+		}
+		if len(multi.Paths) > 0 {
+			result.Path = multi.Paths[0]
+		}
 	}
 	searchDuration := time.Since(searchStart)
 
+	if len(redirects) == 0 {
+		redirects = nil
+	}
+
 	out := pathOutput{
-		Found:      result.Found,
-		From:       from,
-		To:         to,
-		Path:       result.Path,
-		Hops:       result.Hops,
-		Explored:   result.Explored,
-		DurationMs: searchDuration.Milliseconds(),
-		Algorithm:  algorithm,
-		Nodes:      g.NodeCount(),
-		Edges:      g.EdgeCount(),
+		Found:           result.Found,
+		From:            from,
+		To:              to,
+		Path:            result.Path,
+		Paths:           multi.Paths,
+		DAG:             newDAGOutput(multi.DAG),
+		Hops:            result.Hops,
+		Explored:        result.Explored,
+		Truncated:       result.Truncated,
+		BudgetExhausted: result.BudgetExhausted,
+		DurationMs:      searchDuration.Milliseconds(),
+		Algorithm:       algorithm,
+		Nodes:           g.NodeCount(),
+		Edges:           g.EdgeCount(),
+		Redirects:       redirects,
 	}
 
 	switch outputFormat {
@@ -131,18 +338,51 @@ func outputJSON(out pathOutput) error {
 }
 
 func outputText(out pathOutput) error {
+	for _, title := range slices.Sorted(maps.Keys(out.Redirects)) {
+		fmt.Printf("%q redirects to %q\n", title, out.Redirects[title])
+	}
+
 	if !out.Found {
 		fmt.Printf("No path found from %q to %q\n", out.From, out.To)
 		fmt.Printf("Explored %d nodes in %dms\n", out.Explored, out.DurationMs)
+		if out.BudgetExhausted {
+			fmt.Println("Search stopped at the --max-explored limit; a path may still exist")
+		} else if out.Truncated {
+			fmt.Println("Search was interrupted; a path may still exist")
+		}
 		return nil
 	}
 
-	fmt.Printf("Path found (%d hops):\n", out.Hops)
-	for i, title := range out.Path {
-		if i == 0 {
-			fmt.Printf("  %s\n", title)
-		} else {
-			fmt.Printf("  → %s\n", title)
+	if out.DAG != nil {
+		fmt.Printf("Shortest paths found (%d hops) through %d pages and %d links:\n",
+			out.Hops, len(out.DAG.Nodes), len(out.DAG.Edges))
+		for i := 0; i < len(out.DAG.Edges); {
+			source := out.DAG.Edges[i].Source
+			var targets []string
+			for ; i < len(out.DAG.Edges) && out.DAG.Edges[i].Source == source; i++ {
+				targets = append(targets, out.DAG.Edges[i].Target)
+			}
+			fmt.Printf("  %s → %s\n", source, strings.Join(targets, ", "))
+		}
+		if out.Truncated {
+			fmt.Println("  ... the search stopped early and may have missed some links")
+		}
+	} else if len(out.Paths) > 0 {
+		fmt.Printf("%d paths found (shortest %d hops):\n", len(out.Paths), out.Hops)
+		for i, path := range out.Paths {
+			fmt.Printf("  %d. (%d hops) %s\n", i+1, len(path)-1, strings.Join(path, " → "))
+		}
+		if out.Truncated {
+			fmt.Println("  ... more paths may exist")
+		}
+	} else {
+		fmt.Printf("Path found (%d hops):\n", out.Hops)
+		for i, title := range out.Path {
+			if i == 0 {
+				fmt.Printf("  %s\n", title)
+			} else {
+				fmt.Printf("  → %s\n", title)
+			}
 		}
 	}
 	fmt.Println()
@@ -152,6 +392,25 @@ func outputText(out pathOutput) error {
 	return nil
 }
 
+// newDAGOutput converts a shortest-path DAG for output, or returns nil for
+// none.
+func newDAGOutput(s *graph.Subgraph) *dagOutput {
+	if s == nil {
+		return nil
+	}
+	out := &dagOutput{
+		Nodes: make([]dagNode, len(s.Nodes)),
+		Edges: make([]dagEdge, len(s.Edges)),
+	}
+	for i, n := range s.Nodes {
+		out.Nodes[i] = dagNode{Title: n.Title, Hops: n.Hops}
+	}
+	for i, e := range s.Edges {
+		out.Edges[i] = dagEdge{Source: e.Source, Target: e.Target}
+	}
+	return out
+}
+
 func formatNumber(n int) string {
 	if n < 1000 {
 		return fmt.Sprintf("%d", n)
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+type pathPairsOutput struct {
+	Results    []pairOutput      `json:"results,omitempty"`
+	Sources    []string          `json:"sources,omitempty"`
+	Targets    []string          `json:"targets,omitempty"`
+	Distances  [][]*int          `json:"distances,omitempty"`
+	Pairs      int               `json:"pairs"`
+	Found      int               `json:"found"`
+	Searches   int               `json:"searches"`
+	Explored   int               `json:"explored"`
+	Truncated  bool              `json:"truncated"`
+	Redirects  map[string]string `json:"redirects,omitempty"`
+	DurationMs int64             `json:"duration_ms"`
+	Nodes      int               `json:"nodes"`
+	Edges      int               `json:"edges"`
+}
+
+type pairOutput struct {
+	From            string   `json:"from"`
+	To              string   `json:"to"`
+	Found           bool     `json:"found"`
+	Path            []string `json:"path,omitempty"`
+	Hops            int      `json:"hops"`
+	Truncated       bool     `json:"truncated,omitempty"`
+	BudgetExhausted bool     `json:"budget_exhausted,omitempty"`
+	Reason          string   `json:"reason,omitempty"`
+}
+
+// runPathPairs answers every pair in the --pairs file with one BFS per
+// distinct source.
+func runPathPairs(cmd *cobra.Command) error {
+	switch outputFormat {
+	case "text", "json", "csv":
+	default:
+		return fmt.Errorf("unknown format %q (want text, json or csv)", outputFormat)
+	}
+	if pathAll || pathK > 0 || bidirectional || pathAlgorithm != "bfs" ||
+		len(pathExclude) > 0 || len(pathExcludePattern) > 0 || len(pathVia) > 0 || pathMaxDegree > 0 {
+		return fmt.Errorf("--pairs cannot be combined with --all, --k, --algorithm, --bidirectional or path constraints")
+	}
+
+	pairs, err := readPairs(pathPairs)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{Backend: backend})
+
+	loadStart := time.Now()
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+	if verbose {
+		fmt.Fprintf(cmd.ErrOrStderr(), "Loaded %d nodes, %d edges in %s\n",
+			g.NodeCount(), g.EdgeCount(), time.Since(loadStart).Truncate(time.Millisecond))
+	}
+
+	// Redirect titles are searched as the pages they lead to.
+	redirects := make(map[string]string)
+	canonical := func(title string) string {
+		if c := g.Canonical(title); c != title {
+			redirects[title] = c
+			return c
+		}
+		return title
+	}
+	for i, p := range pairs {
+		pairs[i] = graph.PathPair{From: canonical(p.From), To: canonical(p.To)}
+	}
+
+	// Ctrl-C stops the searches still running; their pairs are reported
+	// as truncated.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	searchStart := time.Now()
+	results := graph.BatchPaths(ctx, g.Adjacency(), pairs, pathMaxDepth, pathMaxExplored)
+
+	out := pathPairsOutput{
+		Pairs:      len(pairs),
+		DurationMs: time.Since(searchStart).Milliseconds(),
+		Nodes:      g.NodeCount(),
+		Edges:      g.EdgeCount(),
+	}
+	if len(redirects) > 0 {
+		out.Redirects = redirects
+	}
+
+	explored := make(map[string]int)
+	for i, r := range results {
+		p := pairs[i]
+		pair := pairOutput{
+			From:            p.From,
+			To:              p.To,
+			Found:           r.Found,
+			Path:            r.Path,
+			Hops:            r.Hops,
+			Truncated:       r.Truncated,
+			BudgetExhausted: r.BudgetExhausted,
+		}
+		if !g.HasNode(p.From) || !g.HasNode(p.To) {
+			pair.Reason = "page_not_found"
+		} else {
+			explored[p.From] = r.Explored
+		}
+		if r.Found {
+			out.Found++
+		}
+		out.Truncated = out.Truncated || r.Truncated
+		out.Results = append(out.Results, pair)
+	}
+	out.Searches = len(explored)
+	for _, n := range explored {
+		out.Explored += n
+	}
+
+	if pathMatrix {
+		m := graph.NewDistanceMatrix(pairs, results)
+		out.Results = nil
+		out.Sources, out.Targets = m.Sources, m.Targets
+		out.Distances = make([][]*int, len(m.Hops))
+		for i, row := range m.Hops {
+			out.Distances[i] = make([]*int, len(row))
+			for j, hops := range row {
+				if hops >= 0 {
+					out.Distances[i][j] = &row[j]
+				}
+			}
+		}
+	}
+
+	switch outputFormat {
+	case "json":
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	case "csv":
+		return writePathPairsCSV(out)
+	default:
+		return outputPairsText(out)
+	}
+}
+
+// readPairs reads from,to rows from a CSV file, or from standard input
+// when path is "-". A first row of "from,to" is skipped as a header.
+func readPairs(path string) ([]graph.PathPair, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("opening pairs file: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.TrimLeadingSpace = true
+	cr.Comment = '#'
+	records, err := cr.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("reading pairs file: %w", err)
+	}
+
+	var pairs []graph.PathPair
+	for i, record := range records {
+		from, to := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if i == 0 && strings.EqualFold(from, "from") && strings.EqualFold(to, "to") {
+			continue
+		}
+		if from == "" || to == "" {
+			return nil, fmt.Errorf("reading pairs file: row %d has an empty title", i+1)
+		}
+		pairs = append(pairs, graph.PathPair{From: from, To: to})
+	}
+	if len(pairs) == 0 {
+		return nil, fmt.Errorf("pairs file lists no pairs")
+	}
+	return pairs, nil
+}
+
+// writePathPairsCSV writes a from,to,found,hops,path,reason row per pair,
+// or for --matrix a header row of targets and one row of hop counts per
+// source. Path steps are separated by "|", which cannot occur in page
+// titles, and an empty cell means no path.
+func writePathPairsCSV(out pathPairsOutput) error {
+	w := csv.NewWriter(os.Stdout)
+
+	if out.Distances != nil {
+		_ = w.Write(append([]string{"source"}, out.Targets...))
+		for i, row := range out.Distances {
+			record := []string{out.Sources[i]}
+			for _, hops := range row {
+				record = append(record, hopsCell(hops))
+			}
+			_ = w.Write(record)
+		}
+	} else {
+		_ = w.Write([]string{"from", "to", "found", "hops", "path", "reason"})
+		for _, r := range out.Results {
+			hops := ""
+			if r.Found {
+				hops = strconv.Itoa(r.Hops)
+			}
+			_ = w.Write([]string{r.From, r.To, strconv.FormatBool(r.Found), hops, strings.Join(r.Path, "|"), r.Reason})
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+func outputPairsText(out pathPairsOutput) error {
+	for _, title := range slices.Sorted(maps.Keys(out.Redirects)) {
+		fmt.Printf("%q redirects to %q\n", title, out.Redirects[title])
+	}
+
+	if out.Distances != nil {
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(tw, "\t%s\n", strings.Join(out.Targets, "\t"))
+		for i, row := range out.Distances {
+			cells := make([]string, len(row))
+			for j, hops := range row {
+				if cells[j] = hopsCell(hops); cells[j] == "" {
+					cells[j] = "-"
+				}
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", out.Sources[i], strings.Join(cells, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range out.Results {
+			switch {
+			case r.Found:
+				fmt.Printf("%s → %s (%d hops): %s\n", r.From, r.To, r.Hops, strings.Join(r.Path, " → "))
+			case r.Reason == "page_not_found":
+				fmt.Printf("%s → %s: page not in the graph\n", r.From, r.To)
+			case r.Truncated:
+				fmt.Printf("%s → %s: search stopped early; a path may still exist\n", r.From, r.To)
+			default:
+				fmt.Printf("%s → %s: no path\n", r.From, r.To)
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("%d of %d pairs connected; %d searches explored %s nodes in %dms\n",
+		out.Found, out.Pairs, out.Searches, formatNumber(out.Explored), out.DurationMs)
+	if out.Truncated {
+		fmt.Println("Some searches stopped early; raise --max-explored or --max-depth for a complete answer")
+	}
+	return nil
+}
+
+// hopsCell formats a matrix cell; no path is an empty cell.
+func hopsCell(hops *int) string {
+	if hops == nil {
+		return ""
+	}
+	return strconv.Itoa(*hops)
+}
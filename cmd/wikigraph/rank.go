@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	rankTop           int
+	rankSeeds         []string
+	rankDamping       float64
+	rankTolerance     float64
+	rankMaxIterations int
+	rankDangling      string
+	rankFormat        string
+)
+
+var rankCmd = &cobra.Command{
+	Use:   "rank",
+	Short: "Rank pages by PageRank",
+	Long: `Rank pages by PageRank.
+
+Without --seed the global ranking is computed and cached next to the graph
+cache, so later runs with the same settings are instant. With one or more
+--seed pages a personalized ranking is computed, which favours pages close
+to the seeds.
+
+Examples:
+  wikigraph rank
+  wikigraph rank --top 50 --format json
+  wikigraph rank --seed "Physics" --seed "Mathematics"
+  wikigraph rank --damping 0.9 --dangling teleport`,
+	Args: cobra.NoArgs,
+	RunE: runRank,
+}
+
+func init() {
+	rootCmd.AddCommand(rankCmd)
+
+	rankCmd.Flags().IntVarP(&rankTop, "top", "n", 20, "number of pages to list")
+	rankCmd.Flags().StringArrayVar(&rankSeeds, "seed", nil, "seed page for a personalized ranking (repeatable)")
+	rankCmd.Flags().Float64Var(&rankDamping, "damping", 0, "probability of following a link (default from config)")
+	rankCmd.Flags().Float64Var(&rankTolerance, "tolerance", 0, "convergence tolerance (default from config, negative to run every iteration)")
+	rankCmd.Flags().IntVar(&rankMaxIterations, "max-iterations", 0, "iteration cap (default from config)")
+	rankCmd.Flags().StringVar(&rankDangling, "dangling", "", "dangling-page handling: uniform, teleport, drop (default from config)")
+	rankCmd.Flags().StringVarP(&rankFormat, "format", "f", "text", "output format: text, json")
+}
+
+type rankOutput struct {
+	Seeds      []string           `json:"seeds,omitempty"`
+	Pages      []rankedPageOutput `json:"pages"`
+	Damping    float64            `json:"damping"`
+	Iterations int                `json:"iterations"`
+	Converged  bool               `json:"converged"`
+	DurationMs int64              `json:"duration_ms"`
+}
+
+type rankedPageOutput struct {
+	Rank  int     `json:"rank"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+func runRank(cmd *cobra.Command, args []string) error {
+	opts, err := pageRankOptions()
+	if err != nil {
+		return err
+	}
+	if cmd.Flags().Changed("damping") {
+		opts.Damping = rankDamping
+	}
+	if cmd.Flags().Changed("tolerance") {
+		opts.Tolerance = rankTolerance
+	}
+	if cmd.Flags().Changed("max-iterations") {
+		opts.MaxIterations = rankMaxIterations
+	}
+	if cmd.Flags().Changed("dangling") {
+		if opts.Dangling, err = graph.ParseDanglingMode(rankDangling); err != nil {
+			return err
+		}
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	cachePath := graphCachePath()
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{
+		CachePath:   cachePath,
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	var ranks *graph.Ranks
+	if len(rankSeeds) > 0 {
+		ranks, err = graph.PersonalizedPageRank(ctx, g.Adjacency(), rankSeeds, opts)
+	} else {
+		ranks, err = graph.CachedPageRank(ctx, g.Adjacency(), cachePath, opts)
+	}
+	if err != nil {
+		return fmt.Errorf("computing pagerank: %w", err)
+	}
+
+	out := rankOutput{
+		Seeds:      ranks.Seeds,
+		Damping:    ranks.Options.Damping,
+		Iterations: ranks.Iterations,
+		Converged:  ranks.Converged,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	for i, p := range ranks.Top(rankTop) {
+		out.Pages = append(out.Pages, rankedPageOutput{Rank: i + 1, Title: p.Title, Score: p.Score})
+	}
+
+	if rankFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	for _, p := range out.Pages {
+		fmt.Printf("%4d. %.6f  %s\n", p.Rank, p.Score, p.Title)
+	}
+	fmt.Println()
+	status := "converged"
+	if !out.Converged {
+		status = "did not converge"
+	}
+	fmt.Printf("%d iterations, %s, in %dms\n", out.Iterations, status, out.DurationMs)
+
+	return nil
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	robustnessTop     int
+	robustnessRemove  int
+	robustnessSteps   int
+	robustnessBy      string
+	robustnessSamples int
+	robustnessSeed    uint64
+	robustnessFormat  string
+)
+
+var robustnessCmd = &cobra.Command{
+	Use:   "robustness",
+	Short: "Report how the graph falls apart when hubs are removed",
+	Long: `Report how robust the link graph is, with links taken as undirected.
+
+  k-cores        The k-core is the largest set of pages that each link to
+                 or from at least k others in the set. The innermost core
+                 is the densely knit centre of the graph.
+  Cut points     Articulation points are pages whose removal splits their
+                 component; bridges are links whose removal does. Each is
+                 listed with the number of pages it cuts off.
+  Hub removal    Removes the top hubs a batch at a time and reports how
+                 the giant component shrinks and how the average path
+                 length, following links in their direction from sampled
+                 pages, changes.
+
+Hubs are picked by number of neighbours (degree), in-links (in_links) or
+core number (core). Use --remove 0 to skip the simulation.
+
+Examples:
+  wikigraph robustness
+  wikigraph robustness --remove 1000 --steps 20 --by core
+  wikigraph robustness --top 25 --format json`,
+	Args: cobra.NoArgs,
+	RunE: runRobustness,
+}
+
+func init() {
+	rootCmd.AddCommand(robustnessCmd)
+
+	robustnessCmd.Flags().IntVarP(&robustnessTop, "top", "n", 10, "number of core pages, cut points and bridges to list")
+	robustnessCmd.Flags().IntVar(&robustnessRemove, "remove", 100, "number of hubs to remove (0 to skip the simulation)")
+	robustnessCmd.Flags().IntVar(&robustnessSteps, "steps", 10, "number of times the graph is measured while removing hubs")
+	robustnessCmd.Flags().StringVar(&robustnessBy, "by", string(graph.ByDegree), "how hubs are picked: degree, in_links, core")
+	robustnessCmd.Flags().IntVar(&robustnessSamples, "samples", 100, "number of BFS sources for path lengths")
+	robustnessCmd.Flags().Uint64Var(&robustnessSeed, "seed", 0, "seed for the path samples")
+	robustnessCmd.Flags().StringVarP(&robustnessFormat, "format", "f", "text", "output format: text, json")
+}
+
+type robustnessOutput struct {
+	Nodes      int                `json:"nodes"`
+	Degeneracy int                `json:"degeneracy"`
+	CoreSizes  []coreSizeOutput   `json:"core_sizes"`
+	Innermost  []rankedPageOutput `json:"innermost_core"`
+
+	ArticulationPoints    int             `json:"articulation_points"`
+	Bridges               int             `json:"bridges"`
+	TopArticulationPoints []cutPageOutput `json:"top_articulation_points"`
+	TopBridges            []cutLinkOutput `json:"top_bridges"`
+
+	Removal    *removalOutput `json:"hub_removal,omitempty"`
+	DurationMs int64          `json:"duration_ms"`
+}
+
+type coreSizeOutput struct {
+	K     int `json:"k"`
+	Pages int `json:"pages"`
+}
+
+type cutPageOutput struct {
+	Title     string `json:"title"`
+	Separated int    `json:"separated"`
+}
+
+type cutLinkOutput struct {
+	From      string `json:"from"`
+	To        string `json:"to"`
+	Separated int    `json:"separated"`
+}
+
+type removalOutput struct {
+	By          string              `json:"by"`
+	PathSamples int                 `json:"path_samples"`
+	Hubs        []rankedPageOutput  `json:"hubs"`
+	Steps       []removalStepOutput `json:"steps"`
+}
+
+type removalStepOutput struct {
+	Removed           int     `json:"removed"`
+	Components        int     `json:"components"`
+	GiantSize         int     `json:"giant_size"`
+	GiantPercent      float64 `json:"giant_percent"`
+	AveragePathLength float64 `json:"average_path_length"`
+	Reachability      float64 `json:"reachability"`
+}
+
+func runRobustness(cmd *cobra.Command, args []string) error {
+	if robustnessFormat != "text" && robustnessFormat != "json" {
+		return fmt.Errorf("unknown format %q (want text or json)", robustnessFormat)
+	}
+	if robustnessTop < 1 || robustnessSteps < 1 || robustnessSamples < 1 {
+		return fmt.Errorf("--top, --steps and --samples must be positive")
+	}
+	if robustnessRemove < 0 {
+		return fmt.Errorf("--remove must not be negative")
+	}
+	order, err := graph.ParseHubOrder(robustnessBy)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{
+		CachePath:   graphCachePath(),
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	a := g.Adjacency()
+
+	cores, err := graph.KCores(ctx, a)
+	if err != nil {
+		return fmt.Errorf("computing k-cores: %w", err)
+	}
+	cuts, err := graph.FindCuts(ctx, a, robustnessTop)
+	if err != nil {
+		return fmt.Errorf("finding cut points: %w", err)
+	}
+
+	out := robustnessOutput{
+		Nodes:                 g.NodeCount(),
+		Degeneracy:            cores.Degeneracy(),
+		Innermost:             newRankedPageOutputs(cores.Innermost(robustnessTop)),
+		ArticulationPoints:    cuts.ArticulationPoints,
+		Bridges:               cuts.Bridges,
+		TopArticulationPoints: []cutPageOutput{},
+		TopBridges:            []cutLinkOutput{},
+	}
+	for k := 1; k <= cores.Degeneracy(); k++ {
+		out.CoreSizes = append(out.CoreSizes, coreSizeOutput{K: k, Pages: cores.Size(k)})
+	}
+	for _, p := range cuts.TopArticulationPoints {
+		out.TopArticulationPoints = append(out.TopArticulationPoints, cutPageOutput{Title: p.Title, Separated: p.Separated})
+	}
+	for _, l := range cuts.TopBridges {
+		out.TopBridges = append(out.TopBridges, cutLinkOutput{From: l.From, To: l.To, Separated: l.Separated})
+	}
+
+	if robustnessRemove > 0 {
+		report, err := graph.SimulateHubRemoval(ctx, a, graph.RemovalOptions{
+			Remove:      robustnessRemove,
+			Steps:       robustnessSteps,
+			Order:       order,
+			PathSamples: robustnessSamples,
+			Seed:        robustnessSeed,
+		})
+		if err != nil {
+			return fmt.Errorf("simulating hub removal: %w", err)
+		}
+		out.Removal = newRemovalOutput(report)
+	}
+	out.DurationMs = time.Since(start).Milliseconds()
+
+	if robustnessFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	fmt.Printf("Pages:      %s\n", formatNumber(out.Nodes))
+	fmt.Printf("Degeneracy: %d (innermost core of %s pages)\n", out.Degeneracy, formatNumber(cores.Size(out.Degeneracy)))
+
+	fmt.Printf("\nk-core sizes:\n")
+	for _, s := range out.CoreSizes {
+		// Every power of two and the innermost core keep the list short.
+		if s.K&(s.K-1) == 0 || s.K == out.Degeneracy {
+			fmt.Printf("  k >= %-5d %10s pages\n", s.K, formatNumber(s.Pages))
+		}
+	}
+
+	fmt.Printf("\nInnermost core (by neighbours):\n")
+	for _, p := range out.Innermost {
+		fmt.Printf("  %2d. %8s  %s\n", p.Rank, formatNumber(int(p.Score)), p.Title)
+	}
+
+	fmt.Printf("\nArticulation points: %s\n", formatNumber(out.ArticulationPoints))
+	for i, p := range out.TopArticulationPoints {
+		fmt.Printf("  %2d. %8s cut off  %s\n", i+1, formatNumber(p.Separated), p.Title)
+	}
+
+	fmt.Printf("\nBridges: %s\n", formatNumber(out.Bridges))
+	for i, l := range out.TopBridges {
+		fmt.Printf("  %2d. %8s cut off  %s -- %s\n", i+1, formatNumber(l.Separated), l.From, l.To)
+	}
+
+	if r := out.Removal; r != nil {
+		fmt.Printf("\nRemoving the top %d hubs by %s (paths from %d sampled pages):\n", len(r.Hubs), r.By, r.PathSamples)
+		fmt.Printf("  %8s  %12s  %7s  %10s  %8s  %7s\n", "removed", "giant", "giant%", "components", "avg path", "reach%")
+		for _, s := range r.Steps {
+			fmt.Printf("  %8d  %12s  %6.1f%%  %10s  %8.2f  %6.1f%%\n",
+				s.Removed, formatNumber(s.GiantSize), s.GiantPercent, formatNumber(s.Components),
+				s.AveragePathLength, 100*s.Reachability)
+		}
+
+		fmt.Printf("\nHubs removed first:\n")
+		for _, p := range r.Hubs[:min(robustnessTop, len(r.Hubs))] {
+			fmt.Printf("  %2d. %8s  %s\n", p.Rank, formatNumber(int(p.Score)), p.Title)
+		}
+	}
+
+	fmt.Printf("\nComputed in %dms\n", out.DurationMs)
+	return nil
+}
+
+func newRemovalOutput(r *graph.RemovalReport) *removalOutput {
+	out := &removalOutput{
+		By:          string(r.Order),
+		PathSamples: r.PathSamples,
+		Hubs:        newRankedPageOutputs(r.Hubs),
+	}
+	for _, s := range r.Steps {
+		out.Steps = append(out.Steps, removalStepOutput{
+			Removed:           s.Removed,
+			Components:        s.Components,
+			GiantSize:         s.GiantComponent,
+			GiantPercent:      100 * s.GiantFraction,
+			AveragePathLength: s.AveragePathLength,
+			Reachability:      s.Reachability,
+		})
+	}
+	return out
+}
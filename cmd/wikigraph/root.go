@@ -3,10 +3,12 @@ package main
 import (
 	"log/slog"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/config"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
 )
 
 var (
@@ -54,3 +56,38 @@ func setupLogging() {
 	})
 	slog.SetDefault(slog.New(handler))
 }
+
+// graphCachePath returns the configured graph cache path, defaulting to
+// graph.cache next to the database.
+func graphCachePath() string {
+	if cfg.Graph.CachePath != "" {
+		return cfg.Graph.CachePath
+	}
+	return filepath.Join(filepath.Dir(cfg.Database.Path), "graph.cache")
+}
+
+// pageRankOptions returns the PageRank settings from the config.
+func pageRankOptions() (graph.PageRankOptions, error) {
+	dangling, err := graph.ParseDanglingMode(cfg.Graph.PageRankDangling)
+	if err != nil {
+		return graph.PageRankOptions{}, err
+	}
+	return graph.PageRankOptions{
+		Damping:       cfg.Graph.PageRankDamping,
+		Tolerance:     cfg.Graph.PageRankTolerance,
+		MaxIterations: cfg.Graph.PageRankMaxIterations,
+		Dangling:      dangling,
+	}, nil
+}
+
+// landmarkOptions returns the ALT landmark settings from the config.
+func landmarkOptions() (graph.LandmarkOptions, error) {
+	strategy, err := graph.ParseLandmarkStrategy(cfg.Graph.LandmarkStrategy)
+	if err != nil {
+		return graph.LandmarkOptions{}, err
+	}
+	return graph.LandmarkOptions{
+		Count:    cfg.Graph.Landmarks,
+		Strategy: strategy,
+	}, nil
+}
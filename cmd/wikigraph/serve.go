@@ -6,7 +6,6 @@ import (
 	"log/slog"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 
 	"github.com/spf13/cobra"
@@ -15,6 +14,7 @@ import (
 	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
 	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
 	"github.com/Thinh-nguyen-03/wikigraph/internal/fetcher"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
 )
 
 var (
@@ -84,12 +84,21 @@ func runServe(cmd *cobra.Command, args []string) error {
 		BaseURL:        cfg.Scraper.WikipediaAPIURL,
 	})
 
-	// Determine graph cache path
-	cachePath := cfg.Graph.CachePath
-	if cachePath == "" {
-		// Default to same directory as database
-		dbDir := filepath.Dir(cfg.Database.Path)
-		cachePath = filepath.Join(dbDir, "graph.cache")
+	cachePath := graphCachePath()
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	rankOpts, err := pageRankOptions()
+	if err != nil {
+		return err
+	}
+
+	landmarkOpts, err := landmarkOptions()
+	if err != nil {
+		return err
 	}
 
 	// Create GraphService with background loading
@@ -98,6 +107,9 @@ func runServe(cmd *cobra.Command, args []string) error {
 		MaxCacheAge:     cfg.Graph.MaxCacheAge,
 		RefreshInterval: cfg.Graph.RefreshInterval,
 		ForceRebuild:    serveForceRebuild || cfg.Graph.ForceRebuild,
+		Backend:         backend,
+		PageRank:        rankOpts,
+		Landmarks:       landmarkOpts,
 	}
 	graphService := api.NewGraphService(c, graphServiceCfg)
 
@@ -117,6 +129,7 @@ func runServe(cmd *cobra.Command, args []string) error {
 		RateLimit:       cfg.API.RateLimit,
 		RateBurst:       cfg.API.RateBurst,
 		Production:      cfg.API.Production,
+		MaxExplored:     cfg.API.MaxExplored,
 	}
 
 	// Override with command-line flags if provided
@@ -142,8 +155,19 @@ func runServe(cmd *cobra.Command, args []string) error {
 	fmt.Println("\nAvailable endpoints:")
 	fmt.Println("  GET  /health                        - Health check (shows graph status)")
 	fmt.Println("  GET  /api/v1/page/:title            - Get page links")
+	fmt.Println("  GET  /api/v1/search?q=X             - Autocomplete page titles")
 	fmt.Println("  GET  /api/v1/path?from=X&to=Y       - Find shortest path")
+	fmt.Println("  POST /api/v1/paths                  - Batch paths or a distance matrix")
+	fmt.Println("  GET  /api/v1/distance?from=X&to=Y   - Bounds on the hop count")
+	fmt.Println("  GET  /api/v1/rank?n=20              - Top pages by PageRank")
+	fmt.Println("  POST /api/v1/betweenness            - Start computing bridge pages")
+	fmt.Println("  GET  /api/v1/betweenness?k=20       - Top bridge pages once computed")
+	fmt.Println("  GET  /api/v1/graph/stats            - Graph shape statistics")
+	fmt.Println("  GET  /api/v1/communities            - Detected communities")
 	fmt.Println("  GET  /api/v1/connections/:title     - Get N-hop neighborhood")
+	fmt.Println("  GET  /api/v1/similar/:title         - Pages sharing the most links")
+	fmt.Println("  GET  /api/v1/suggest-links/:title   - Likely missing links from a page")
+	fmt.Println("  GET  /api/v1/first-link/:title      - Follow first links until they loop")
 	fmt.Println("  POST /api/v1/crawl                  - Start background crawl")
 	fmt.Println("\nPress Ctrl+C to stop")
 
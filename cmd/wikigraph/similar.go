@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	similarMethod    string
+	similarLimit     int
+	similarThreshold float64
+	similarFormat    string
+)
+
+var similarCmd = &cobra.Command{
+	Use:   "similar <title>",
+	Short: "List pages that share the most links with a page",
+	Long: `List pages that share the most links with a page.
+
+Pages are scored by the links they have in common with the given page:
+
+  jaccard      shared in- and out-links over all of either page's (default)
+  cosine       shared in- and out-links over the geometric mean of both counts
+  adamic_adar  shared in- and out-links, each weighted by 1/log(its degree)
+  cocitation   number of pages linking to both
+  coupling     number of pages both link to
+
+Examples:
+  wikigraph similar "Cat"
+  wikigraph similar "Cat" --method adamic_adar --limit 20
+  wikigraph similar "Cat" --method cocitation --threshold 5 --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSimilar,
+}
+
+func init() {
+	rootCmd.AddCommand(similarCmd)
+
+	similarCmd.Flags().StringVarP(&similarMethod, "method", "m", "jaccard", "score: jaccard, cosine, adamic_adar, cocitation, coupling")
+	similarCmd.Flags().IntVarP(&similarLimit, "limit", "n", 10, "number of pages to list")
+	similarCmd.Flags().Float64Var(&similarThreshold, "threshold", 0, "leave out pages scoring below this")
+	similarCmd.Flags().StringVarP(&similarFormat, "format", "f", "text", "output format: text, json")
+}
+
+type similarOutput struct {
+	Query          string             `json:"query"`
+	RedirectedFrom string             `json:"redirected_from,omitempty"`
+	Method         string             `json:"method"`
+	Similar        []similarPageEntry `json:"similar"`
+	Threshold      float64            `json:"threshold"`
+	DurationMs     int64              `json:"duration_ms"`
+}
+
+type similarPageEntry struct {
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+func runSimilar(cmd *cobra.Command, args []string) error {
+	method, err := graph.ParseSimilarityMethod(similarMethod)
+	if err != nil {
+		return err
+	}
+	if similarLimit < 1 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	if similarThreshold < 0 {
+		return fmt.Errorf("--threshold must not be negative")
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{
+		CachePath:   graphCachePath(),
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	out := similarOutput{Query: g.Canonical(args[0]), Method: string(method), Threshold: similarThreshold}
+	if out.Query != args[0] {
+		out.RedirectedFrom = args[0]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	pages, err := graph.Similar(ctx, g.Adjacency(), out.Query, graph.SimilarityOptions{
+		Method:    method,
+		Limit:     similarLimit,
+		Threshold: similarThreshold,
+	})
+	if err != nil {
+		return fmt.Errorf("finding similar pages: %w", err)
+	}
+	out.DurationMs = time.Since(start).Milliseconds()
+	out.Similar = make([]similarPageEntry, len(pages))
+	for i, p := range pages {
+		out.Similar[i] = similarPageEntry{Title: p.Title, Score: p.Score}
+	}
+
+	if similarFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if out.RedirectedFrom != "" {
+		fmt.Printf("%q redirects to %q\n", out.RedirectedFrom, out.Query)
+	}
+	if len(out.Similar) == 0 {
+		fmt.Printf("No similar pages found for %s\n", out.Query)
+		return nil
+	}
+	for i, p := range out.Similar {
+		fmt.Printf("%4d. %.4f  %s\n", i+1, p.Score, p.Title)
+	}
+	fmt.Println()
+	fmt.Printf("%d pages by %s in %dms\n", len(out.Similar), out.Method, out.DurationMs)
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
+)
+
+var (
+	suggestMethod string
+	suggestLimit  int
+	suggestShared int
+	suggestFormat string
+)
+
+var suggestLinksCmd = &cobra.Command{
+	Use:   "suggest-links <title>",
+	Short: "Suggest links a page is probably missing",
+	Long: `Suggest links a page is probably missing.
+
+Candidates are pages the given page does not link to that share neighbours
+with it, counting links in either direction. Each is scored by:
+
+  common_neighbors     number of shared neighbours
+  adamic_adar          sum of 1/log(degree) over shared neighbours (default)
+  resource_allocation  sum of 1/degree over shared neighbours
+
+Both weighted scores favour pages connected through rarely linked
+neighbours over those connected through hubs. Every suggestion lists the
+shared neighbours behind it, least linked first.
+
+Examples:
+  wikigraph suggest-links "Cat"
+  wikigraph suggest-links "Cat" --method resource_allocation --limit 25
+  wikigraph suggest-links "Cat" --shared 3 --format json`,
+	Args: cobra.ExactArgs(1),
+	RunE: runSuggestLinks,
+}
+
+func init() {
+	rootCmd.AddCommand(suggestLinksCmd)
+
+	suggestLinksCmd.Flags().StringVarP(&suggestMethod, "method", "m", "adamic_adar", "score: common_neighbors, adamic_adar, resource_allocation")
+	suggestLinksCmd.Flags().IntVarP(&suggestLimit, "limit", "n", 10, "number of suggestions to list")
+	suggestLinksCmd.Flags().IntVar(&suggestShared, "shared", 5, "shared neighbours to list with each suggestion")
+	suggestLinksCmd.Flags().StringVarP(&suggestFormat, "format", "f", "text", "output format: text, json")
+}
+
+type suggestLinksOutput struct {
+	Title          string                 `json:"title"`
+	RedirectedFrom string                 `json:"redirected_from,omitempty"`
+	Method         string                 `json:"method"`
+	Suggestions    []linkSuggestionOutput `json:"suggestions"`
+	DurationMs     int64                  `json:"duration_ms"`
+}
+
+type linkSuggestionOutput struct {
+	Title       string   `json:"title"`
+	Score       float64  `json:"score"`
+	Shared      []string `json:"shared"`
+	SharedCount int      `json:"shared_count"`
+}
+
+func runSuggestLinks(cmd *cobra.Command, args []string) error {
+	scorer, err := graph.ParseLinkScorer(suggestMethod)
+	if err != nil {
+		return err
+	}
+	if suggestLimit < 1 {
+		return fmt.Errorf("--limit must be positive")
+	}
+	if suggestShared < 1 {
+		return fmt.Errorf("--shared must be positive")
+	}
+
+	db, err := database.Open(cfg.Database.Path)
+	if err != nil {
+		return fmt.Errorf("opening database: %w", err)
+	}
+	defer db.Close()
+
+	if err := db.Migrate(); err != nil {
+		return fmt.Errorf("running migrations: %w", err)
+	}
+
+	backend, err := graph.ParseBackend(cfg.Graph.Backend)
+	if err != nil {
+		return err
+	}
+
+	loader := graph.NewLoaderWithConfig(cache.New(db), graph.LoaderConfig{
+		CachePath:   graphCachePath(),
+		MaxCacheAge: cfg.Graph.MaxCacheAge,
+		Backend:     backend,
+	})
+
+	g, err := loader.LoadView()
+	if err != nil {
+		return fmt.Errorf("loading graph: %w", err)
+	}
+	if g.NodeCount() == 0 {
+		return fmt.Errorf("graph is empty - use 'wikigraph fetch' to crawl pages first")
+	}
+
+	out := suggestLinksOutput{Title: g.Canonical(args[0]), Method: string(scorer)}
+	if out.Title != args[0] {
+		out.RedirectedFrom = args[0]
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	start := time.Now()
+	suggestions, err := graph.SuggestLinks(ctx, g.Adjacency(), out.Title, graph.LinkPredictionOptions{
+		Scorer:    scorer,
+		Limit:     suggestLimit,
+		MaxShared: suggestShared,
+	})
+	if err != nil {
+		return fmt.Errorf("suggesting links: %w", err)
+	}
+	out.DurationMs = time.Since(start).Milliseconds()
+	out.Suggestions = make([]linkSuggestionOutput, len(suggestions))
+	for i, s := range suggestions {
+		out.Suggestions[i] = linkSuggestionOutput{
+			Title:       s.Title,
+			Score:       s.Score,
+			Shared:      s.Shared,
+			SharedCount: s.SharedCount,
+		}
+	}
+
+	if suggestFormat == "json" {
+		data, err := json.MarshalIndent(out, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+		return nil
+	}
+
+	if out.RedirectedFrom != "" {
+		fmt.Printf("%q redirects to %q\n", out.RedirectedFrom, out.Title)
+	}
+	if len(out.Suggestions) == 0 {
+		fmt.Printf("No missing links found for %s\n", out.Title)
+		return nil
+	}
+	for i, s := range out.Suggestions {
+		fmt.Printf("%4d. %.4f  %s\n", i+1, s.Score, s.Title)
+		via := strings.Join(s.Shared, ", ")
+		if more := s.SharedCount - len(s.Shared); more > 0 {
+			via += fmt.Sprintf(" and %d more", more)
+		}
+		fmt.Printf("      via %s\n", via)
+	}
+	fmt.Println()
+	fmt.Printf("%d suggestions by %s in %dms\n", len(out.Suggestions), out.Method, out.DurationMs)
+	return nil
+}
@@ -15,6 +15,7 @@ type Config struct {
 	RateLimit       float64 // requests per second per IP
 	RateBurst       int     // burst capacity for rate limiter
 	Production      bool    // set gin.ReleaseMode
+	MaxExplored     int     // max nodes a graph search may expand (0 = no cap)
 }
 
 // DefaultConfig returns sensible defaults for the API server.
@@ -29,4 +30,5 @@ var DefaultConfig = Config{
 	RateLimit:       100.0,
 	RateBurst:       200,
 	Production:      false,
+	MaxExplored:     5_000_000,
 }
@@ -9,9 +9,10 @@ import (
 
 // APIError represents an error response from the API.
 type APIError struct {
-	Code       string `json:"error"`
-	Message    string `json:"message"`
-	StatusCode int    `json:"-"`
+	Code        string   `json:"error"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	StatusCode  int      `json:"-"`
 }
 
 // Error implements the error interface.
@@ -79,9 +80,10 @@ func RespondWithError(c *gin.Context, err *APIError) {
 	reqIDStr, _ := requestID.(string)
 
 	c.JSON(err.StatusCode, ErrorResponse{
-		Error:     err.Code,
-		Message:   err.Message,
-		RequestID: reqIDStr,
+		Error:       err.Code,
+		Message:     err.Message,
+		Suggestions: err.Suggestions,
+		RequestID:   reqIDStr,
 	})
 }
 
@@ -105,12 +107,14 @@ func RespondWithMissingParam(c *gin.Context, param string) {
 	RespondWithError(c, err)
 }
 
-// RespondWithNotFound writes a not found error for a specific resource.
-func RespondWithNotFound(c *gin.Context, resource, identifier string) {
-	err := NewAPIError(
-		"not_found",
-		fmt.Sprintf("%s '%s' not found", resource, identifier),
-		http.StatusNotFound,
-	)
+// RespondWithNotFound writes a not found error for a specific resource,
+// with a "did you mean" hint when suggestions are given.
+func RespondWithNotFound(c *gin.Context, resource, identifier string, suggestions ...string) {
+	message := fmt.Sprintf("%s '%s' not found", resource, identifier)
+	if len(suggestions) > 0 {
+		message += fmt.Sprintf(". Did you mean '%s'?", suggestions[0])
+	}
+	err := NewAPIError("not_found", message, http.StatusNotFound)
+	err.Suggestions = suggestions
 	RespondWithError(c, err)
 }
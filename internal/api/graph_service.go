@@ -2,9 +2,12 @@ package api
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log/slog"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
@@ -40,6 +43,11 @@ func (s LoadState) String() string {
 	}
 }
 
+// MarshalText encodes the state by name.
+func (s LoadState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
 // GraphServiceConfig configures the GraphService behavior.
 type GraphServiceConfig struct {
 	// CachePath is the path to the graph cache file.
@@ -54,9 +62,21 @@ type GraphServiceConfig struct {
 
 	// ForceRebuild forces a complete rebuild ignoring cache.
 	ForceRebuild bool
+
+	// Backend selects the in-memory graph representation.
+	Backend graph.Backend
+
+	// PageRank configures the global ranking computed after each load.
+	PageRank graph.PageRankOptions
+
+	// Landmarks configures the distance tables for ALT path searches,
+	// computed after the ranking. A zero Count disables them.
+	Landmarks graph.LandmarkOptions
 }
 
-// LoadProgress tracks the progress of graph loading.
+// LoadProgress tracks the progress of graph loading. While loading, Stage
+// is one of the loader's stages (graph.StageLinks and so on) and
+// StageProgress reports how far it has got.
 type LoadProgress struct {
 	State       LoadState     `json:"state"`
 	Stage       string        `json:"stage"`
@@ -66,6 +86,172 @@ type LoadProgress struct {
 	Error       string        `json:"error,omitempty"`
 	CacheHit    bool          `json:"cache_hit"`
 	CacheAge    time.Duration `json:"cache_age_seconds,omitempty"`
+	StageProgress
+}
+
+// MarshalJSON writes the durations in the units their names give.
+func (p LoadProgress) MarshalJSON() ([]byte, error) {
+	type fields LoadProgress
+	return json.Marshal(struct {
+		fields
+		Duration int64 `json:"duration_ms,omitempty"`
+		CacheAge int64 `json:"cache_age_seconds,omitempty"`
+	}{fields(p), p.Duration.Milliseconds(), int64(p.CacheAge.Seconds())})
+}
+
+// StageProgress reports how far the current stage of a graph load has got:
+// the rows read so far and an estimate of the stage's total, from which
+// the percentage done and the time left are worked out. The estimate and
+// the figures derived from it are zero for stages of unknown size.
+type StageProgress struct {
+	RowsProcessed int64   `json:"rows_processed,omitempty"`
+	RowsEstimated int64   `json:"rows_estimated,omitempty"`
+	Percent       float64 `json:"percent,omitempty"`
+	ETASeconds    float64 `json:"eta_seconds,omitempty"`
+
+	stageStarted time.Time
+}
+
+// advance records a progress report from the loader; newStage is set when
+// the report starts a stage.
+func (sp *StageProgress) advance(p graph.Progress, newStage bool, now time.Time) {
+	if newStage {
+		sp.stageStarted = now
+	}
+	sp.RowsProcessed = p.Rows
+	sp.RowsEstimated = p.EstimatedRows
+	sp.Percent, sp.ETASeconds = 0, 0
+	if p.EstimatedRows <= 0 {
+		return
+	}
+
+	// The estimate can fall short, so a stage never shows as done before it is.
+	sp.Percent = min(roundTenth(100*float64(p.Rows)/float64(p.EstimatedRows)), 99.9)
+	if elapsed := now.Sub(sp.stageStarted).Seconds(); p.Rows > 0 && elapsed > 0 {
+		rate := float64(p.Rows) / elapsed
+		sp.ETASeconds = roundTenth(float64(max(p.EstimatedRows-p.Rows, 0)) / rate)
+	}
+}
+
+func roundTenth(x float64) float64 {
+	return math.Round(x*10) / 10
+}
+
+// BetweennessStatus reports the progress of the background betweenness
+// computation.
+type BetweennessStatus struct {
+	State      string    `json:"state"` // idle, running, complete, canceled or failed
+	Samples    int       `json:"samples"`
+	Done       int       `json:"done"`
+	Total      int       `json:"total"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// RebuildStatus reports the progress of a rebuild started by ForceReload,
+// which runs while the current snapshot keeps serving. Stage and
+// StageProgress follow the load as LoadProgress does.
+type RebuildStatus struct {
+	State       string    `json:"state"` // idle, running, complete or failed
+	Stage       string    `json:"stage,omitempty"`
+	StartedAt   time.Time `json:"started_at,omitempty"`
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	DurationMs  int64     `json:"duration_ms"`
+	Error       string    `json:"error,omitempty"`
+	StageProgress
+}
+
+// ServingStatus describes the snapshot answering queries and which of its
+// derived data is ready.
+type ServingStatus struct {
+	Version         uint64    `json:"version"`
+	Nodes           int       `json:"nodes"`
+	Edges           int       `json:"edges"`
+	Since           time.Time `json:"since"`
+	ComponentsReady bool      `json:"components_ready"`
+	PageRankReady   bool      `json:"pagerank_ready"`
+	LandmarksReady  bool      `json:"landmarks_ready"`
+	SearchReady     bool      `json:"search_ready"`
+}
+
+// Snapshot is one immutable version of the graph and the data derived from
+// it. A request loads the current snapshot once and reads everything from
+// it, so an update applied meanwhile is never seen halfway.
+type Snapshot struct {
+	// Version increases by one with every new graph.
+	Version uint64
+	Graph   graph.View
+	// CreatedAt is when this version started serving.
+	CreatedAt time.Time
+
+	// Titles, Components and Landmarks are nil until computed for this
+	// graph. Ranks is carried over from the previous version until the new
+	// ranking is ready.
+	Titles     *graph.TitleIndex
+	Components *graph.Components
+	Ranks      *graph.Ranks
+	Landmarks  *graph.Landmarks
+
+	// lease keeps the memory-mapped snapshot file Graph reads from, if
+	// any, mapped while this snapshot is in use.
+	lease *mapLease
+}
+
+// Canonical resolves a title as the graph does and, failing that, to the
+// one page whose title matches it ignoring case, once the title index is
+// ready.
+func (s *Snapshot) Canonical(title string) string {
+	canonical := s.Graph.Canonical(title)
+	if s.Titles == nil || s.Graph.HasNode(canonical) {
+		return canonical
+	}
+	if page, ok := s.Titles.Lookup(title); ok {
+		return page
+	}
+	return canonical
+}
+
+// mapLease counts the users of a memory-mapped graph: one for as long as it
+// backs the current snapshot, plus one for each request or background
+// computation reading it. The last to let go unmaps it. A nil lease, for a
+// graph on the heap, does nothing.
+type mapLease struct {
+	csr  *graph.CSR
+	refs atomic.Int64
+}
+
+// newMapLease returns a lease held by the current snapshot on the mapped
+// CSR g reads from, or nil if g is not memory-mapped.
+func newMapLease(g graph.View) *mapLease {
+	var c *graph.CSR
+	switch g := g.(type) {
+	case *graph.CSR:
+		c = g
+	case *graph.Overlay:
+		c = g.Base()
+	}
+	if c == nil || !c.Mapped() {
+		return nil
+	}
+	l := &mapLease{csr: c}
+	l.refs.Store(1)
+	return l
+}
+
+func (l *mapLease) acquire() {
+	if l != nil {
+		l.refs.Add(1)
+	}
+}
+
+func (l *mapLease) release() {
+	if l == nil || l.refs.Add(-1) > 0 {
+		return
+	}
+	if err := l.csr.Close(); err != nil {
+		slog.Warn("failed to unmap graph snapshot", "error", err)
+	}
 }
 
 // GraphService manages the graph lifecycle including background loading,
@@ -76,29 +262,75 @@ type GraphService struct {
 	config GraphServiceConfig
 
 	mu       sync.RWMutex
-	g        *graph.Graph
 	state    LoadState
 	progress LoadProgress
 	loadErr  error
 
+	// current is the snapshot requests read from. Snapshots are replaced,
+	// never modified, so readers load it without waiting on mu; writers
+	// swap it while holding mu. Readers of its graph acquire its lease
+	// under mu, so a replaced mapping is never unmapped under them.
+	current atomic.Pointer[Snapshot]
+
+	// writeMu serializes the updates and reloads that build new graphs.
+	writeMu sync.Mutex
+
+	derivedCancel context.CancelFunc
+
+	// Betweenness runs one computation at a time; the result is only
+	// served while btwVersion matches the current snapshot.
+	betweenness *graph.Centrality
+	btwStatus   BetweennessStatus
+	btwVersion  uint64
+	btwCancel   context.CancelFunc
+
+	// The latest statistics report is kept while the snapshot and the
+	// options it was computed with stay the same.
+	statistics   *graph.Statistics
+	statsOptions graph.StatisticsOptions
+	statsVersion uint64
+
+	rebuild RebuildStatus
+
 	// For graceful shutdown
+	ctx    context.Context
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 }
 
 // NewGraphService creates a new graph service.
 func NewGraphService(c *cache.Cache, cfg GraphServiceConfig) *GraphService {
-	loader := graph.NewLoaderWithConfig(c, graph.LoaderConfig{
+	gs := &GraphService{
+		cache:  c,
+		config: cfg,
+		state:  StateUninitialized,
+	}
+	gs.loader = graph.NewLoaderWithConfig(c, graph.LoaderConfig{
 		CachePath:    cfg.CachePath,
 		MaxCacheAge:  cfg.MaxCacheAge,
 		ForceRebuild: cfg.ForceRebuild,
+		Backend:      cfg.Backend,
+		Progress:     gs.recordLoadProgress,
 	})
+	return gs
+}
 
-	return &GraphService{
-		loader: loader,
-		cache:  c,
-		config: cfg,
-		state:  StateUninitialized,
+// recordLoadProgress passes a progress report from the loader on to the
+// initial load and to a running rebuild, whichever are in progress.
+func (gs *GraphService) recordLoadProgress(p graph.Progress) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	now := time.Now()
+	if gs.state == StateLoading {
+		newStage := gs.progress.Stage != p.Stage
+		gs.progress.Stage = p.Stage
+		gs.progress.advance(p, newStage, now)
+	}
+	if gs.rebuild.State == "running" {
+		newStage := gs.rebuild.Stage != p.Stage
+		gs.rebuild.Stage = p.Stage
+		gs.rebuild.advance(p, newStage, now)
 	}
 }
 
@@ -106,6 +338,7 @@ func NewGraphService(c *cache.Cache, cfg GraphServiceConfig) *GraphService {
 // This method returns immediately - use IsReady() to check load status.
 func (gs *GraphService) Start(ctx context.Context) {
 	ctx, gs.cancel = context.WithCancel(ctx)
+	gs.ctx = ctx
 
 	// Start background loading
 	gs.wg.Add(1)
@@ -142,7 +375,7 @@ func (gs *GraphService) backgroundLoad(ctx context.Context) {
 	slog.Info("starting background graph load")
 
 	// Load the graph
-	g, err := gs.loader.Load()
+	g, err := gs.loader.LoadView()
 
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
@@ -160,10 +393,11 @@ func (gs *GraphService) backgroundLoad(ctx context.Context) {
 		return
 	}
 
-	gs.g = g
 	gs.state = StateReady
 	gs.progress.State = StateReady
 	gs.progress.Stage = "complete"
+	gs.progress.StageProgress = StageProgress{}
+	gs.publish(g)
 
 	// Check if we used cache
 	if info, err := gs.loader.GetCacheInfo(); err == nil {
@@ -209,7 +443,9 @@ func (gs *GraphService) periodicRefresh(ctx context.Context) {
 	}
 }
 
-// checkAndApplyUpdates checks for database changes and applies them to the graph.
+// checkAndApplyUpdates checks for database changes and applies them to the
+// graph. The changed pages are layered over the current graph as a new
+// version, so requests keep reading the old one until it is swapped in.
 func (gs *GraphService) checkAndApplyUpdates(ctx context.Context, since time.Time) error {
 	// Get pages updated since last check
 	updates, err := gs.cache.GetUpdatedPages(since)
@@ -221,16 +457,28 @@ func (gs *GraphService) checkAndApplyUpdates(ctx context.Context, since time.Tim
 		return nil // No updates
 	}
 
-	slog.Info("applying incremental graph updates", "pages_changed", len(updates))
+	gs.writeMu.Lock()
+	defer gs.writeMu.Unlock()
 
-	gs.mu.Lock()
-	defer gs.mu.Unlock()
+	snap, release, err := gs.AcquireSnapshot()
+	if err != nil {
+		return nil
+	}
+	defer release()
+	base, err := graph.OverlayOf(snap.Graph)
+	if err != nil {
+		return err
+	}
 
-	for _, update := range updates {
-		// Remove old edges for this page
-		gs.g.RemoveOutLinks(update.Title)
+	slog.Info("applying incremental graph updates", "pages_changed", len(updates))
 
-		// Add new edges if page was successfully fetched
+	changed := make(map[string][]string, len(updates))
+	for _, update := range updates {
+		// Redirect pages live on as aliases of their target, not as nodes.
+		if snap.Graph.Canonical(update.Title) != update.Title {
+			continue
+		}
+		changed[update.Title] = nil
 		if update.FetchStatus == "success" {
 			links, err := gs.cache.GetPageLinks(update.ID)
 			if err != nil {
@@ -238,25 +486,358 @@ func (gs *GraphService) checkAndApplyUpdates(ctx context.Context, since time.Tim
 					"title", update.Title,
 					"error", err,
 				)
+				delete(changed, update.Title)
 				continue
 			}
-			for _, target := range links {
-				gs.g.AddEdge(update.Title, target)
-			}
+			changed[update.Title] = canonicalLinks(snap.Graph, update.Title, links)
 		}
 	}
+	next := base.WithOutLinks(changed)
 
-	// Save updated graph to cache
+	// Persist before publishing, so the data derived from next is cached
+	// alongside it. Only the changes are written until WithOutLinks folds
+	// them into a new base, which is then saved whole and mapped in its place.
 	if gs.config.CachePath != "" {
-		if err := gs.g.Save(gs.config.CachePath); err != nil {
+		saved, err := next.Persist(gs.config.CachePath)
+		if err != nil {
 			slog.Warn("failed to save updated cache", "error", err)
+		} else {
+			next = saved
 		}
 	}
 
-	slog.Info("incremental update complete", "pages_updated", len(updates))
+	gs.mu.Lock()
+	published := gs.publish(next)
+	gs.mu.Unlock()
+
+	slog.Info("incremental update complete",
+		"pages_updated", len(updates),
+		"version", published.Version,
+		"overlay_pages", next.Changed(),
+	)
 	return nil
 }
 
+// canonicalLinks rewrites link targets that are redirects to the pages they
+// lead to, dropping links that lead back to the source. Redirects found
+// since the graph was loaded take effect on the next full rebuild.
+func canonicalLinks(g graph.View, source string, targets []string) []string {
+	canonical := make([]string, 0, len(targets))
+	for _, target := range targets {
+		c := g.Canonical(target)
+		if c != target && c == source {
+			continue
+		}
+		canonical = append(canonical, c)
+	}
+	return canonical
+}
+
+// publish makes g the current graph as a new snapshot version and starts
+// recomputing its derived data. A snapshot file the previous version was
+// mapped from is unmapped once the requests still reading it finish, unless
+// g reads from it too. Callers must hold gs.mu.
+func (gs *GraphService) publish(g graph.View) *Snapshot {
+	next := &Snapshot{Version: 1, Graph: g, CreatedAt: time.Now()}
+	prev := gs.current.Load()
+	if prev != nil {
+		next.Version = prev.Version + 1
+		next.Ranks = prev.Ranks
+	}
+	next.lease = newMapLease(g)
+	if prev != nil && prev.lease != nil && next.lease != nil && prev.lease.csr == next.lease.csr {
+		next.lease = prev.lease
+	}
+	gs.current.Store(next)
+	if prev != nil && prev.lease != next.lease {
+		prev.lease.release()
+	}
+	gs.refreshDerived(next)
+	return next
+}
+
+// refreshDerived recomputes the data derived from snap's graph in the
+// background: first its title index, then its strongly connected
+// components, then its global PageRank, then the ALT landmarks. Any
+// computation still running for an older snapshot is canceled. The
+// previous ranking keeps being served until the new one is ready, but
+// components and landmarks are never carried over since they could wrongly
+// rule out a path to a new page, nor is the title index, which refers to
+// pages by their ID in the old graph. Callers must hold gs.mu.
+func (gs *GraphService) refreshDerived(snap *Snapshot) {
+	if gs.derivedCancel != nil {
+		gs.derivedCancel()
+	}
+
+	parent := gs.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	gs.derivedCancel = cancel
+
+	g := snap.Graph
+	version := snap.Version
+	lease := snap.lease
+	lease.acquire()
+
+	gs.wg.Add(1)
+	go func() {
+		defer gs.wg.Done()
+		defer cancel()
+		defer lease.release()
+
+		a := g.Adjacency()
+
+		start := time.Now()
+		titles, err := graph.NewTitleIndex(ctx, g)
+		if err != nil {
+			return
+		}
+		if !gs.storeDerived(version, func(s *Snapshot) { s.Titles = titles }) {
+			return
+		}
+		slog.Info("title index ready",
+			"titles", titles.Len(),
+			"duration", time.Since(start).Round(time.Millisecond),
+		)
+
+		start = time.Now()
+		sccs, err := graph.StronglyConnectedComponents(ctx, a)
+		if err != nil {
+			return
+		}
+		if !gs.storeDerived(version, func(s *Snapshot) { s.Components = sccs }) {
+			return
+		}
+		slog.Info("strongly connected components ready",
+			"components", sccs.Count(),
+			"singletons", sccs.Singletons(),
+			"duration", time.Since(start).Round(time.Millisecond),
+		)
+
+		start = time.Now()
+		r, err := graph.CachedPageRank(ctx, a, gs.config.CachePath, gs.config.PageRank)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("pagerank computation failed", "error", err)
+			}
+			return
+		}
+		if !gs.storeDerived(version, func(s *Snapshot) { s.Ranks = r }) {
+			return
+		}
+		slog.Info("pagerank ready",
+			"iterations", r.Iterations,
+			"converged", r.Converged,
+			"duration", time.Since(start).Round(time.Millisecond),
+		)
+
+		if gs.config.Landmarks.Count <= 0 {
+			return
+		}
+		start = time.Now()
+		l, err := graph.CachedLandmarks(ctx, a, gs.config.CachePath, gs.config.Landmarks)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("landmark computation failed", "error", err)
+			}
+			return
+		}
+		if !gs.storeDerived(version, func(s *Snapshot) { s.Landmarks = l }) {
+			return
+		}
+		slog.Info("landmarks ready",
+			"landmarks", l.Count(),
+			"strategy", l.Options.Strategy,
+			"duration", time.Since(start).Round(time.Millisecond),
+		)
+	}()
+}
+
+// storeDerived swaps in a copy of the current snapshot with store applied,
+// if it is still at the given version, and reports whether it did.
+func (gs *GraphService) storeDerived(version uint64, store func(*Snapshot)) bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+	cur := gs.current.Load()
+	if cur == nil || cur.Version != version {
+		return false
+	}
+	next := *cur
+	store(&next)
+	gs.current.Store(&next)
+	return true
+}
+
+// currentVersion returns the version of the current snapshot, or zero
+// before the first graph is loaded.
+func (gs *GraphService) currentVersion() uint64 {
+	if snap := gs.current.Load(); snap != nil {
+		return snap.Version
+	}
+	return 0
+}
+
+// LandmarksEnabled reports whether ALT landmarks are computed at all.
+func (gs *GraphService) LandmarksEnabled() bool {
+	return gs.config.Landmarks.Count > 0
+}
+
+// PageRankOptions returns the options rankings are computed with.
+func (gs *GraphService) PageRankOptions() graph.PageRankOptions {
+	return gs.config.PageRank
+}
+
+// StartBetweenness starts estimating betweenness centrality from the given
+// number of pivots in the background. It does nothing if a computation with
+// the same settings is already running or complete for the current graph;
+// any other running computation is canceled first.
+func (gs *GraphService) StartBetweenness(samples int) {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	snap := gs.current.Load()
+	if snap == nil {
+		return
+	}
+	current := gs.btwVersion == snap.Version && gs.btwStatus.Samples == samples
+	if current && (gs.btwStatus.State == "running" || gs.btwStatus.State == "complete") {
+		return
+	}
+	if gs.btwCancel != nil {
+		gs.btwCancel()
+	}
+
+	parent := gs.ctx
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithCancel(parent)
+	gs.btwCancel = cancel
+
+	g := snap.Graph
+	version := snap.Version
+	lease := snap.lease
+	lease.acquire()
+	gs.betweenness = nil
+	gs.btwVersion = version
+	gs.btwStatus = BetweennessStatus{
+		State:     "running",
+		Samples:   samples,
+		StartedAt: time.Now(),
+	}
+
+	slog.Info("starting betweenness computation", "samples", samples)
+
+	gs.wg.Add(1)
+	go func() {
+		defer gs.wg.Done()
+		defer cancel()
+		defer lease.release()
+
+		c, err := graph.Betweenness(ctx, g.Adjacency(), graph.BetweennessOptions{
+			Samples: samples,
+			Progress: func(done, total int) {
+				gs.mu.Lock()
+				if gs.btwVersion == version && gs.btwStatus.Samples == samples {
+					gs.btwStatus.Done = done
+					gs.btwStatus.Total = total
+				}
+				gs.mu.Unlock()
+			},
+		})
+
+		gs.mu.Lock()
+		defer gs.mu.Unlock()
+
+		// A newer computation has taken over.
+		if gs.btwVersion != version || gs.btwStatus.Samples != samples || gs.btwStatus.State != "running" {
+			return
+		}
+
+		gs.btwStatus.DurationMs = time.Since(gs.btwStatus.StartedAt).Milliseconds()
+		switch {
+		case ctx.Err() != nil:
+			gs.btwStatus.State = "canceled"
+		case err != nil:
+			gs.btwStatus.State = "failed"
+			gs.btwStatus.Error = err.Error()
+			slog.Error("betweenness computation failed", "error", err)
+		default:
+			gs.betweenness = c
+			gs.btwStatus.State = "complete"
+			slog.Info("betweenness computation complete",
+				"samples", c.Samples,
+				"duration", time.Since(gs.btwStatus.StartedAt).Round(time.Millisecond),
+			)
+		}
+	}()
+}
+
+// GetBetweenness returns the betweenness result for the current graph, or
+// nil if none is complete, along with the status of the latest computation.
+func (gs *GraphService) GetBetweenness() (*graph.Centrality, BetweennessStatus) {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	status := gs.btwStatus
+	if status.State == "" {
+		status.State = "idle"
+	}
+	if status.State == "running" {
+		status.DurationMs = time.Since(status.StartedAt).Milliseconds()
+	}
+	if gs.btwVersion != gs.currentVersion() {
+		return nil, status
+	}
+	return gs.betweenness, status
+}
+
+// CancelBetweenness stops a running betweenness computation.
+// Returns false if none was running.
+func (gs *GraphService) CancelBetweenness() bool {
+	gs.mu.Lock()
+	defer gs.mu.Unlock()
+
+	if gs.btwStatus.State != "running" {
+		return false
+	}
+	gs.btwCancel()
+	gs.btwStatus.State = "canceled"
+	gs.btwStatus.DurationMs = time.Since(gs.btwStatus.StartedAt).Milliseconds()
+	slog.Info("betweenness computation canceled")
+	return true
+}
+
+// GetStatistics returns the statistics report for snap's graph, computing
+// it first unless a report with the same options is kept. The computation
+// stops when ctx is done.
+func (gs *GraphService) GetStatistics(ctx context.Context, snap *Snapshot, opts graph.StatisticsOptions) (*graph.Statistics, error) {
+	gs.mu.RLock()
+	if gs.statistics != nil && gs.statsVersion == snap.Version && gs.statsOptions == opts {
+		s := gs.statistics
+		gs.mu.RUnlock()
+		return s, nil
+	}
+	gs.mu.RUnlock()
+
+	start := time.Now()
+	s, err := graph.ComputeStatistics(ctx, snap.Graph.Adjacency(), opts)
+	if err != nil {
+		return nil, err
+	}
+	gs.mu.Lock()
+	if snap.Version == gs.currentVersion() {
+		gs.statistics = s
+		gs.statsOptions = opts
+		gs.statsVersion = snap.Version
+	}
+	gs.mu.Unlock()
+	slog.Info("graph statistics computed", "duration", time.Since(start).Round(time.Millisecond))
+	return s, nil
+}
+
 // IsReady returns true if the graph is loaded and ready for queries.
 func (gs *GraphService) IsReady() bool {
 	gs.mu.RLock()
@@ -278,81 +859,184 @@ func (gs *GraphService) GetProgress() LoadProgress {
 	return gs.progress
 }
 
-// GetGraph returns the loaded graph if ready.
+// GetGraph returns the graph of the current snapshot if ready.
+// Returns an error if the graph is not yet loaded or failed to load.
+func (gs *GraphService) GetGraph() (graph.View, error) {
+	snap, err := gs.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	return snap.Graph, nil
+}
+
+// GetSnapshot returns the current snapshot if the graph is ready.
 // Returns an error if the graph is not yet loaded or failed to load.
-func (gs *GraphService) GetGraph() (*graph.Graph, error) {
+// A memory-mapped graph may be unmapped once the snapshot is replaced, so
+// code that reads the graph should use AcquireSnapshot instead.
+func (gs *GraphService) GetSnapshot() (*Snapshot, error) {
+	snap, release, err := gs.AcquireSnapshot()
+	if err != nil {
+		return nil, err
+	}
+	release()
+	return snap, nil
+}
+
+// AcquireSnapshot returns the current snapshot if the graph is ready, along
+// with a function that must be called once the caller is done reading it.
+// Until then, the snapshot's graph stays mapped even if it is replaced.
+// Returns an error if the graph is not yet loaded or failed to load.
+func (gs *GraphService) AcquireSnapshot() (*Snapshot, func(), error) {
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
 
 	switch gs.state {
 	case StateReady:
-		return gs.g, nil
+		snap := gs.current.Load()
+		snap.lease.acquire()
+		return snap, snap.lease.release, nil
 	case StateError:
-		return nil, fmt.Errorf("graph loading failed: %w", gs.loadErr)
+		return nil, nil, fmt.Errorf("graph loading failed: %w", gs.loadErr)
 	case StateLoading:
-		return nil, fmt.Errorf("graph is still loading")
+		return nil, nil, fmt.Errorf("graph is still loading")
 	default:
-		return nil, fmt.Errorf("graph service not started")
+		return nil, nil, fmt.Errorf("graph service not started")
 	}
 }
 
-// GetGraphStats returns basic graph statistics even during loading.
-func (gs *GraphService) GetGraphStats() (nodes int, edges int) {
+// GetGraphStats returns basic statistics and the version of the current
+// snapshot, even during loading. All are zero before the first load.
+func (gs *GraphService) GetGraphStats() (nodes, edges int, version uint64) {
+	// Holding mu keeps the snapshot current, and so mapped, while it is read.
 	gs.mu.RLock()
 	defer gs.mu.RUnlock()
-
-	if gs.g != nil {
-		return gs.g.NodeCount(), gs.g.EdgeCount()
+	if snap := gs.current.Load(); snap != nil {
+		return snap.Graph.NodeCount(), snap.Graph.EdgeCount(), snap.Version
 	}
-	return 0, 0
+	return 0, 0, 0
 }
 
-// ForceReload triggers a complete graph rebuild from the database.
-// This runs in the background and saves to cache when complete.
+// ForceReload triggers a complete graph rebuild from the database and saves
+// it to the cache. Once a graph is being served, the rebuild runs alongside
+// it and the result is swapped in as a new snapshot, so queries are never
+// turned away; if the rebuild fails, the old graph keeps serving. Before
+// the first graph is ready it loads like the initial load does.
 func (gs *GraphService) ForceReload(ctx context.Context) error {
 	gs.mu.Lock()
 	if gs.state == StateLoading {
 		gs.mu.Unlock()
 		return fmt.Errorf("graph is already loading")
 	}
-	gs.state = StateLoading
-	gs.progress = LoadProgress{
-		State:     StateLoading,
-		Stage:     "rebuilding",
+	if gs.rebuild.State == "running" {
+		gs.mu.Unlock()
+		return fmt.Errorf("graph is already rebuilding")
+	}
+	serving := gs.state == StateReady
+	gs.rebuild = RebuildStatus{
+		State:     "running",
+		Stage:     "loading",
 		StartedAt: time.Now(),
 	}
+	if !serving {
+		gs.state = StateLoading
+		gs.progress = LoadProgress{
+			State:     StateLoading,
+			Stage:     "rebuilding",
+			StartedAt: gs.rebuild.StartedAt,
+		}
+	}
 	gs.mu.Unlock()
 
-	slog.Info("forcing graph rebuild")
+	slog.Info("forcing graph rebuild", "serving", serving)
+
+	// Incremental updates wait for the rebuild, which reads them anyway.
+	gs.writeMu.Lock()
+	defer gs.writeMu.Unlock()
 
-	g, err := gs.loader.Rebuild()
+	g, err := gs.loader.RebuildView()
 
 	gs.mu.Lock()
 	defer gs.mu.Unlock()
 
-	gs.progress.CompletedAt = time.Now()
-	gs.progress.Duration = gs.progress.CompletedAt.Sub(gs.progress.StartedAt)
+	gs.rebuild.CompletedAt = time.Now()
+	gs.rebuild.DurationMs = gs.rebuild.CompletedAt.Sub(gs.rebuild.StartedAt).Milliseconds()
+	gs.rebuild.Stage = ""
+	gs.rebuild.StageProgress = StageProgress{}
 
 	if err != nil {
+		gs.rebuild.State = "failed"
+		gs.rebuild.Error = err.Error()
+		if serving {
+			slog.Error("graph rebuild failed, keeping the current graph",
+				"error", err,
+				"version", gs.currentVersion(),
+			)
+			return err
+		}
 		gs.state = StateError
 		gs.loadErr = err
+		gs.progress.CompletedAt = gs.rebuild.CompletedAt
+		gs.progress.Duration = gs.progress.CompletedAt.Sub(gs.progress.StartedAt)
 		gs.progress.State = StateError
 		gs.progress.Stage = "failed"
 		gs.progress.Error = err.Error()
 		return err
 	}
 
-	gs.g = g
-	gs.state = StateReady
-	gs.progress.State = StateReady
-	gs.progress.Stage = "complete"
-	gs.progress.CacheHit = false
+	gs.rebuild.State = "complete"
+	if !serving {
+		gs.state = StateReady
+		gs.progress.CompletedAt = gs.rebuild.CompletedAt
+		gs.progress.Duration = gs.progress.CompletedAt.Sub(gs.progress.StartedAt)
+		gs.progress.State = StateReady
+		gs.progress.Stage = "complete"
+		gs.progress.StageProgress = StageProgress{}
+		gs.progress.CacheHit = false
+	}
+	snap := gs.publish(g)
 
 	slog.Info("graph rebuild complete",
 		"nodes", g.NodeCount(),
 		"edges", g.EdgeCount(),
-		"duration", gs.progress.Duration.Round(time.Millisecond),
+		"version", snap.Version,
+		"duration", time.Duration(gs.rebuild.DurationMs)*time.Millisecond,
 	)
 
 	return nil
 }
+
+// GetRebuildStatus returns the status of the latest ForceReload.
+func (gs *GraphService) GetRebuildStatus() RebuildStatus {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+
+	status := gs.rebuild
+	if status.State == "" {
+		status.State = "idle"
+	}
+	if status.State == "running" {
+		status.DurationMs = time.Since(status.StartedAt).Milliseconds()
+	}
+	return status
+}
+
+// GetServingStatus describes the snapshot answering queries, or returns
+// nil before the first graph is loaded.
+func (gs *GraphService) GetServingStatus() *ServingStatus {
+	gs.mu.RLock()
+	defer gs.mu.RUnlock()
+	snap := gs.current.Load()
+	if snap == nil {
+		return nil
+	}
+	return &ServingStatus{
+		Version:         snap.Version,
+		Nodes:           snap.Graph.NodeCount(),
+		Edges:           snap.Graph.EdgeCount(),
+		Since:           snap.CreatedAt,
+		ComponentsReady: snap.Components != nil,
+		PageRankReady:   snap.Ranks != nil,
+		LandmarksReady:  snap.Landmarks != nil,
+		SearchReady:     snap.Titles != nil,
+	}
+}
@@ -1,12 +1,20 @@
 package api
 
 import (
+	"bytes"
 	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/graph"
 	"github.com/Thinh-nguyen-03/wikigraph/internal/scraper"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
@@ -16,7 +24,7 @@ import (
 // GET /health
 func (s *Server) handleHealth(c *gin.Context) {
 	progress := s.graphService.GetProgress()
-	nodes, edges := s.graphService.GetGraphStats()
+	nodes, edges, version := s.graphService.GetGraphStats()
 
 	// Determine status based on graph state
 	var status string
@@ -37,7 +45,7 @@ func (s *Server) handleHealth(c *gin.Context) {
 		httpStatus = http.StatusOK
 	}
 
-	c.JSON(httpStatus, HealthResponse{
+	resp := HealthResponse{
 		Status:  status,
 		Version: Version,
 		Graph: GraphStats{
@@ -45,14 +53,25 @@ func (s *Server) handleHealth(c *gin.Context) {
 			Edges: edges,
 		},
 		GraphReady:        progress.State == StateReady,
+		GraphVersion:      version,
+		Serving:           s.graphService.GetServingStatus(),
+		Rebuilding:        s.graphService.GetRebuildStatus(),
 		EmbeddingsEnabled: false, // Phase 3
-	})
+	}
+	if progress.State != StateReady {
+		resp.Loading = &progress
+	}
+	c.JSON(httpStatus, resp)
 }
 
-// requireGraphReady is a helper that returns 503 if graph is not ready.
-// Returns the graph if ready, or nil if not ready (response already sent).
-func (s *Server) requireGraphReady(c *gin.Context) bool {
-	if !s.graphService.IsReady() {
+// graphSnapshot returns the snapshot a request reads the graph from and
+// reports its version in the X-Graph-Version header. Handlers load it once
+// so the whole response comes from one version of the graph, and call
+// release once they are done with it. If the graph is not ready, it
+// responds 503 and returns false.
+func (s *Server) graphSnapshot(c *gin.Context) (snap *Snapshot, release func(), ok bool) {
+	snap, release, err := s.graphService.AcquireSnapshot()
+	if err != nil {
 		progress := s.graphService.GetProgress()
 		c.Header("Retry-After", "2")
 		c.JSON(http.StatusServiceUnavailable, gin.H{
@@ -60,9 +79,25 @@ func (s *Server) requireGraphReady(c *gin.Context) bool {
 			"message": "Graph is still loading, please retry in a few seconds",
 			"stage":   progress.Stage,
 		})
-		return false
+		return nil, nil, false
 	}
-	return true
+	c.Header("X-Graph-Version", strconv.FormatUint(snap.Version, 10))
+	return snap, release, true
+}
+
+// maxNotFoundSuggestions caps the titles a page not found error suggests.
+const maxNotFoundSuggestions = 5
+
+// respondPageNotFound writes a not found error for a page, suggesting
+// titles close to it once the snapshot's title index is ready.
+func respondPageNotFound(c *gin.Context, snap *Snapshot, title string) {
+	var suggestions []string
+	if snap != nil && snap.Titles != nil {
+		for _, m := range snap.Titles.Suggest(title, maxNotFoundSuggestions) {
+			suggestions = append(suggestions, m.Title)
+		}
+	}
+	RespondWithNotFound(c, "Page", title, suggestions...)
 }
 
 // handleGetPage returns a page and its links.
@@ -74,41 +109,118 @@ func (s *Server) handleGetPage(c *gin.Context) {
 		return
 	}
 
-	if !s.requireGraphReady(c) {
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
 		return
 	}
+	defer release()
+	g := snap.Graph
 
-	g, _ := s.graphService.GetGraph()
-	node := g.GetNode(title)
-
-	if node == nil {
-		RespondWithNotFound(c, "Page", title)
+	var redirectedFrom string
+	if canonical := snap.Canonical(title); canonical != title {
+		redirectedFrom, title = title, canonical
+	}
+	if !g.HasNode(title) {
+		respondPageNotFound(c, snap, title)
 		return
 	}
 
-	// Extract link titles
-	outLinks := make([]string, len(node.OutLinks))
-	for i, n := range node.OutLinks {
-		outLinks[i] = n.Title
+	outLinks := g.OutLinkTitles(title)
+	inLinks := g.InLinkTitles(title)
+
+	var pageRank *float64
+	if ranks := snap.Ranks; ranks != nil {
+		if score, ok := ranks.Score(title); ok {
+			pageRank = &score
+		}
 	}
 
-	inLinks := make([]string, len(node.InLinks))
-	for i, n := range node.InLinks {
-		inLinks[i] = n.Title
+	var community *int
+	if id, ok, err := s.cache.GetCommunity(title); err != nil {
+		slog.Warn("failed to look up community", "title", title, "error", err)
+	} else if ok {
+		community = &id
 	}
 
 	c.JSON(http.StatusOK, PageResponse{
-		Title:       node.Title,
-		Links:       outLinks,
-		LinkCount:   len(outLinks),
-		InLinks:     inLinks,
-		InLinkCount: len(inLinks),
-		Cached:      true,
+		Title:          title,
+		RedirectedFrom: redirectedFrom,
+		Links:          outLinks,
+		LinkCount:      len(outLinks),
+		InLinks:        inLinks,
+		InLinkCount:    len(inLinks),
+		PageRank:       pageRank,
+		Community:      community,
+		Cached:         true,
+	})
+}
+
+// handleSearch autocompletes page titles. Titles starting with q, ignoring
+// case, come first, most linked first; titles starting within a typo or
+// two of q fill the rest. Redirect titles lead to their page.
+// GET /api/v1/search?q=albert+ein&limit=10
+func (s *Server) handleSearch(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		RespondWithMissingParam(c, "q")
+		return
+	}
+	if len(query) > 256 {
+		RespondWithValidationError(c, "q", "must be at most 256 bytes")
+		return
+	}
+
+	limit := parseIntQuery(c, "limit", 10)
+	if limit < 1 || limit > 100 {
+		RespondWithValidationError(c, "limit", "must be between 1 and 100")
+		return
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+	if snap.Titles == nil {
+		c.Header("Retry-After", "5")
+		RespondWithError(c, NewAPIError("search_index_building",
+			"The title index is still being built, please retry in a few seconds", http.StatusServiceUnavailable))
+		return
+	}
+
+	start := time.Now()
+	matches := snap.Titles.Complete(query, limit)
+	results := make([]SearchMatch, len(matches))
+	for i, m := range matches {
+		results[i] = SearchMatch{Title: m.Title, Matched: m.Matched, Typos: m.Edits, InLinks: m.InLinks}
+	}
+
+	c.JSON(http.StatusOK, SearchResponse{
+		Query:      query,
+		Results:    results,
+		Count:      len(results),
+		DurationMs: time.Since(start).Milliseconds(),
 	})
 }
 
 // handleFindPath finds the shortest path between two pages.
-// GET /api/v1/path?from=X&to=Y&algorithm=bfs|bidirectional&max_depth=6
+// GET /api/v1/path?from=X&to=Y&algorithm=bfs|bidirectional|alt&max_depth=6&mode=single|all|k|dag&k=3&max_paths=100
+//
+// algorithm=alt runs an A* search guided by precomputed landmark distances;
+// it answers 503 until the landmarks are ready and takes no constraints.
+//
+// mode=all returns every shortest path (up to max_paths); mode=k returns the
+// k shortest loopless paths; mode=dag returns the union of every shortest
+// path as one graph. All three ignore algorithm.
+//
+// Constraints (mode=single only): exclude=Title and exclude_pattern=regex may
+// be repeated, via=Title may be repeated to give ordered waypoints, and
+// max_degree=N skips pages with more than N out-links.
+//
+// The search stops when the request times out or after expanding
+// max_explored nodes (capped by the server's limit). When the strongly
+// connected components show that no path can exist, the response has
+// reason "unreachable" and no search is run.
 func (s *Server) handleFindPath(c *gin.Context) {
 	from := c.Query("from")
 	to := c.Query("to")
@@ -123,8 +235,8 @@ func (s *Server) handleFindPath(c *gin.Context) {
 	}
 
 	algorithm := c.DefaultQuery("algorithm", "bfs")
-	if algorithm != "bfs" && algorithm != "bidirectional" {
-		RespondWithValidationError(c, "algorithm", "must be 'bfs' or 'bidirectional'")
+	if algorithm != "bfs" && algorithm != "bidirectional" && algorithm != "alt" {
+		RespondWithValidationError(c, "algorithm", "must be 'bfs', 'bidirectional' or 'alt'")
 		return
 	}
 
@@ -134,52 +246,748 @@ func (s *Server) handleFindPath(c *gin.Context) {
 		return
 	}
 
-	if !s.requireGraphReady(c) {
+	mode := c.DefaultQuery("mode", "single")
+	if mode != "single" && mode != "all" && mode != "k" && mode != "dag" {
+		RespondWithValidationError(c, "mode", "must be 'single', 'all', 'k' or 'dag'")
+		return
+	}
+
+	k := parseIntQuery(c, "k", 3)
+	if k < 1 || k > 20 {
+		RespondWithValidationError(c, "k", "must be between 1 and 20")
 		return
 	}
 
+	maxPaths := parseIntQuery(c, "max_paths", 100)
+	if maxPaths < 1 || maxPaths > 1000 {
+		RespondWithValidationError(c, "max_paths", "must be between 1 and 1000")
+		return
+	}
+
+	maxExplored, ok := s.parseMaxExplored(c)
+	if !ok {
+		return
+	}
+
+	opts, ok := parsePathOptions(c)
+	if !ok {
+		return
+	}
+	opts.MaxDepth = maxDepth
+	opts.MaxExplored = maxExplored
+	opts.Bidirectional = algorithm == "bidirectional"
+	constrained := opts.HasConstraints()
+	if constrained && mode != "single" {
+		RespondWithValidationError(c, "mode", "constraints are only supported with mode 'single'")
+		return
+	}
+	useALT := algorithm == "alt" && mode == "single"
+	if useALT && constrained {
+		RespondWithValidationError(c, "algorithm", "constraints are not supported with algorithm 'alt'")
+		return
+	}
+	if useALT && !s.graphService.LandmarksEnabled() {
+		RespondWithValidationError(c, "algorithm", "'alt' is disabled because no landmarks are configured")
+		return
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	var landmarks *graph.Landmarks
+	if useALT {
+		if landmarks = snap.Landmarks; landmarks == nil {
+			c.Header("Retry-After", "10")
+			RespondWithError(c, NewAPIError("landmarks_computing",
+				"Landmarks are still being computed, please retry later", http.StatusServiceUnavailable))
+			return
+		}
+	}
+
 	start := time.Now()
+	ctx := c.Request.Context()
+
+	g := snap.Graph
 
-	g, _ := s.graphService.GetGraph()
+	var redirects redirectLog
+	from = redirects.resolve(snap, from)
+	to = redirects.resolve(snap, to)
+	for i, title := range opts.Waypoints {
+		opts.Waypoints[i] = redirects.resolve(snap, title)
+	}
+	for i, title := range opts.Exclude {
+		opts.Exclude[i] = redirects.resolve(snap, title)
+	}
 
-	var result struct {
-		Found    bool
-		Path     []string
-		Hops     int
-		Explored int
+	// Pages in components the condensation orders the wrong way round can
+	// never be connected, so answer without searching.
+	if sccs := snap.Components; sccs != nil &&
+		g.HasNode(from) && g.HasNode(to) && !sccs.MayReach(from, to) {
+		c.JSON(http.StatusOK, PathResponse{
+			From:       from,
+			To:         to,
+			Algorithm:  algorithm,
+			Mode:       mode,
+			Reason:     "unreachable",
+			Redirects:  redirects,
+			DurationMs: time.Since(start).Milliseconds(),
+		})
+		return
 	}
 
-	switch algorithm {
-	case "bidirectional":
-		r := g.FindPathBidirectional(from, to)
-		result.Found = r.Found
-		result.Path = r.Path
-		result.Hops = r.Hops
-		result.Explored = r.Explored
+	var result graph.PathResult
+	var paths [][]string
+	var dag *PathDAG
+
+	switch {
+	case mode != "single":
+		var r graph.MultiPathResult
+		switch mode {
+		case "all":
+			algorithm = "bfs"
+			r = graph.AllShortestPaths(ctx, g.Adjacency(), from, to, maxDepth, maxPaths, maxExplored)
+		case "dag":
+			algorithm = "bfs"
+			r = graph.ShortestPathDAG(ctx, g.Adjacency(), from, to, maxDepth, maxExplored)
+		default:
+			algorithm = "yen"
+			r = graph.KShortestPaths(ctx, g.Adjacency(), from, to, k, maxDepth, maxExplored)
+		}
+		result = graph.PathResult{
+			Found:           r.Found,
+			Hops:            r.Hops,
+			Explored:        r.Explored,
+			Truncated:       r.Truncated,
+			BudgetExhausted: r.BudgetExhausted,
+		}
+		if len(r.Paths) > 0 {
+			result.Path = r.Paths[0]
+		}
+		paths = r.Paths
+		if r.DAG != nil {
+			dag = newPathDAG(r.DAG)
+		}
+	case constrained:
+		result = graph.FindPathWithOptions(ctx, g.Adjacency(), from, to, opts)
+	case useALT:
+		result = landmarks.FindPath(ctx, from, to, maxDepth, maxExplored)
+	case algorithm == "bidirectional":
+		result = g.FindPathBidirectionalContext(ctx, from, to, -1, maxExplored)
 	default:
-		r := g.FindPathWithLimit(from, to, maxDepth)
-		result.Found = r.Found
-		result.Path = r.Path
-		result.Hops = r.Hops
-		result.Explored = r.Explored
+		result = g.FindPathContext(ctx, from, to, maxDepth, maxExplored)
 	}
 
 	duration := time.Since(start)
 
 	c.JSON(http.StatusOK, PathResponse{
-		Found:      result.Found,
-		From:       from,
-		To:         to,
-		Path:       result.Path,
-		Hops:       result.Hops,
-		Explored:   result.Explored,
-		Algorithm:  algorithm,
-		DurationMs: duration.Milliseconds(),
+		Found:           result.Found,
+		From:            from,
+		To:              to,
+		Path:            result.Path,
+		Paths:           paths,
+		DAG:             dag,
+		Hops:            result.Hops,
+		Explored:        result.Explored,
+		Algorithm:       algorithm,
+		Mode:            mode,
+		Truncated:       result.Truncated,
+		BudgetExhausted: result.BudgetExhausted,
+		Redirects:       redirects,
+		DurationMs:      duration.Milliseconds(),
+	})
+}
+
+// newPathDAG converts a shortest-path DAG to its response format.
+func newPathDAG(s *graph.Subgraph) *PathDAG {
+	dag := &PathDAG{
+		Nodes: make([]GraphNode, len(s.Nodes)),
+		Edges: make([]GraphEdge, len(s.Edges)),
+	}
+	for i, n := range s.Nodes {
+		dag.Nodes[i] = GraphNode{
+			ID:        n.Title,
+			Title:     n.Title,
+			Hops:      n.Hops,
+			InDegree:  n.InDegree,
+			OutDegree: n.OutDegree,
+		}
+	}
+	for i, e := range s.Edges {
+		dag.Edges[i] = GraphEdge{Source: e.Source, Target: e.Target}
+	}
+	return dag
+}
+
+// handleDistance bounds the hop count between two pages from the landmark
+// distance tables, without searching.
+// GET /api/v1/distance?from=X&to=Y&exact=false
+//
+// With exact=true, a bidirectional BFS settles the distance whenever the
+// bounds differ. Without landmarks, exact=true searches and exact=false
+// answers 503 until they are ready.
+func (s *Server) handleDistance(c *gin.Context) {
+	from := c.Query("from")
+	to := c.Query("to")
+
+	if from == "" {
+		RespondWithMissingParam(c, "from")
+		return
+	}
+	if to == "" {
+		RespondWithMissingParam(c, "to")
+		return
+	}
+
+	exact := false
+	if v := c.Query("exact"); v != "" {
+		var err error
+		if exact, err = strconv.ParseBool(v); err != nil {
+			RespondWithValidationError(c, "exact", "must be true or false")
+			return
+		}
+	}
+
+	maxExplored, ok := s.parseMaxExplored(c)
+	if !ok {
+		return
+	}
+
+	if !exact && !s.graphService.LandmarksEnabled() {
+		RespondWithValidationError(c, "exact", "must be true because no landmarks are configured")
+		return
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	start := time.Now()
+	g := snap.Graph
+
+	var redirects redirectLog
+	from = redirects.resolve(snap, from)
+	to = redirects.resolve(snap, to)
+
+	for _, title := range []string{from, to} {
+		if !g.HasNode(title) {
+			respondPageNotFound(c, snap, title)
+			return
+		}
+	}
+
+	resp := DistanceResponse{From: from, To: to, Redirects: redirects}
+	setBounds := func(lower, upper int) {
+		resp.Lower, resp.Upper = nil, nil
+		if lower >= 0 {
+			resp.Lower = &lower
+		}
+		if upper >= 0 {
+			resp.Upper = &upper
+		}
+		resp.Exact = lower >= 0 && lower == upper
+	}
+
+	sccs := snap.Components
+	landmarks := snap.Landmarks
+	switch {
+	case sccs != nil && !sccs.MayReach(from, to):
+		resp.Unreachable, resp.Exact = true, true
+	case landmarks != nil:
+		est, _ := landmarks.Estimate(from, to)
+		if est.Unreachable {
+			resp.Unreachable, resp.Exact = true, true
+		} else {
+			setBounds(est.Lower, est.Upper)
+		}
+	case !exact:
+		c.Header("Retry-After", "10")
+		RespondWithError(c, NewAPIError("landmarks_computing",
+			"Landmarks are still being computed, please retry later", http.StatusServiceUnavailable))
+		return
+	}
+
+	if exact && !resp.Exact {
+		result := g.FindPathBidirectionalContext(c.Request.Context(), from, to, -1, maxExplored)
+		resp.Searched = true
+		resp.Explored = result.Explored
+		resp.Truncated = result.Truncated
+		resp.BudgetExhausted = result.BudgetExhausted
+		switch {
+		case result.Found:
+			setBounds(result.Hops, result.Hops)
+		case !result.Truncated:
+			resp.Unreachable, resp.Exact = true, true
+		}
+	}
+
+	resp.DurationMs = time.Since(start).Milliseconds()
+	c.JSON(http.StatusOK, resp)
+}
+
+// redirectLog records the redirect titles a request named, and titles it
+// gave in another form, with the pages they were resolved to.
+type redirectLog map[string]string
+
+// resolve returns the canonical title of a page, recording the redirect
+// if title is one.
+func (r *redirectLog) resolve(snap *Snapshot, title string) string {
+	canonical := snap.Canonical(title)
+	if canonical != title {
+		if *r == nil {
+			*r = make(redirectLog)
+		}
+		(*r)[title] = canonical
+	}
+	return canonical
+}
+
+// maxBatchPairs caps the number of pairs one batch path request may ask for.
+const maxBatchPairs = 10_000
+
+// handleFindPaths answers many path queries at once. Pairs are grouped by
+// source so that one search from each distinct source answers all of its
+// targets.
+// POST /api/v1/paths?format=json
+func (s *Server) handleFindPaths(c *gin.Context) {
+	var req PathsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		RespondWithError(c, NewAPIError("invalid_request", err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	format := c.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		RespondWithValidationError(c, "format", "must be 'json' or 'csv'")
+		return
+	}
+	if req.Output == "" {
+		req.Output = "paths"
+	}
+	if req.Output != "paths" && req.Output != "matrix" {
+		RespondWithValidationError(c, "output", "must be 'paths' or 'matrix'")
+		return
+	}
+	if req.MaxDepth == 0 {
+		req.MaxDepth = 6
+	}
+	if req.MaxDepth < 1 || req.MaxDepth > 20 {
+		RespondWithValidationError(c, "max_depth", "must be between 1 and 20")
+		return
+	}
+	if req.MaxExplored == 0 {
+		req.MaxExplored = s.config.MaxExplored
+	}
+	maxExplored, ok := s.checkMaxExplored(c, req.MaxExplored)
+	if !ok {
+		return
+	}
+
+	pairs := make([]graph.PathPair, 0, len(req.Pairs))
+	for _, p := range req.Pairs {
+		pairs = append(pairs, graph.PathPair{From: p.From, To: p.To})
+	}
+	if len(req.Sources) > 0 || len(req.Targets) > 0 {
+		if len(req.Pairs) > 0 {
+			RespondWithValidationError(c, "pairs", "cannot be combined with sources and targets")
+			return
+		}
+		if len(req.Sources)*len(req.Targets) > maxBatchPairs {
+			RespondWithValidationError(c, "sources", fmt.Sprintf("sources times targets must not exceed %d", maxBatchPairs))
+			return
+		}
+		for _, from := range req.Sources {
+			for _, to := range req.Targets {
+				pairs = append(pairs, graph.PathPair{From: from, To: to})
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		RespondWithValidationError(c, "pairs", "must list at least one pair, or sources and targets")
+		return
+	}
+	if len(pairs) > maxBatchPairs {
+		RespondWithValidationError(c, "pairs", fmt.Sprintf("at most %d pairs are allowed", maxBatchPairs))
+		return
+	}
+	for _, p := range pairs {
+		if p.From == "" || p.To == "" {
+			RespondWithValidationError(c, "pairs", "every pair needs a from and a to page")
+			return
+		}
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	start := time.Now()
+	g := snap.Graph
+
+	// Pairs with a missing page, or in components the condensation orders
+	// the wrong way round, are answered without searching.
+	var redirects redirectLog
+	results := make([]PairResult, len(pairs))
+	var search []graph.PathPair
+	var searchIndex []int
+	for i, p := range pairs {
+		p = graph.PathPair{From: redirects.resolve(snap, p.From), To: redirects.resolve(snap, p.To)}
+		pairs[i] = p
+		results[i] = PairResult{From: p.From, To: p.To}
+		switch {
+		case !g.HasNode(p.From) || !g.HasNode(p.To):
+			results[i].Reason = "page_not_found"
+		case snap.Components != nil && !snap.Components.MayReach(p.From, p.To):
+			results[i].Reason = "unreachable"
+		default:
+			search = append(search, p)
+			searchIndex = append(searchIndex, i)
+		}
+	}
+
+	resp := PathsResponse{Pairs: len(pairs), Redirects: redirects}
+	found := make([]graph.PathResult, len(pairs))
+	explored := make(map[string]int)
+	for j, r := range graph.BatchPaths(c.Request.Context(), g.Adjacency(), search, req.MaxDepth, maxExplored) {
+		i := searchIndex[j]
+		found[i] = r
+		results[i].Found = r.Found
+		results[i].Path = r.Path
+		results[i].Hops = r.Hops
+		results[i].Truncated = r.Truncated
+		results[i].BudgetExhausted = r.BudgetExhausted
+		explored[pairs[i].From] = r.Explored
+		if r.Found {
+			resp.Found++
+		}
+		resp.Truncated = resp.Truncated || r.Truncated
+	}
+	resp.Searches = len(explored)
+	for _, n := range explored {
+		resp.Explored += n
+	}
+
+	if req.Output == "matrix" {
+		m := graph.NewDistanceMatrix(pairs, found)
+		resp.Sources, resp.Targets = m.Sources, m.Targets
+		resp.Distances = make([][]*int, len(m.Hops))
+		for i, row := range m.Hops {
+			resp.Distances[i] = make([]*int, len(row))
+			for j, hops := range row {
+				if hops >= 0 {
+					resp.Distances[i][j] = &row[j]
+				}
+			}
+		}
+	} else {
+		resp.Results = results
+	}
+	resp.DurationMs = time.Since(start).Milliseconds()
+
+	if format == "csv" {
+		respondPathsCSV(c, resp)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// respondPathsCSV writes a batch path response as CSV: a from,to,found,
+// hops,path,reason row per pair, or for a matrix a header row of targets
+// and one row of hop counts per source. Path steps are separated by "|",
+// which cannot occur in page titles, and an empty cell means no path.
+func respondPathsCSV(c *gin.Context, resp PathsResponse) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if resp.Distances != nil {
+		_ = w.Write(append([]string{"source"}, resp.Targets...))
+		for i, row := range resp.Distances {
+			record := []string{resp.Sources[i]}
+			for _, hops := range row {
+				cell := ""
+				if hops != nil {
+					cell = strconv.Itoa(*hops)
+				}
+				record = append(record, cell)
+			}
+			_ = w.Write(record)
+		}
+	} else {
+		_ = w.Write([]string{"from", "to", "found", "hops", "path", "reason"})
+		for _, r := range resp.Results {
+			hops := ""
+			if r.Found {
+				hops = strconv.Itoa(r.Hops)
+			}
+			_ = w.Write([]string{r.From, r.To, strconv.FormatBool(r.Found), hops, strings.Join(r.Path, "|"), r.Reason})
+		}
+	}
+	w.Flush()
+
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// handleRank returns the highest-ranked pages by PageRank.
+// GET /api/v1/rank?n=20&seed=X
+//
+// seed may be repeated (up to 10) for a personalized ranking, which is
+// computed per request; the global ranking is computed once per graph load.
+func (s *Server) handleRank(c *gin.Context) {
+	n := parseIntQuery(c, "n", 20)
+	if n < 1 || n > 1000 {
+		RespondWithValidationError(c, "n", "must be between 1 and 1000")
+		return
+	}
+
+	seeds := c.QueryArray("seed")
+	if len(seeds) > 10 {
+		RespondWithValidationError(c, "seed", "at most 10 seeds are allowed")
+		return
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	start := time.Now()
+
+	var ranks *graph.Ranks
+	if len(seeds) == 0 {
+		ranks = snap.Ranks
+		if ranks == nil {
+			c.Header("Retry-After", "10")
+			RespondWithError(c, NewAPIError("ranks_computing",
+				"PageRank is still being computed, please retry later", http.StatusServiceUnavailable))
+			return
+		}
+	} else {
+		for i, seed := range seeds {
+			seeds[i] = snap.Canonical(seed)
+		}
+		var err error
+		ranks, err = graph.PersonalizedPageRank(c.Request.Context(), snap.Graph.Adjacency(), seeds, s.graphService.PageRankOptions())
+		switch {
+		case errors.Is(err, graph.ErrPageNotFound):
+			RespondWithError(c, NewAPIError("not_found", err.Error(), http.StatusNotFound))
+			return
+		case c.Request.Context().Err() != nil:
+			RespondWithError(c, NewAPIError("timeout", "PageRank computation timed out", http.StatusServiceUnavailable))
+			return
+		case err != nil:
+			RespondWithError(c, NewAPIError("invalid_configuration", err.Error(), http.StatusInternalServerError))
+			return
+		}
+	}
+
+	pages := newRankedPages(ranks.Top(n))
+
+	c.JSON(http.StatusOK, RankResponse{
+		Seeds:      ranks.Seeds,
+		Pages:      pages,
+		Count:      len(pages),
+		Damping:    ranks.Options.Damping,
+		Iterations: ranks.Iterations,
+		Converged:  ranks.Converged,
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+}
+
+// handleGraphStatistics reports the shape of the graph.
+// GET /api/v1/graph/stats?samples=100&wedges=100000&top=10
+//
+// The report is computed on the first request and kept until the graph
+// changes or different parameters are asked for.
+func (s *Server) handleGraphStatistics(c *gin.Context) {
+	samples := parseIntQuery(c, "samples", 100)
+	if samples < 1 || samples > 10000 {
+		RespondWithValidationError(c, "samples", "must be between 1 and 10000")
+		return
+	}
+
+	wedges := parseIntQuery(c, "wedges", 100000)
+	if wedges < 1 || wedges > 10000000 {
+		RespondWithValidationError(c, "wedges", "must be between 1 and 10000000")
+		return
+	}
+
+	top := parseIntQuery(c, "top", 10)
+	if top < 1 || top > 100 {
+		RespondWithValidationError(c, "top", "must be between 1 and 100")
+		return
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	start := time.Now()
+	stats, err := s.graphService.GetStatistics(c.Request.Context(), snap, graph.StatisticsOptions{
+		PathSamples:       samples,
+		ClusteringSamples: wedges,
+		Top:               top,
+	})
+	switch {
+	case c.Request.Context().Err() != nil:
+		RespondWithError(c, NewAPIError("timeout", "Graph statistics computation timed out", http.StatusServiceUnavailable))
+		return
+	case err != nil:
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	unfetched, err := s.cache.CountUnfetchedTargets()
+	if err != nil {
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, GraphStatisticsResponse{
+		Nodes:             stats.Nodes,
+		Edges:             stats.Edges,
+		InDegree:          newDegreeDistribution(stats.InDegree),
+		OutDegree:         newDegreeDistribution(stats.OutDegree),
+		Hubs:              newRankedPages(stats.Hubs),
+		Authorities:       newRankedPages(stats.Authorities),
+		Reciprocity:       stats.Reciprocity,
+		Clustering:        stats.Clustering,
+		ClusteringExact:   stats.ClusteringExact,
+		Dangling:          stats.Dangling,
+		UnfetchedTargets:  unfetched,
+		Orphans:           stats.Orphans,
+		Diameter:          stats.Diameter,
+		AveragePathLength: stats.AveragePathLength,
+		Reachability:      stats.Reachability,
+		PathSamples:       stats.PathSamples,
+		DurationMs:        time.Since(start).Milliseconds(),
 	})
 }
 
+func newDegreeDistribution(d graph.DegreeDistribution) DegreeDistribution {
+	out := DegreeDistribution{
+		Mean:        d.Mean,
+		Max:         d.Max,
+		Percentiles: make([]DegreePercentile, len(d.Percentiles)),
+		Histogram:   make([]DegreeBucket, len(d.Histogram)),
+	}
+	for i, p := range d.Percentiles {
+		out.Percentiles[i] = DegreePercentile{Percentile: p.Percentile, Degree: p.Degree}
+	}
+	for i, b := range d.Histogram {
+		out.Histogram[i] = DegreeBucket{Min: b.Min, Max: b.Max, Count: b.Count}
+	}
+	return out
+}
+
+func newRankedPages(top []graph.RankedPage) []RankedPage {
+	pages := make([]RankedPage, len(top))
+	for i, p := range top {
+		pages[i] = RankedPage{Rank: i + 1, Title: p.Title, Score: p.Score}
+	}
+	return pages
+}
+
+// handleBetweenness returns the pages with the highest betweenness centrality.
+// GET /api/v1/betweenness?k=20
+//
+// It only reads the latest computation started by handleStartBetweenness:
+// while it runs the endpoint responds 202 with its progress, and once it is
+// complete for the current graph, 200 with the top k pages.
+func (s *Server) handleBetweenness(c *gin.Context) {
+	k := parseIntQuery(c, "k", 20)
+	if k < 1 || k > 1000 {
+		RespondWithValidationError(c, "k", "must be between 1 and 1000")
+		return
+	}
+
+	_, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	result, status := s.graphService.GetBetweenness()
+	if result == nil {
+		if status.State == "running" {
+			c.JSON(http.StatusAccepted, BetweennessResponse{Status: status})
+			return
+		}
+		RespondWithError(c, NewAPIError("not_computed",
+			fmt.Sprintf("No betweenness result for the current graph (last computation: %s); POST /api/v1/betweenness to start one", status.State),
+			http.StatusNotFound))
+		return
+	}
+
+	pages := newRankedPages(result.Top(k))
+
+	c.JSON(http.StatusOK, BetweennessResponse{
+		Status:  status,
+		Pages:   pages,
+		Count:   len(pages),
+		Samples: result.Samples,
+		Exact:   result.Exact,
+	})
+}
+
+// handleStartBetweenness starts estimating betweenness centrality in the
+// background from samples source pivots.
+// POST /api/v1/betweenness?samples=1000
+//
+// A computation with the same settings that is running or complete for the
+// current graph is kept; any other is canceled first. It responds 202 with
+// the progress, or 200 if the result is already complete; GET the same path
+// for the top pages.
+func (s *Server) handleStartBetweenness(c *gin.Context) {
+	samples := parseIntQuery(c, "samples", 1000)
+	if samples < 1 || samples > 100000 {
+		RespondWithValidationError(c, "samples", "must be between 1 and 100000")
+		return
+	}
+
+	_, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+
+	s.graphService.StartBetweenness(samples)
+	result, status := s.graphService.GetBetweenness()
+	code := http.StatusAccepted
+	if result != nil && status.State == "complete" {
+		code = http.StatusOK
+	}
+	c.JSON(code, BetweennessResponse{Status: status})
+}
+
+// handleCancelBetweenness stops a running betweenness computation.
+// DELETE /api/v1/betweenness
+func (s *Server) handleCancelBetweenness(c *gin.Context) {
+	if !s.graphService.CancelBetweenness() {
+		RespondWithError(c, NewAPIError("not_running", "No betweenness computation is running", http.StatusConflict))
+		return
+	}
+
+	_, status := s.graphService.GetBetweenness()
+	c.JSON(http.StatusOK, BetweennessResponse{Status: status})
+}
+
 // handleGetConnections returns the N-hop neighborhood of a page.
-// GET /api/v1/connections/:title?depth=2&max_nodes=1000
+// GET /api/v1/connections/:title?depth=2&max_nodes=1000&max_explored=N&color=community
+//
+//	&direction=out|in|both&fanout=50,10&min_in_degree=N
+//
+// direction picks the links followed, fanout caps the pages taken from each
+// page per hop and min_in_degree skips sparsely linked pages. color=community
+// tags every node with its community and a display colour.
 func (s *Server) handleGetConnections(c *gin.Context) {
 	title := c.Param("title")
 	if title == "" {
@@ -199,21 +1007,44 @@ func (s *Server) handleGetConnections(c *gin.Context) {
 		return
 	}
 
-	if !s.requireGraphReady(c) {
+	maxExplored, ok := s.parseMaxExplored(c)
+	if !ok {
 		return
 	}
 
-	g, _ := s.graphService.GetGraph()
+	opts, ok := parseNeighborhoodOptions(c)
+	if !ok {
+		return
+	}
+	opts.MaxDepth = depth
+	opts.MaxNodes = maxNodes
+	opts.MaxExplored = maxExplored
 
-	node := g.GetNode(title)
-	if node == nil {
-		RespondWithNotFound(c, "Page", title)
+	color := c.Query("color")
+	if color != "" && color != "community" {
+		RespondWithValidationError(c, "color", "must be 'community'")
 		return
 	}
 
-	subgraph := g.GetNeighborhood(title, depth, maxNodes)
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+	g := snap.Graph
+
+	var redirectedFrom string
+	if canonical := snap.Canonical(title); canonical != title {
+		redirectedFrom, title = title, canonical
+	}
+	if !g.HasNode(title) {
+		respondPageNotFound(c, snap, title)
+		return
+	}
+
+	subgraph := g.GetNeighborhoodWithOptions(c.Request.Context(), title, opts)
 	if subgraph == nil {
-		RespondWithNotFound(c, "Page", title)
+		respondPageNotFound(c, snap, title)
 		return
 	}
 
@@ -221,9 +1052,29 @@ func (s *Server) handleGetConnections(c *gin.Context) {
 	nodes := make([]GraphNode, len(subgraph.Nodes))
 	for i, n := range subgraph.Nodes {
 		nodes[i] = GraphNode{
-			ID:    n.Title,
-			Title: n.Title,
-			Hops:  n.Hops,
+			ID:        n.Title,
+			Title:     n.Title,
+			Hops:      n.Hops,
+			InDegree:  n.InDegree,
+			OutDegree: n.OutDegree,
+		}
+	}
+
+	if color == "community" {
+		titles := make([]string, len(nodes))
+		for i, n := range nodes {
+			titles[i] = n.Title
+		}
+		communities, err := s.cache.GetCommunities(titles)
+		if err != nil {
+			RespondWithError(c, ErrInternal)
+			return
+		}
+		for i := range nodes {
+			if id, ok := communities[nodes[i].Title]; ok {
+				nodes[i].Community = &id
+				nodes[i].Color = communityColor(id)
+			}
 		}
 	}
 
@@ -236,13 +1087,357 @@ func (s *Server) handleGetConnections(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, ConnectionsResponse{
-		Center:    title,
-		Depth:     depth,
-		Nodes:     nodes,
-		Edges:     edges,
-		NodeCount: len(nodes),
-		EdgeCount: len(edges),
+		Center:          title,
+		RedirectedFrom:  redirectedFrom,
+		Depth:           depth,
+		Direction:       string(opts.Direction),
+		Nodes:           nodes,
+		Edges:           edges,
+		NodeCount:       len(nodes),
+		EdgeCount:       len(edges),
+		Truncated:       subgraph.Truncated,
+		BudgetExhausted: subgraph.BudgetExhausted,
+	})
+}
+
+// communityPalette colours communities in connections responses.
+var communityPalette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b",
+	"#e377c2", "#7f7f7f", "#bcbd22", "#17becf", "#aec7e8", "#ffbb78",
+}
+
+// communityColor returns the display colour of a community.
+func communityColor(id int) string {
+	return communityPalette[id%len(communityPalette)]
+}
+
+// handleListCommunities returns the largest detected communities.
+// GET /api/v1/communities?limit=20&offset=0&sample=5
+func (s *Server) handleListCommunities(c *gin.Context) {
+	limit := parseIntQuery(c, "limit", 20)
+	if limit < 1 || limit > 1000 {
+		RespondWithValidationError(c, "limit", "must be between 1 and 1000")
+		return
+	}
+
+	offset := parseIntQuery(c, "offset", 0)
+	if offset < 0 {
+		RespondWithValidationError(c, "offset", "must not be negative")
+		return
+	}
+
+	sample := parseIntQuery(c, "sample", 5)
+	if sample < 0 || sample > 100 {
+		RespondWithValidationError(c, "sample", "must be between 0 and 100")
+		return
+	}
+
+	total, err := s.cache.CountCommunities()
+	if err != nil {
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	summaries, err := s.cache.ListCommunities(limit, offset)
+	if err != nil {
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	communities := make([]CommunitySummary, len(summaries))
+	for i, summary := range summaries {
+		communities[i] = CommunitySummary{ID: summary.ID, Size: summary.Size, Sample: []string{}}
+		if sample > 0 {
+			members, err := s.cache.GetCommunityMembers(summary.ID, sample, 0)
+			if err != nil {
+				RespondWithError(c, ErrInternal)
+				return
+			}
+			communities[i].Sample = members
+		}
+	}
+
+	c.JSON(http.StatusOK, CommunitiesResponse{
+		Communities: communities,
+		Count:       len(communities),
+		Total:       total,
+	})
+}
+
+// handleGetCommunity returns the pages of one community.
+// GET /api/v1/communities/:id?limit=100&offset=0
+func (s *Server) handleGetCommunity(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id < 0 {
+		RespondWithValidationError(c, "id", "must be a non-negative integer")
+		return
+	}
+
+	limit := parseIntQuery(c, "limit", 100)
+	if limit < 1 || limit > 10000 {
+		RespondWithValidationError(c, "limit", "must be between 1 and 10000")
+		return
+	}
+
+	offset := parseIntQuery(c, "offset", 0)
+	if offset < 0 {
+		RespondWithValidationError(c, "offset", "must not be negative")
+		return
+	}
+
+	size, ok, err := s.cache.GetCommunitySize(id)
+	if err != nil {
+		RespondWithError(c, ErrInternal)
+		return
+	}
+	if !ok {
+		RespondWithNotFound(c, "Community", c.Param("id"))
+		return
+	}
+
+	members, err := s.cache.GetCommunityMembers(id, limit, offset)
+	if err != nil {
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	c.JSON(http.StatusOK, CommunityResponse{
+		ID:      id,
+		Size:    size,
+		Members: members,
+		Count:   len(members),
+	})
+}
+
+// handleSimilar returns the pages sharing the most links with a page.
+// GET /api/v1/similar/:title?method=jaccard&limit=10&threshold=0
+//
+// method is jaccard, cosine, adamic_adar (shared in- and out-links),
+// cocitation (shared in-links) or coupling (shared out-links).
+func (s *Server) handleSimilar(c *gin.Context) {
+	title := c.Param("title")
+	if title == "" {
+		RespondWithMissingParam(c, "title")
+		return
+	}
+
+	method, err := graph.ParseSimilarityMethod(c.Query("method"))
+	if err != nil {
+		RespondWithValidationError(c, "method", "must be jaccard, cosine, cocitation, coupling or adamic_adar")
+		return
+	}
+
+	limit := parseIntQuery(c, "limit", 10)
+	if limit < 1 || limit > 100 {
+		RespondWithValidationError(c, "limit", "must be between 1 and 100")
+		return
+	}
+
+	threshold := 0.0
+	if v := c.Query("threshold"); v != "" {
+		if threshold, err = strconv.ParseFloat(v, 64); err != nil || threshold < 0 {
+			RespondWithValidationError(c, "threshold", "must be a non-negative number")
+			return
+		}
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+	g := snap.Graph
+
+	var redirectedFrom string
+	if canonical := snap.Canonical(title); canonical != title {
+		redirectedFrom, title = title, canonical
+	}
+
+	start := time.Now()
+	pages, err := graph.Similar(c.Request.Context(), g.Adjacency(), title, graph.SimilarityOptions{
+		Method:    method,
+		Limit:     limit,
+		Threshold: threshold,
+	})
+	switch {
+	case errors.Is(err, graph.ErrPageNotFound):
+		respondPageNotFound(c, snap, title)
+		return
+	case c.Request.Context().Err() != nil:
+		RespondWithError(c, NewAPIError("timeout", "Similarity computation timed out", http.StatusServiceUnavailable))
+		return
+	case err != nil:
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	similar := make([]SimilarPage, len(pages))
+	for i, p := range pages {
+		similar[i] = SimilarPage{Title: p.Title, Score: p.Score}
+	}
+
+	c.JSON(http.StatusOK, SimilarResponse{
+		Query:          title,
+		RedirectedFrom: redirectedFrom,
+		Method:         string(method),
+		Similar:        similar,
+		Count:          len(similar),
+		Threshold:      threshold,
+		DurationMs:     time.Since(start).Milliseconds(),
+	})
+}
+
+// handleSuggestLinks returns pages a page probably should link to but
+// does not, scored by the neighbours they share.
+// GET /api/v1/suggest-links/:title?method=adamic_adar&limit=10&shared=10
+//
+// method is common_neighbors, adamic_adar or resource_allocation.
+func (s *Server) handleSuggestLinks(c *gin.Context) {
+	title := c.Param("title")
+	if title == "" {
+		RespondWithMissingParam(c, "title")
+		return
+	}
+
+	scorer, err := graph.ParseLinkScorer(c.Query("method"))
+	if err != nil {
+		RespondWithValidationError(c, "method", "must be common_neighbors, adamic_adar or resource_allocation")
+		return
+	}
+
+	limit := parseIntQuery(c, "limit", 10)
+	if limit < 1 || limit > 100 {
+		RespondWithValidationError(c, "limit", "must be between 1 and 100")
+		return
+	}
+
+	shared := parseIntQuery(c, "shared", 10)
+	if shared < 1 || shared > 100 {
+		RespondWithValidationError(c, "shared", "must be between 1 and 100")
+		return
+	}
+
+	snap, release, ok := s.graphSnapshot(c)
+	if !ok {
+		return
+	}
+	defer release()
+	g := snap.Graph
+
+	var redirectedFrom string
+	if canonical := snap.Canonical(title); canonical != title {
+		redirectedFrom, title = title, canonical
+	}
+
+	start := time.Now()
+	found, err := graph.SuggestLinks(c.Request.Context(), g.Adjacency(), title, graph.LinkPredictionOptions{
+		Scorer:    scorer,
+		Limit:     limit,
+		MaxShared: shared,
+	})
+	switch {
+	case errors.Is(err, graph.ErrPageNotFound):
+		respondPageNotFound(c, snap, title)
+		return
+	case c.Request.Context().Err() != nil:
+		RespondWithError(c, NewAPIError("timeout", "Link suggestion timed out", http.StatusServiceUnavailable))
+		return
+	case err != nil:
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	suggestions := make([]LinkSuggestion, len(found))
+	for i, sg := range found {
+		suggestions[i] = LinkSuggestion{
+			Title:       sg.Title,
+			Score:       sg.Score,
+			Shared:      sg.Shared,
+			SharedCount: sg.SharedCount,
+		}
+	}
+
+	c.JSON(http.StatusOK, SuggestLinksResponse{
+		Title:          title,
+		RedirectedFrom: redirectedFrom,
+		Method:         string(scorer),
+		Suggestions:    suggestions,
+		Count:          len(suggestions),
+		DurationMs:     time.Since(start).Milliseconds(),
+	})
+}
+
+// handleFirstLink follows the first link of each page from a start page
+// until the chain loops, as in "Getting to Philosophy". Link order is read
+// from the database, so this works while the graph is loading.
+// GET /api/v1/first-link/:title?max_steps=100
+func (s *Server) handleFirstLink(c *gin.Context) {
+	title := c.Param("title")
+	if title == "" {
+		RespondWithMissingParam(c, "title")
+		return
+	}
+
+	maxSteps := parseIntQuery(c, "max_steps", 100)
+	if maxSteps < 1 || maxSteps > 1000 {
+		RespondWithValidationError(c, "max_steps", "must be between 1 and 1000")
+		return
+	}
+
+	start := time.Now()
+
+	resolved, err := s.cache.ResolveRedirect(title)
+	if err != nil {
+		RespondWithError(c, ErrInternal)
+		return
+	}
+	page, err := s.cache.GetPage(resolved)
+	if err != nil {
+		RespondWithError(c, ErrInternal)
+		return
+	}
+	if page == nil {
+		snap, release, err := s.graphService.AcquireSnapshot()
+		if err == nil {
+			defer release()
+		}
+		respondPageNotFound(c, snap, resolved)
+		return
+	}
+
+	var last cache.FirstLinkStatus
+	chain, err := graph.FollowChain(resolved, maxSteps, func(title string) (string, bool, error) {
+		next, status, err := s.cache.GetFirstLink(title)
+		last = status
+		return next, status == cache.FirstLinkFound, err
 	})
+	if err != nil {
+		slog.Error("following first links", "title", resolved, "error", err)
+		RespondWithError(c, ErrInternal)
+		return
+	}
+
+	resp := FirstLinkResponse{
+		Start:       resolved,
+		Path:        chain.Path,
+		Steps:       chain.Steps(),
+		Cycle:       chain.Cycle(),
+		ConvergesTo: chain.ConvergesTo(),
+	}
+	if resolved != title {
+		resp.RedirectedFrom = title
+	}
+	switch {
+	case chain.CycleAt >= 0:
+		resp.End = "cycle"
+	case chain.Truncated:
+		resp.End = "max_steps"
+	default:
+		resp.End = string(last)
+	}
+	resp.DurationMs = time.Since(start).Milliseconds()
+
+	c.JSON(http.StatusOK, resp)
 }
 
 // handleCrawl starts a background crawl job.
@@ -315,6 +1510,98 @@ func (s *Server) handleCrawl(c *gin.Context) {
 	})
 }
 
+// parseMaxExplored reads the max_explored query parameter, which may lower
+// but not raise the server's per-search node budget.
+// Returns false if the parameter is invalid (response already sent).
+func (s *Server) parseMaxExplored(c *gin.Context) (int, bool) {
+	return s.checkMaxExplored(c, parseIntQuery(c, "max_explored", s.config.MaxExplored))
+}
+
+// checkMaxExplored validates a requested per-search node budget against
+// the server's. Returns false if it is invalid (response already sent).
+func (s *Server) checkMaxExplored(c *gin.Context, maxExplored int) (int, bool) {
+	limit := s.config.MaxExplored
+	if limit <= 0 {
+		if maxExplored < 0 {
+			RespondWithValidationError(c, "max_explored", "must not be negative")
+			return 0, false
+		}
+		return maxExplored, true
+	}
+	if maxExplored < 1 || maxExplored > limit {
+		RespondWithValidationError(c, "max_explored", fmt.Sprintf("must be between 1 and %d", limit))
+		return 0, false
+	}
+	return maxExplored, true
+}
+
+// parsePathOptions reads the path constraint query parameters.
+// Returns false if a parameter is invalid (response already sent).
+func parsePathOptions(c *gin.Context) (graph.PathOptions, bool) {
+	opts := graph.PathOptions{
+		Exclude:   c.QueryArray("exclude"),
+		Waypoints: c.QueryArray("via"),
+		MaxDegree: parseIntQuery(c, "max_degree", 0),
+	}
+
+	if opts.MaxDegree < 0 {
+		RespondWithValidationError(c, "max_degree", "must not be negative")
+		return opts, false
+	}
+	if len(opts.Waypoints) > 10 {
+		RespondWithValidationError(c, "via", "at most 10 waypoints are allowed")
+		return opts, false
+	}
+
+	for _, pattern := range c.QueryArray("exclude_pattern") {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			RespondWithValidationError(c, "exclude_pattern", "invalid regular expression: "+err.Error())
+			return opts, false
+		}
+		opts.ExcludePatterns = append(opts.ExcludePatterns, re)
+	}
+
+	return opts, true
+}
+
+// parseNeighborhoodOptions reads the direction, fanout and min_in_degree
+// query parameters of the connections endpoint. fanout is a comma-separated
+// list with one cap per hop.
+func parseNeighborhoodOptions(c *gin.Context) (graph.NeighborhoodOptions, bool) {
+	var opts graph.NeighborhoodOptions
+
+	direction, err := graph.ParseDirection(c.Query("direction"))
+	if err != nil {
+		RespondWithValidationError(c, "direction", "must be 'out', 'in' or 'both'")
+		return opts, false
+	}
+	opts.Direction = direction
+
+	if fanout := c.Query("fanout"); fanout != "" {
+		for _, field := range strings.Split(fanout, ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(field))
+			if err != nil || n < 0 {
+				RespondWithValidationError(c, "fanout", "must be a comma-separated list of non-negative integers")
+				return opts, false
+			}
+			opts.FanOut = append(opts.FanOut, n)
+		}
+		if len(opts.FanOut) > 5 {
+			RespondWithValidationError(c, "fanout", "at most one value per hop (5) is allowed")
+			return opts, false
+		}
+	}
+
+	opts.MinInDegree = parseIntQuery(c, "min_in_degree", 0)
+	if opts.MinInDegree < 0 {
+		RespondWithValidationError(c, "min_in_degree", "must not be negative")
+		return opts, false
+	}
+
+	return opts, true
+}
+
 // parseIntQuery parses an integer query parameter with a default value.
 func parseIntQuery(c *gin.Context, key string, defaultVal int) int {
 	val := c.Query(key)
@@ -44,13 +44,36 @@ func (s *Server) setupRouter() {
 	{
 		// Page endpoints
 		v1.GET("/page/:title", s.handleGetPage)
+		v1.GET("/search", s.handleSearch)
 
 		// Path endpoints
 		v1.GET("/path", s.handleFindPath)
+		v1.POST("/paths", s.handleFindPaths)
+		v1.GET("/distance", s.handleDistance)
+
+		// Ranking endpoints
+		v1.GET("/rank", s.handleRank)
+		v1.GET("/betweenness", s.handleBetweenness)
+		v1.POST("/betweenness", s.handleStartBetweenness)
+		v1.DELETE("/betweenness", s.handleCancelBetweenness)
+
+		// Graph analytics endpoints
+		v1.GET("/graph/stats", s.handleGraphStatistics)
+
+		// Community endpoints
+		v1.GET("/communities", s.handleListCommunities)
+		v1.GET("/communities/:id", s.handleGetCommunity)
 
 		// Connections endpoints
 		v1.GET("/connections/:title", s.handleGetConnections)
 
+		// Related pages
+		v1.GET("/similar/:title", s.handleSimilar)
+		v1.GET("/suggest-links/:title", s.handleSuggestLinks)
+
+		// First-link chains
+		v1.GET("/first-link/:title", s.handleFirstLink)
+
 		// Crawl endpoints
 		v1.POST("/crawl", s.handleCrawl)
 	}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
 	"github.com/Thinh-nguyen-03/wikigraph/internal/fetcher"
@@ -41,10 +42,9 @@ func NewWithGraphService(gs *GraphService, c *cache.Cache, f *fetcher.Fetcher, c
 // New creates a new API server with a pre-loaded graph.
 // This constructor is kept for backward compatibility and testing.
 // For production use, prefer NewWithGraphService for background loading.
-func New(g *graph.Graph, c *cache.Cache, f *fetcher.Fetcher, cfg Config) *Server {
+func New(g graph.View, c *cache.Cache, f *fetcher.Fetcher, cfg Config) *Server {
 	// Create a simple GraphService wrapper around the provided graph
 	gs := &GraphService{
-		g:     g,
 		cache: c,
 		state: StateReady,
 		progress: LoadProgress{
@@ -52,6 +52,7 @@ func New(g *graph.Graph, c *cache.Cache, f *fetcher.Fetcher, cfg Config) *Server
 			Stage: "complete",
 		},
 	}
+	gs.current.Store(&Snapshot{Version: 1, Graph: g, CreatedAt: time.Now()})
 
 	return NewWithGraphService(gs, c, f, cfg)
 }
@@ -112,7 +113,7 @@ func (s *Server) Router() *gin.Engine {
 }
 
 // Graph returns the graph if ready, or nil if still loading.
-func (s *Server) Graph() *graph.Graph {
+func (s *Server) Graph() graph.View {
 	g, _ := s.graphService.GetGraph()
 	return g
 }
@@ -2,20 +2,29 @@ package api
 
 import "time"
 
-// ErrorResponse is the standard error response format.
+// ErrorResponse is the standard error response format. Suggestions lists
+// titles close to one that was not found.
 type ErrorResponse struct {
-	Error     string `json:"error"`
-	Message   string `json:"message"`
-	RequestID string `json:"request_id,omitempty"`
+	Error       string   `json:"error"`
+	Message     string   `json:"message"`
+	Suggestions []string `json:"suggestions,omitempty"`
+	RequestID   string   `json:"request_id,omitempty"`
 }
 
-// HealthResponse is returned by the health check endpoint.
+// HealthResponse is returned by the health check endpoint. GraphVersion is
+// the version of the graph snapshot being served, zero before the first load.
+// Serving describes that snapshot and Rebuilding the latest rebuild, which
+// runs alongside it. Loading follows the first load until it completes.
 type HealthResponse struct {
-	Status            string     `json:"status"`
-	Version           string     `json:"version"`
-	Graph             GraphStats `json:"graph"`
-	GraphReady        bool       `json:"graph_ready"`
-	EmbeddingsEnabled bool       `json:"embeddings_enabled"`
+	Status            string         `json:"status"`
+	Version           string         `json:"version"`
+	Graph             GraphStats     `json:"graph"`
+	GraphReady        bool           `json:"graph_ready"`
+	GraphVersion      uint64         `json:"graph_version"`
+	Loading           *LoadProgress  `json:"loading,omitempty"`
+	Serving           *ServingStatus `json:"serving,omitempty"`
+	Rebuilding        RebuildStatus  `json:"rebuilding"`
+	EmbeddingsEnabled bool           `json:"embeddings_enabled"`
 }
 
 // GraphStats contains graph statistics for health response.
@@ -24,44 +33,225 @@ type GraphStats struct {
 	Edges int `json:"edges"`
 }
 
-// PageResponse is returned by the page endpoint.
+// PageResponse is returned by the page endpoint. RedirectedFrom is set when
+// the request named a redirect title; Title is then the canonical page.
 type PageResponse struct {
-	Title       string    `json:"title"`
-	Links       []string  `json:"links"`
-	LinkCount   int       `json:"link_count"`
-	InLinks     []string  `json:"in_links,omitempty"`
-	InLinkCount int       `json:"in_link_count"`
-	FetchedAt   time.Time `json:"fetched_at,omitempty"`
-	Cached      bool      `json:"cached"`
+	Title          string    `json:"title"`
+	RedirectedFrom string    `json:"redirected_from,omitempty"`
+	Links          []string  `json:"links"`
+	LinkCount      int       `json:"link_count"`
+	InLinks        []string  `json:"in_links,omitempty"`
+	InLinkCount    int       `json:"in_link_count"`
+	PageRank       *float64  `json:"pagerank,omitempty"`
+	Community      *int      `json:"community,omitempty"`
+	FetchedAt      time.Time `json:"fetched_at,omitempty"`
+	Cached         bool      `json:"cached"`
 }
 
 // PathResponse is returned by the path endpoint.
+// Path is the shortest path; Paths is only set for mode=all and mode=k, and
+// DAG, in place of both, for mode=dag.
+// Truncated is set when the search stopped early or, for mode=all and
+// mode=k, when more paths exist; BudgetExhausted when the search hit its
+// explored-node cap. Reason is "unreachable" when the graph's structure
+// rules out any path without searching. Redirects maps each redirect title
+// in the request to the canonical page that was used instead.
 type PathResponse struct {
-	Found      bool     `json:"found"`
-	From       string   `json:"from"`
-	To         string   `json:"to"`
-	Path       []string `json:"path,omitempty"`
-	Hops       int      `json:"hops"`
-	Explored   int      `json:"explored"`
-	Algorithm  string   `json:"algorithm"`
-	DurationMs int64    `json:"duration_ms"`
+	Found           bool              `json:"found"`
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	Path            []string          `json:"path,omitempty"`
+	Paths           [][]string        `json:"paths,omitempty"`
+	DAG             *PathDAG          `json:"dag,omitempty"`
+	Hops            int               `json:"hops"`
+	Explored        int               `json:"explored"`
+	Algorithm       string            `json:"algorithm"`
+	Mode            string            `json:"mode"`
+	Truncated       bool              `json:"truncated"`
+	BudgetExhausted bool              `json:"budget_exhausted"`
+	Reason          string            `json:"reason,omitempty"`
+	Redirects       map[string]string `json:"redirects,omitempty"`
+	DurationMs      int64             `json:"duration_ms"`
+}
+
+// PathDAG is the union of every shortest path between two pages: the pages
+// on one, by distance from the source, and the links between them that lie
+// on one.
+type PathDAG struct {
+	Nodes []GraphNode `json:"nodes"`
+	Edges []GraphEdge `json:"edges"`
+}
+
+// DistanceResponse bounds the number of hops between two pages. Lower and
+// Upper are null when unknown, and both are null for unreachable pages.
+// Explored and Truncated describe the search run for exact=true.
+type DistanceResponse struct {
+	From            string            `json:"from"`
+	To              string            `json:"to"`
+	Lower           *int              `json:"lower"`
+	Upper           *int              `json:"upper"`
+	Exact           bool              `json:"exact"`
+	Unreachable     bool              `json:"unreachable"`
+	Searched        bool              `json:"searched"`
+	Explored        int               `json:"explored,omitempty"`
+	Truncated       bool              `json:"truncated,omitempty"`
+	BudgetExhausted bool              `json:"budget_exhausted,omitempty"`
+	Redirects       map[string]string `json:"redirects,omitempty"`
+	DurationMs      int64             `json:"duration_ms"`
+}
+
+// PathsRequest is the request body of the batch path endpoint. It lists
+// either pairs, or sources and targets to search every combination of.
+// Output is "paths" (default) for one result per pair or "matrix" for a
+// table of hop counts. MaxDepth and MaxExplored apply to the search from
+// each distinct source; zero means the default.
+type PathsRequest struct {
+	Pairs       []PagePair `json:"pairs"`
+	Sources     []string   `json:"sources"`
+	Targets     []string   `json:"targets"`
+	Output      string     `json:"output"`
+	MaxDepth    int        `json:"max_depth"`
+	MaxExplored int        `json:"max_explored"`
+}
+
+// PagePair is one (from, to) query of a batch.
+type PagePair struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// PathsResponse is returned by the batch path endpoint. Results is set for
+// output=paths; Sources, Targets and Distances for output=matrix, where a
+// null distance means no path was found or the pair was not asked for.
+// Searches is the number of distinct sources searched and Explored the
+// pages they expanded in total. Truncated is set when any search stopped
+// early.
+type PathsResponse struct {
+	Results    []PairResult      `json:"results,omitempty"`
+	Sources    []string          `json:"sources,omitempty"`
+	Targets    []string          `json:"targets,omitempty"`
+	Distances  [][]*int          `json:"distances,omitempty"`
+	Pairs      int               `json:"pairs"`
+	Found      int               `json:"found"`
+	Searches   int               `json:"searches"`
+	Explored   int               `json:"explored"`
+	Truncated  bool              `json:"truncated"`
+	Redirects  map[string]string `json:"redirects,omitempty"`
+	DurationMs int64             `json:"duration_ms"`
+}
+
+// PairResult is the shortest path found for one pair of a batch. Reason is
+// "page_not_found" when either page is not in the graph and "unreachable"
+// when the graph's structure rules out any path without searching.
+type PairResult struct {
+	From            string   `json:"from"`
+	To              string   `json:"to"`
+	Found           bool     `json:"found"`
+	Path            []string `json:"path,omitempty"`
+	Hops            int      `json:"hops"`
+	Truncated       bool     `json:"truncated,omitempty"`
+	BudgetExhausted bool     `json:"budget_exhausted,omitempty"`
+	Reason          string   `json:"reason,omitempty"`
+}
+
+// RankResponse is returned by the rank endpoint. Seeds is only set for a
+// personalized ranking.
+type RankResponse struct {
+	Seeds      []string     `json:"seeds,omitempty"`
+	Pages      []RankedPage `json:"pages"`
+	Count      int          `json:"count"`
+	Damping    float64      `json:"damping"`
+	Iterations int          `json:"iterations"`
+	Converged  bool         `json:"converged"`
+	DurationMs int64        `json:"duration_ms"`
+}
+
+// RankedPage is a page with its position and PageRank score.
+type RankedPage struct {
+	Rank  int     `json:"rank"`
+	Title string  `json:"title"`
+	Score float64 `json:"score"`
+}
+
+// BetweennessResponse is returned by the betweenness endpoints. Pages is
+// only set once the computation is complete.
+type BetweennessResponse struct {
+	Status  BetweennessStatus `json:"status"`
+	Pages   []RankedPage      `json:"pages,omitempty"`
+	Count   int               `json:"count"`
+	Samples int               `json:"samples"`
+	Exact   bool              `json:"exact"`
+}
+
+// GraphStatisticsResponse is returned by the graph statistics endpoint.
+type GraphStatisticsResponse struct {
+	Nodes             int                `json:"nodes"`
+	Edges             int                `json:"edges"`
+	InDegree          DegreeDistribution `json:"in_degree"`
+	OutDegree         DegreeDistribution `json:"out_degree"`
+	Hubs              []RankedPage       `json:"hubs"`
+	Authorities       []RankedPage       `json:"authorities"`
+	Reciprocity       float64            `json:"reciprocity"`
+	Clustering        float64            `json:"clustering"`
+	ClusteringExact   bool               `json:"clustering_exact"`
+	Dangling          int                `json:"dangling"`
+	UnfetchedTargets  int64              `json:"unfetched_targets"`
+	Orphans           int                `json:"orphans"`
+	Diameter          int                `json:"diameter"`
+	AveragePathLength float64            `json:"average_path_length"`
+	Reachability      float64            `json:"reachability"`
+	PathSamples       int                `json:"path_samples"`
+	DurationMs        int64              `json:"duration_ms"`
+}
+
+// DegreeDistribution summarizes the in- or out-degrees of every page.
+type DegreeDistribution struct {
+	Mean        float64            `json:"mean"`
+	Max         int                `json:"max"`
+	Percentiles []DegreePercentile `json:"percentiles"`
+	Histogram   []DegreeBucket     `json:"histogram"`
+}
+
+// DegreePercentile is the degree at or below which a percentage of pages fall.
+type DegreePercentile struct {
+	Percentile float64 `json:"percentile"`
+	Degree     int     `json:"degree"`
+}
+
+// DegreeBucket counts the pages with a degree between Min and Max.
+type DegreeBucket struct {
+	Min   int `json:"min"`
+	Max   int `json:"max"`
+	Count int `json:"count"`
 }
 
 // ConnectionsResponse is returned by the connections endpoint.
+// Direction is the link direction followed. Truncated is set when the
+// traversal stopped early; BudgetExhausted when it hit its explored-node cap.
 type ConnectionsResponse struct {
-	Center    string      `json:"center"`
-	Depth     int         `json:"depth"`
-	Nodes     []GraphNode `json:"nodes"`
-	Edges     []GraphEdge `json:"edges"`
-	NodeCount int         `json:"node_count"`
-	EdgeCount int         `json:"edge_count"`
+	Center          string      `json:"center"`
+	RedirectedFrom  string      `json:"redirected_from,omitempty"`
+	Depth           int         `json:"depth"`
+	Direction       string      `json:"direction"`
+	Nodes           []GraphNode `json:"nodes"`
+	Edges           []GraphEdge `json:"edges"`
+	NodeCount       int         `json:"node_count"`
+	EdgeCount       int         `json:"edge_count"`
+	Truncated       bool        `json:"truncated"`
+	BudgetExhausted bool        `json:"budget_exhausted"`
 }
 
 // GraphNode represents a node in the subgraph response.
+// InDegree and OutDegree count the page's links across the whole graph.
+// Community and Color are only set when colouring by community.
 type GraphNode struct {
-	ID    string `json:"id"`
-	Title string `json:"title"`
-	Hops  int    `json:"hops"`
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	Hops      int    `json:"hops"`
+	InDegree  int    `json:"in_degree"`
+	OutDegree int    `json:"out_degree"`
+	Community *int   `json:"community,omitempty"`
+	Color     string `json:"color,omitempty"`
 }
 
 // GraphEdge represents an edge in the subgraph response.
@@ -70,6 +260,64 @@ type GraphEdge struct {
 	Target string `json:"target"`
 }
 
+// CommunitiesResponse is returned by the communities endpoint.
+type CommunitiesResponse struct {
+	Communities []CommunitySummary `json:"communities"`
+	Count       int                `json:"count"`
+	Total       int                `json:"total"`
+}
+
+// CommunitySummary describes a community with a sample of its pages.
+type CommunitySummary struct {
+	ID     int      `json:"id"`
+	Size   int      `json:"size"`
+	Sample []string `json:"sample"`
+}
+
+// CommunityResponse is returned by the community members endpoint.
+type CommunityResponse struct {
+	ID      int      `json:"id"`
+	Size    int      `json:"size"`
+	Members []string `json:"members"`
+	Count   int      `json:"count"`
+}
+
+// FirstLinkResponse is returned by the first-link endpoint. Path starts at
+// Start and follows the first link of each page; Cycle holds the loop the
+// chain ended in. End is "cycle", "no_link", "unordered" (the last page was
+// fetched before link order was recorded), "not_fetched" or "max_steps".
+type FirstLinkResponse struct {
+	Start          string   `json:"start"`
+	RedirectedFrom string   `json:"redirected_from,omitempty"`
+	Path           []string `json:"path"`
+	Steps          int      `json:"steps"`
+	Cycle          []string `json:"cycle,omitempty"`
+	ConvergesTo    string   `json:"converges_to"`
+	End            string   `json:"end"`
+	DurationMs     int64    `json:"duration_ms"`
+}
+
+// SuggestLinksResponse is returned by the link suggestion endpoint. Each
+// suggestion is a page Title does not link to yet.
+type SuggestLinksResponse struct {
+	Title          string           `json:"title"`
+	RedirectedFrom string           `json:"redirected_from,omitempty"`
+	Method         string           `json:"method"`
+	Suggestions    []LinkSuggestion `json:"suggestions"`
+	Count          int              `json:"count"`
+	DurationMs     int64            `json:"duration_ms"`
+}
+
+// LinkSuggestion is a candidate link target. Shared lists the neighbours
+// supporting it, least linked first, up to the requested number;
+// SharedCount counts them all.
+type LinkSuggestion struct {
+	Title       string   `json:"title"`
+	Score       float64  `json:"score"`
+	Shared      []string `json:"shared"`
+	SharedCount int      `json:"shared_count"`
+}
+
 // CrawlRequest is the request body for starting a crawl job.
 type CrawlRequest struct {
 	Title    string `json:"title" binding:"required"`
@@ -84,12 +332,35 @@ type CrawlResponse struct {
 	Message string `json:"message"`
 }
 
-// SimilarResponse is returned by the similar endpoint (Phase 3).
+// SearchResponse is returned by the search endpoint.
+type SearchResponse struct {
+	Query      string        `json:"query"`
+	Results    []SearchMatch `json:"results"`
+	Count      int           `json:"count"`
+	DurationMs int64         `json:"duration_ms"`
+}
+
+// SearchMatch is a page whose title, or a redirect to it, starts with the
+// query. Matched is the redirect title that matched; Typos is the number
+// of typos between the query and the start of the matched title.
+type SearchMatch struct {
+	Title   string `json:"title"`
+	Matched string `json:"matched,omitempty"`
+	Typos   int    `json:"typos"`
+	InLinks int    `json:"in_links"`
+}
+
+// SimilarResponse is returned by the similar endpoint. Method names the
+// link-based score used; RedirectedFrom is set when the request named a
+// redirect title.
 type SimilarResponse struct {
-	Query     string        `json:"query"`
-	Similar   []SimilarPage `json:"similar"`
-	Count     int           `json:"count"`
-	Threshold float64       `json:"threshold"`
+	Query          string        `json:"query"`
+	RedirectedFrom string        `json:"redirected_from,omitempty"`
+	Method         string        `json:"method"`
+	Similar        []SimilarPage `json:"similar"`
+	Count          int           `json:"count"`
+	Threshold      float64       `json:"threshold"`
+	DurationMs     int64         `json:"duration_ms"`
 }
 
 // SimilarPage represents a similar page with its score.
@@ -4,10 +4,12 @@ package cache
 import (
 	"database/sql"
 	"fmt"
+	"iter"
 	"strings"
 	"time"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/wikititle"
 )
 
 type Cache struct {
@@ -39,10 +41,16 @@ type Page struct {
 	UpdatedAt   string
 }
 
+// Link is a stored link. Position orders the links of a page and is null
+// for links stored before link order was recorded.
 type Link struct {
 	ID          int64
 	SourceID    int64
 	TargetTitle string
+	Position    sql.NullInt64
+	InParens    bool
+	InItalics   bool
+	InProse     bool
 	CreatedAt   string
 }
 
@@ -61,7 +69,20 @@ func scanPage(s scanner) (*Page, error) {
 	return p, nil
 }
 
+// GetPage returns the page with the given title, or nil if there is none.
+// A title not stored as given is looked up again in normalized form, so
+// "albert_einstein" finds "Albert einstein".
 func (c *Cache) GetPage(title string) (*Page, error) {
+	p, err := c.getPageByTitle(title)
+	if p == nil && err == nil {
+		if normalized := wikititle.Normalize(title); normalized != title {
+			p, err = c.getPageByTitle(normalized)
+		}
+	}
+	return p, err
+}
+
+func (c *Cache) getPageByTitle(title string) (*Page, error) {
 	row := c.db.QueryRow(`SELECT `+pageColumns+` FROM pages WHERE title = ?`, title)
 	p, err := scanPage(row)
 
@@ -74,8 +95,9 @@ func (c *Cache) GetPage(title string) (*Page, error) {
 	return p, nil
 }
 
+// CreatePage adds a pending page under the normalized form of title.
 func (c *Cache) CreatePage(title string) (*Page, error) {
-	result, err := c.db.Exec(`INSERT INTO pages (title) VALUES (?)`, title)
+	result, err := c.db.Exec(`INSERT INTO pages (title) VALUES (?)`, wikititle.Normalize(title))
 	if err != nil {
 		return nil, fmt.Errorf("inserting page: %w", err)
 	}
@@ -165,6 +187,25 @@ func (c *Cache) AddLinks(sourceID int64, links []Link) error {
 	}
 	defer tx.Rollback()
 
+	if err := insertLinks(tx, sourceID, links, false); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// insertLinks adds links from sourceID in batches. A link that already
+// exists is skipped, or with update has its order and context replaced.
+func insertLinks(tx *sql.Tx, sourceID int64, links []Link, update bool) error {
+	conflict := "DO NOTHING"
+	if update {
+		conflict = `DO UPDATE SET position = excluded.position, in_parens = excluded.in_parens,
+				in_italics = excluded.in_italics, in_prose = excluded.in_prose`
+	}
+
 	const batchSize = 500
 	for i := 0; i < len(links); i += batchSize {
 		end := i + batchSize
@@ -176,23 +217,20 @@ func (c *Cache) AddLinks(sourceID int64, links []Link) error {
 		var placeholders []string
 		var args []interface{}
 		for _, link := range batch {
-			placeholders = append(placeholders, "(?, ?)")
-			args = append(args, sourceID, link.TargetTitle)
+			placeholders = append(placeholders, "(?, ?, ?, ?, ?, ?)")
+			args = append(args, sourceID, link.TargetTitle, link.Position, link.InParens, link.InItalics, link.InProse)
 		}
 
 		query := fmt.Sprintf(`
-			INSERT OR IGNORE INTO links (source_id, target_title)
+			INSERT INTO links (source_id, target_title, position, in_parens, in_italics, in_prose)
 			VALUES %s
-		`, strings.Join(placeholders, ", "))
+			ON CONFLICT (source_id, target_title) %s
+		`, strings.Join(placeholders, ", "), conflict)
 
 		if _, err := tx.Exec(query, args...); err != nil {
 			return fmt.Errorf("inserting links batch: %w", err)
 		}
 	}
-
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("committing transaction: %w", err)
-	}
 	return nil
 }
 
@@ -232,14 +270,34 @@ func (c *Cache) GetIncomingLinks(targetTitle string) ([]int64, error) {
 	return ids, rows.Err()
 }
 
+// DeleteLinksFromPage removes every link from sourceID, recording them in
+// the link history.
 func (c *Cache) DeleteLinksFromPage(sourceID int64) error {
-	_, err := c.db.Exec(`DELETE FROM links WHERE source_id = ?`, sourceID)
+	tx, err := c.db.Begin()
 	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO link_history (source_id, target_title, created_at)
+		SELECT source_id, target_title, created_at FROM links WHERE source_id = ?
+	`, sourceID); err != nil {
+		return fmt.Errorf("recording removed links: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM links WHERE source_id = ?`, sourceID); err != nil {
 		return fmt.Errorf("deleting links: %w", err)
 	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
 	return nil
 }
 
+// ReplaceLinks makes links the complete set of links from sourceID. Links
+// the page keeps stay in place with their original created_at; those it
+// no longer has are moved to the link history.
 func (c *Cache) ReplaceLinks(sourceID int64, links []Link) error {
 	tx, err := c.db.Begin()
 	if err != nil {
@@ -247,37 +305,51 @@ func (c *Cache) ReplaceLinks(sourceID int64, links []Link) error {
 	}
 	defer tx.Rollback()
 
-	if _, err := tx.Exec(`DELETE FROM links WHERE source_id = ?`, sourceID); err != nil {
-		return fmt.Errorf("deleting old links: %w", err)
+	keep := make(map[string]bool, len(links))
+	for _, link := range links {
+		keep[link.TargetTitle] = true
 	}
 
-	if len(links) == 0 {
-		return tx.Commit()
+	rows, err := tx.Query(`SELECT target_title FROM links WHERE source_id = ?`, sourceID)
+	if err != nil {
+		return fmt.Errorf("querying old links: %w", err)
+	}
+	var removed []any
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			rows.Close()
+			return fmt.Errorf("scanning old link: %w", err)
+		}
+		if !keep[title] {
+			removed = append(removed, title)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating old links: %w", err)
 	}
 
 	const batchSize = 500
-	for i := 0; i < len(links); i += batchSize {
-		end := i + batchSize
-		if end > len(links) {
-			end = len(links)
+	for i := 0; i < len(removed); i += batchSize {
+		batch := removed[i:min(i+batchSize, len(removed))]
+		in := strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", ")
+		args := append([]any{sourceID}, batch...)
+
+		if _, err := tx.Exec(`
+			INSERT INTO link_history (source_id, target_title, created_at)
+			SELECT source_id, target_title, created_at FROM links
+			WHERE source_id = ? AND target_title IN (`+in+`)
+		`, args...); err != nil {
+			return fmt.Errorf("recording removed links: %w", err)
 		}
-		batch := links[i:end]
-
-		var placeholders []string
-		var args []interface{}
-		for _, link := range batch {
-			placeholders = append(placeholders, "(?, ?)")
-			args = append(args, sourceID, link.TargetTitle)
+		if _, err := tx.Exec(`DELETE FROM links WHERE source_id = ? AND target_title IN (`+in+`)`, args...); err != nil {
+			return fmt.Errorf("deleting old links: %w", err)
 		}
+	}
 
-		query := fmt.Sprintf(`
-			INSERT OR IGNORE INTO links (source_id, target_title)
-			VALUES %s
-		`, strings.Join(placeholders, ", "))
-
-		if _, err := tx.Exec(query, args...); err != nil {
-			return fmt.Errorf("inserting links batch: %w", err)
-		}
+	if err := insertLinks(tx, sourceID, links, true); err != nil {
+		return err
 	}
 
 	if err := tx.Commit(); err != nil {
@@ -354,10 +426,42 @@ type GraphData struct {
 	Edges [][2]string  // [source, target] pairs
 }
 
+// GetGraphData reads the whole graph into memory. ScanGraphData reads it
+// without holding the rows.
 func (c *Cache) GetGraphData() (*GraphData, error) {
 	data := &GraphData{}
+	if err := c.ScanGraphData(data.addEdge, data.addNode); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
 
-	rows, err := c.db.Query(`
+// GetGraphDataAt reads the graph ScanGraphDataAt yields into memory.
+func (c *Cache) GetGraphDataAt(t time.Time) (*GraphData, error) {
+	data := &GraphData{}
+	if err := c.ScanGraphDataAt(t, data.addEdge, data.addNode); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (d *GraphData) addEdge(source, target string) error {
+	d.Edges = append(d.Edges, [2]string{source, target})
+	return nil
+}
+
+func (d *GraphData) addNode(title string) error {
+	d.Nodes = append(d.Nodes, title)
+	return nil
+}
+
+// ScanGraphData calls edge for every link from a fetched page, then node
+// for every fetched page without links, straight from the database cursor.
+// An error from either stops the scan and is returned. The scan runs on the
+// database's read-only connection, so other queries and writes go ahead
+// while it lasts.
+func (c *Cache) ScanGraphData(edge func(source, target string) error, node func(title string) error) error {
+	err := c.scanRows("edges", scanEdge(edge), `
 		SELECT p.title, l.target_title
 		FROM links l
 		INDEXED BY idx_links_source_target_covering
@@ -365,43 +469,202 @@ func (c *Cache) GetGraphData() (*GraphData, error) {
 		WHERE p.fetch_status = 'success'
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("querying edges: %w", err)
+		return err
+	}
+
+	return c.scanRows("isolated nodes", scanTitle("isolated node", node), `
+		SELECT p.title FROM pages p
+		LEFT JOIN links l ON l.source_id = p.id
+		WHERE p.fetch_status = 'success' AND l.id IS NULL
+	`)
+}
+
+// ScanGraphDataAt scans the graph as it was at time t, as ScanGraphData
+// does: the links that existed then, from the pages that are articles now,
+// and as nodes every such page last fetched by t. Links removed before the
+// link history was recorded cannot be recovered.
+func (c *Cache) ScanGraphDataAt(t time.Time, edge func(source, target string) error, node func(title string) error) error {
+	at := t.UTC().Format(time.RFC3339)
+
+	err := c.scanRows("edges", scanEdge(edge), `
+		SELECT p.title, l.target_title
+		FROM links l
+		JOIN pages p ON p.id = l.source_id
+		WHERE p.fetch_status = 'success' AND l.created_at <= ?
+		UNION ALL
+		SELECT p.title, h.target_title
+		FROM link_history h
+		JOIN pages p ON p.id = h.source_id
+		WHERE h.created_at <= ? AND h.removed_at > ?
+	`, at, at, at)
+	if err != nil {
+		return err
+	}
+
+	// Pages with edges are listed again here; adding a node twice is
+	// harmless.
+	return c.scanRows("pages", scanTitle("page", node), `
+		SELECT title FROM pages
+		WHERE fetch_status = 'success' AND fetched_at <= ?
+	`, at)
+}
+
+// EstimateLinkCount returns the highest link ID, a quick upper bound on the
+// number of links that overcounts by the links deleted since.
+func (c *Cache) EstimateLinkCount() (int64, error) {
+	var count sql.NullInt64
+	if err := c.db.QueryRow(`SELECT MAX(id) FROM links`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("estimating link count: %w", err)
+	}
+	return count.Int64, nil
+}
+
+// scanRows runs a query on the read-only connection and calls fn on each
+// row until fn returns an error. what names the rows in errors.
+func (c *Cache) scanRows(what string, fn func(*sql.Rows) error, query string, args ...any) error {
+	rows, err := c.db.Reader().Query(query, args...)
+	if err != nil {
+		return fmt.Errorf("querying %s: %w", what, err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
+		if err := fn(rows); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterating %s: %w", what, err)
+	}
+	return nil
+}
+
+func scanEdge(edge func(source, target string) error) func(*sql.Rows) error {
+	return func(rows *sql.Rows) error {
 		var source, target string
 		if err := rows.Scan(&source, &target); err != nil {
-			return nil, fmt.Errorf("scanning edge: %w", err)
+			return fmt.Errorf("scanning edge: %w", err)
 		}
-		data.Edges = append(data.Edges, [2]string{source, target})
+		return edge(source, target)
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating edges: %w", err)
+}
+
+func scanTitle(what string, node func(title string) error) func(*sql.Rows) error {
+	return func(rows *sql.Rows) error {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return fmt.Errorf("scanning %s: %w", what, err)
+		}
+		return node(title)
 	}
+}
 
-	rows, err = c.db.Query(`
-		SELECT p.title FROM pages p
-		LEFT JOIN links l ON l.source_id = p.id
-		WHERE p.fetch_status = 'success' AND l.id IS NULL
+// GetRedirects returns the target of every page recorded as a redirect,
+// keyed by the redirect title. Chains are not followed.
+func (c *Cache) GetRedirects() (map[string]string, error) {
+	rows, err := c.db.Query(`
+		SELECT title, redirect_to FROM pages
+		WHERE fetch_status = 'redirect' AND redirect_to IS NOT NULL
 	`)
 	if err != nil {
-		return nil, fmt.Errorf("querying isolated nodes: %w", err)
+		return nil, fmt.Errorf("querying redirects: %w", err)
 	}
 	defer rows.Close()
 
+	redirects := make(map[string]string)
 	for rows.Next() {
-		var title string
-		if err := rows.Scan(&title); err != nil {
-			return nil, fmt.Errorf("scanning isolated node: %w", err)
+		var title, target string
+		if err := rows.Scan(&title, &target); err != nil {
+			return nil, fmt.Errorf("scanning redirect: %w", err)
 		}
-		data.Nodes = append(data.Nodes, title)
+		redirects[title] = target
 	}
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("iterating isolated nodes: %w", err)
+		return nil, fmt.Errorf("iterating redirects: %w", err)
 	}
 
-	return data, nil
+	return redirects, nil
+}
+
+// maxRedirectHops bounds ResolveRedirect on redirect loops.
+const maxRedirectHops = 5
+
+// ResolveRedirect follows redirect pages from title to the page they lead
+// to. A title that is not a known redirect is returned as the page is
+// stored, or unchanged if there is no such page.
+func (c *Cache) ResolveRedirect(title string) (string, error) {
+	page, err := c.GetPage(title)
+	if err != nil {
+		return "", err
+	}
+	if page != nil {
+		title = page.Title
+	}
+	for range maxRedirectHops {
+		var target sql.NullString
+		err := c.db.QueryRow(`
+			SELECT redirect_to FROM pages
+			WHERE title = ? AND fetch_status = 'redirect'
+		`, title).Scan(&target)
+		if err == sql.ErrNoRows || (err == nil && !target.Valid) {
+			return title, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("resolving redirect: %w", err)
+		}
+		title = target.String
+	}
+	return title, nil
+}
+
+// FirstLinkStatus says what GetFirstLink found for a page.
+type FirstLinkStatus string
+
+const (
+	FirstLinkFound      FirstLinkStatus = "found"
+	FirstLinkNone       FirstLinkStatus = "no_link"     // no link in the text outside parentheses and italics
+	FirstLinkUnordered  FirstLinkStatus = "unordered"   // links stored before their order was recorded
+	FirstLinkNotFetched FirstLinkStatus = "not_fetched" // page unknown, pending, not found or failed
+)
+
+// GetFirstLink returns the first link in the text of a fetched page that is
+// outside parentheses and italics, with redirects resolved. The title
+// itself must not be a redirect.
+func (c *Cache) GetFirstLink(title string) (string, FirstLinkStatus, error) {
+	page, err := c.GetPage(title)
+	if err != nil {
+		return "", "", err
+	}
+	if page == nil || page.FetchStatus != StatusSuccess {
+		return "", FirstLinkNotFetched, nil
+	}
+
+	// A page's links are written together, so one without a position
+	// means none of them has one. NULLs sort first.
+	var target string
+	var position sql.NullInt64
+	err = c.db.QueryRow(`
+		SELECT target_title, position FROM links
+		WHERE source_id = ?
+		  AND (position IS NULL OR (in_prose = 1 AND in_parens = 0 AND in_italics = 0))
+		ORDER BY position
+		LIMIT 1
+	`, page.ID).Scan(&target, &position)
+	if err == sql.ErrNoRows {
+		return "", FirstLinkNone, nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("querying first link: %w", err)
+	}
+	if !position.Valid {
+		return "", FirstLinkUnordered, nil
+	}
+
+	target, err = c.ResolveRedirect(target)
+	if err != nil {
+		return "", "", err
+	}
+	return target, FirstLinkFound, nil
 }
 
 // UpdatedPage represents a page that has been modified in the database.
@@ -472,3 +735,220 @@ func (c *Cache) GetPageLinks(pageID int64) ([]string, error) {
 
 	return links, nil
 }
+
+// CountUnfetchedTargets returns the number of distinct pages that fetched
+// pages link to but that have not been fetched successfully themselves.
+// These are the dangling nodes of the loaded graph.
+func (c *Cache) CountUnfetchedTargets() (int64, error) {
+	var count int64
+	err := c.db.QueryRow(`
+		SELECT COUNT(DISTINCT l.target_title)
+		FROM links l
+		JOIN pages s ON s.id = l.source_id
+		WHERE s.fetch_status = 'success'
+		  AND NOT EXISTS (
+			SELECT 1 FROM pages t
+			WHERE t.title = l.target_title AND t.fetch_status = 'success'
+		  )
+	`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("counting unfetched targets: %w", err)
+	}
+	return count, nil
+}
+
+// CommunitySummary describes one detected community.
+type CommunitySummary struct {
+	ID   int
+	Size int
+}
+
+// ReplaceCommunities stores the result of a community detection run,
+// replacing any earlier one. sizes[i] is the size of community i.
+func (c *Cache) ReplaceCommunities(sizes []int, assignments iter.Seq2[string, int]) error {
+	tx, err := c.db.Begin()
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM page_communities`); err != nil {
+		return fmt.Errorf("deleting old assignments: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM communities`); err != nil {
+		return fmt.Errorf("deleting old communities: %w", err)
+	}
+
+	const batchSize = 500
+	insert := func(table, columns string, args []interface{}) error {
+		placeholders := strings.Repeat("(?, ?), ", len(args)/2)
+		query := fmt.Sprintf(`INSERT INTO %s (%s) VALUES %s`,
+			table, columns, strings.TrimSuffix(placeholders, ", "))
+		if _, err := tx.Exec(query, args...); err != nil {
+			return fmt.Errorf("inserting %s batch: %w", table, err)
+		}
+		return nil
+	}
+
+	var args []interface{}
+	for id, size := range sizes {
+		args = append(args, id, size)
+		if len(args) == 2*batchSize {
+			if err := insert("communities", "id, size", args); err != nil {
+				return err
+			}
+			args = args[:0]
+		}
+	}
+	if len(args) > 0 {
+		if err := insert("communities", "id, size", args); err != nil {
+			return err
+		}
+		args = args[:0]
+	}
+
+	for title, id := range assignments {
+		args = append(args, title, id)
+		if len(args) == 2*batchSize {
+			if err := insert("page_communities", "title, community_id", args); err != nil {
+				return err
+			}
+			args = args[:0]
+		}
+	}
+	if len(args) > 0 {
+		if err := insert("page_communities", "title, community_id", args); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("committing transaction: %w", err)
+	}
+	return nil
+}
+
+// GetCommunity returns the community of a page.
+// Returns false if no community has been stored for it.
+func (c *Cache) GetCommunity(title string) (int, bool, error) {
+	var id int
+	err := c.db.QueryRow(`SELECT community_id FROM page_communities WHERE title = ?`, title).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("querying community: %w", err)
+	}
+	return id, true, nil
+}
+
+// GetCommunities returns the communities of the given pages. Pages without
+// a stored community are left out.
+func (c *Cache) GetCommunities(titles []string) (map[string]int, error) {
+	result := make(map[string]int, len(titles))
+
+	const batchSize = 500
+	for i := 0; i < len(titles); i += batchSize {
+		batch := titles[i:min(i+batchSize, len(titles))]
+
+		args := make([]interface{}, len(batch))
+		for j, title := range batch {
+			args[j] = title
+		}
+		query := fmt.Sprintf(`
+			SELECT title, community_id FROM page_communities
+			WHERE title IN (%s)
+		`, strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", "))
+
+		rows, err := c.db.Query(query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("querying communities: %w", err)
+		}
+		for rows.Next() {
+			var title string
+			var id int
+			if err := rows.Scan(&title, &id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scanning community: %w", err)
+			}
+			result[title] = id
+		}
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return nil, fmt.Errorf("iterating communities: %w", err)
+		}
+	}
+
+	return result, nil
+}
+
+// ListCommunities returns the largest communities, biggest first.
+func (c *Cache) ListCommunities(limit, offset int) ([]CommunitySummary, error) {
+	rows, err := c.db.Query(`
+		SELECT id, size FROM communities
+		ORDER BY size DESC, id ASC
+		LIMIT ? OFFSET ?
+	`, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying communities: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []CommunitySummary
+	for rows.Next() {
+		var s CommunitySummary
+		if err := rows.Scan(&s.ID, &s.Size); err != nil {
+			return nil, fmt.Errorf("scanning community: %w", err)
+		}
+		summaries = append(summaries, s)
+	}
+	return summaries, rows.Err()
+}
+
+// CountCommunities returns the number of stored communities.
+func (c *Cache) CountCommunities() (int, error) {
+	var count int
+	if err := c.db.QueryRow(`SELECT COUNT(*) FROM communities`).Scan(&count); err != nil {
+		return 0, fmt.Errorf("counting communities: %w", err)
+	}
+	return count, nil
+}
+
+// GetCommunitySize returns the size of a community.
+// Returns false if there is no such community.
+func (c *Cache) GetCommunitySize(id int) (int, bool, error) {
+	var size int
+	err := c.db.QueryRow(`SELECT size FROM communities WHERE id = ?`, id).Scan(&size)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("querying community size: %w", err)
+	}
+	return size, true, nil
+}
+
+// GetCommunityMembers returns the pages of a community in title order.
+func (c *Cache) GetCommunityMembers(id, limit, offset int) ([]string, error) {
+	rows, err := c.db.Query(`
+		SELECT title FROM page_communities
+		WHERE community_id = ?
+		ORDER BY title
+		LIMIT ? OFFSET ?
+	`, id, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("querying community members: %w", err)
+	}
+	defer rows.Close()
+
+	var titles []string
+	for rows.Next() {
+		var title string
+		if err := rows.Scan(&title); err != nil {
+			return nil, fmt.Errorf("scanning member: %w", err)
+		}
+		titles = append(titles, title)
+	}
+	return titles, rows.Err()
+}
@@ -1,9 +1,12 @@
 package cache
 
 import (
+	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/database"
 )
@@ -66,6 +69,39 @@ func TestGetPage(t *testing.T) {
 	}
 }
 
+func TestGetPage_Normalized(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	if _, err := c.CreatePage("albert_einstein"); err != nil {
+		t.Fatalf("CreatePage error: %v", err)
+	}
+	for _, title := range []string{"Albert einstein", "albert einstein", "Albert_einstein"} {
+		page, err := c.GetPage(title)
+		if err != nil {
+			t.Fatalf("GetPage(%q) error: %v", title, err)
+		}
+		if page == nil || page.Title != "Albert einstein" {
+			t.Errorf("GetPage(%q) = %+v, want page %q", title, page, "Albert einstein")
+		}
+	}
+
+	// A title stored as given is found exactly, even when not normalized.
+	if _, err := db.Exec(`INSERT INTO pages (title) VALUES ('iPod')`); err != nil {
+		t.Fatal(err)
+	}
+	if page, _ := c.GetPage("iPod"); page == nil || page.Title != "iPod" {
+		t.Errorf("GetPage(iPod) = %+v, want the stored page", page)
+	}
+
+	c.CreatePage("USA")
+	c.UpdatePageStatus("USA", StatusRedirect, "", "Albert einstein")
+	if resolved, err := c.ResolveRedirect("uSA"); err != nil || resolved != "Albert einstein" {
+		t.Errorf("ResolveRedirect(uSA) = %q, %v, want %q", resolved, err, "Albert einstein")
+	}
+}
+
 func TestGetOrCreatePage(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -202,6 +238,155 @@ func TestAddLinks_Duplicates(t *testing.T) {
 	}
 }
 
+func TestCountUnfetchedTargets(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	a, _ := c.CreatePage("A")
+	b, _ := c.CreatePage("B")
+	c.UpdatePageStatus("A", StatusSuccess, "hash", "")
+	c.AddLinks(a.ID, []Link{{TargetTitle: "B"}, {TargetTitle: "C"}, {TargetTitle: "D"}})
+	c.AddLinks(b.ID, []Link{{TargetTitle: "E"}})
+	c.UpdatePageStatus("B", StatusSuccess, "hash", "")
+	c.CreatePage("C")
+	c.UpdatePageStatus("C", StatusNotFound, "", "")
+
+	// C was not found and D, E were never fetched; B was.
+	count, err := c.CountUnfetchedTargets()
+	if err != nil {
+		t.Fatalf("CountUnfetchedTargets error: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("CountUnfetchedTargets() = %d, want 3", count)
+	}
+}
+
+func TestScanGraphData(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	if n, err := c.EstimateLinkCount(); err != nil || n != 0 {
+		t.Errorf("EstimateLinkCount() on empty db = %d, %v, want 0", n, err)
+	}
+
+	a, _ := c.CreatePage("A")
+	c.UpdatePageStatus("A", StatusSuccess, "hash", "")
+	c.AddLinks(a.ID, []Link{{TargetTitle: "B"}, {TargetTitle: "C"}})
+	c.CreatePage("Lonely")
+	c.UpdatePageStatus("Lonely", StatusSuccess, "hash", "")
+	p, _ := c.CreatePage("Pending")
+	c.AddLinks(p.ID, []Link{{TargetTitle: "A"}})
+
+	var edges [][2]string
+	var nodes []string
+	err := c.ScanGraphData(func(source, target string) error {
+		edges = append(edges, [2]string{source, target})
+		return nil
+	}, func(title string) error {
+		nodes = append(nodes, title)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ScanGraphData error: %v", err)
+	}
+	// Links from pages not fetched successfully are left out.
+	if len(edges) != 2 || len(nodes) != 1 || nodes[0] != "Lonely" {
+		t.Errorf("edges, nodes = %v, %v, want A's 2 links and Lonely", edges, nodes)
+	}
+
+	if n, err := c.EstimateLinkCount(); err != nil || n < 3 {
+		t.Errorf("EstimateLinkCount() = %d, %v, want at least 3", n, err)
+	}
+
+	// An error from a callback stops the scan.
+	stop := errors.New("stop")
+	calls := 0
+	err = c.ScanGraphData(func(source, target string) error {
+		calls++
+		return stop
+	}, func(string) error {
+		t.Error("node called after the edge scan stopped")
+		return nil
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("ScanGraphData = %v after %d calls, want stop after 1", err, calls)
+	}
+}
+
+func TestScanGraphDataLeavesDatabaseFree(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	a, _ := c.CreatePage("A")
+	c.UpdatePageStatus("A", StatusSuccess, "hash", "")
+	c.AddLinks(a.ID, []Link{{TargetTitle: "B"}, {TargetTitle: "C"}})
+
+	// Reads and writes made while the cursor is open must not wait for it.
+	calls := 0
+	err := c.ScanGraphData(func(source, target string) error {
+		if calls++; calls > 1 {
+			return nil
+		}
+		done := make(chan error, 1)
+		go func() {
+			if _, err := c.GetPage("A"); err != nil {
+				done <- err
+				return
+			}
+			_, err := c.CreatePage("D")
+			done <- err
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-time.After(5 * time.Second):
+			return errors.New("query blocked by the scan")
+		}
+	}, func(string) error { return nil })
+	if err != nil {
+		t.Fatalf("ScanGraphData error: %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("edge called %d times, want 2", calls)
+	}
+	if p, err := c.GetPage("D"); err != nil || p == nil {
+		t.Errorf("GetPage(D) = %v, %v after the scan", p, err)
+	}
+}
+
+func TestGetRedirects(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	c.CreatePage("USA")
+	c.UpdatePageStatus("USA", StatusRedirect, "", "United States of America")
+	c.CreatePage("United States of America")
+	c.UpdatePageStatus("United States of America", StatusRedirect, "", "United States")
+	c.CreatePage("United States")
+	c.UpdatePageStatus("United States", StatusSuccess, "hash", "")
+
+	redirects, err := c.GetRedirects()
+	if err != nil {
+		t.Fatalf("GetRedirects error: %v", err)
+	}
+	want := map[string]string{
+		"USA":                      "United States of America",
+		"United States of America": "United States",
+	}
+	if len(redirects) != len(want) {
+		t.Fatalf("GetRedirects() = %v, want %v", redirects, want)
+	}
+	for title, target := range want {
+		if redirects[title] != target {
+			t.Errorf("redirects[%q] = %q, want %q", title, redirects[title], target)
+		}
+	}
+}
+
 func TestGetIncomingLinks(t *testing.T) {
 	db, cleanup := setupTestDB(t)
 	defer cleanup()
@@ -261,3 +446,201 @@ func TestDeleteLinksFromPage(t *testing.T) {
 		t.Errorf("got %d links, want 0", len(outgoing))
 	}
 }
+
+func TestReplaceLinks_History(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	t0 := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(24 * time.Hour)
+	t2 := t1.Add(24 * time.Hour)
+	stamp := func(t time.Time) string { return t.Format(time.RFC3339) }
+
+	page, _ := c.CreatePage("A")
+	c.UpdatePageStatus("A", StatusSuccess, "hash", "")
+	c.AddLinks(page.ID, []Link{{TargetTitle: "B"}, {TargetTitle: "C"}})
+	db.Exec(`UPDATE links SET created_at = ?`, stamp(t0))
+	db.Exec(`UPDATE pages SET fetched_at = ?`, stamp(t0))
+
+	position := sql.NullInt64{Int64: 4, Valid: true}
+	if err := c.ReplaceLinks(page.ID, []Link{{TargetTitle: "C", Position: position}, {TargetTitle: "D"}}); err != nil {
+		t.Fatalf("ReplaceLinks error: %v", err)
+	}
+	db.Exec(`UPDATE links SET created_at = ? WHERE target_title = 'D'`, stamp(t2))
+	db.Exec(`UPDATE link_history SET removed_at = ?`, stamp(t2))
+
+	// C was kept, so it still dates from t0 and takes the new position.
+	var created string
+	var got sql.NullInt64
+	db.QueryRow(`SELECT created_at, position FROM links WHERE target_title = 'C'`).Scan(&created, &got)
+	if created != stamp(t0) || got != position {
+		t.Errorf("kept link created_at, position = %s, %v, want %s, %v", created, got, stamp(t0), position)
+	}
+
+	edges := func(at time.Time) map[string]bool {
+		data, err := c.GetGraphDataAt(at)
+		if err != nil {
+			t.Fatalf("GetGraphDataAt error: %v", err)
+		}
+		set := make(map[string]bool)
+		for _, e := range data.Edges {
+			set[e[0]+"->"+e[1]] = true
+		}
+		return set
+	}
+
+	tests := []struct {
+		at   time.Time
+		want []string
+	}{
+		{t0.Add(-time.Hour), nil},
+		{t1, []string{"A->B", "A->C"}},
+		{t2, []string{"A->C", "A->D"}},
+	}
+	for _, tt := range tests {
+		got := edges(tt.at)
+		if len(got) != len(tt.want) {
+			t.Errorf("edges at %s = %v, want %v", stamp(tt.at), got, tt.want)
+			continue
+		}
+		for _, e := range tt.want {
+			if !got[e] {
+				t.Errorf("edges at %s = %v, want %v", stamp(tt.at), got, tt.want)
+			}
+		}
+	}
+
+	if err := c.DeleteLinksFromPage(page.ID); err != nil {
+		t.Fatalf("DeleteLinksFromPage error: %v", err)
+	}
+	if got := edges(t2); len(got) != 2 {
+		t.Errorf("edges at %s after deleting = %v, want the links still recorded", stamp(t2), got)
+	}
+}
+
+func TestGetFirstLink(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	at := func(position int64) sql.NullInt64 {
+		return sql.NullInt64{Int64: position, Valid: true}
+	}
+
+	cat, _ := c.CreatePage("Cat")
+	c.UpdatePageStatus("Cat", StatusSuccess, "hash", "")
+	c.AddLinks(cat.ID, []Link{
+		{TargetTitle: "Hat", Position: at(0)},
+		{TargetTitle: "Latin", Position: at(1), InParens: true, InProse: true},
+		{TargetTitle: "Pet", Position: at(3), InProse: true},
+		{TargetTitle: "Felines", Position: at(2), InProse: true},
+	})
+	c.CreatePage("Felines")
+	c.UpdatePageStatus("Felines", StatusRedirect, "", "Felidae")
+
+	old, _ := c.CreatePage("Old")
+	c.UpdatePageStatus("Old", StatusSuccess, "hash", "")
+	c.AddLinks(old.ID, []Link{{TargetTitle: "Cat"}})
+
+	c.CreatePage("Empty")
+	c.UpdatePageStatus("Empty", StatusSuccess, "hash", "")
+	c.CreatePage("Pending")
+
+	tests := []struct {
+		title  string
+		target string
+		status FirstLinkStatus
+	}{
+		{"Cat", "Felidae", FirstLinkFound},
+		{"Old", "", FirstLinkUnordered},
+		{"Empty", "", FirstLinkNone},
+		{"Pending", "", FirstLinkNotFetched},
+		{"Missing", "", FirstLinkNotFetched},
+	}
+	for _, tt := range tests {
+		target, status, err := c.GetFirstLink(tt.title)
+		if err != nil {
+			t.Fatalf("GetFirstLink(%q) error: %v", tt.title, err)
+		}
+		if target != tt.target || status != tt.status {
+			t.Errorf("GetFirstLink(%q) = %q, %s, want %q, %s", tt.title, target, status, tt.target, tt.status)
+		}
+	}
+
+	if got, _ := c.ResolveRedirect("Felines"); got != "Felidae" {
+		t.Errorf("ResolveRedirect(Felines) = %q, want Felidae", got)
+	}
+	if got, _ := c.ResolveRedirect("Cat"); got != "Cat" {
+		t.Errorf("ResolveRedirect(Cat) = %q, want Cat", got)
+	}
+}
+
+func TestReplaceCommunities(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+	c := New(db)
+
+	assign := func(m map[string]int) func(func(string, int) bool) {
+		return func(yield func(string, int) bool) {
+			for title, id := range m {
+				if !yield(title, id) {
+					return
+				}
+			}
+		}
+	}
+
+	first := map[string]int{"A": 0, "B": 0, "C": 1}
+	if err := c.ReplaceCommunities([]int{2, 1}, assign(first)); err != nil {
+		t.Fatalf("ReplaceCommunities error: %v", err)
+	}
+
+	// A second run replaces the first one entirely.
+	second := map[string]int{"A": 0, "B": 1, "C": 0, "D": 0}
+	if err := c.ReplaceCommunities([]int{3, 1}, assign(second)); err != nil {
+		t.Fatalf("ReplaceCommunities error: %v", err)
+	}
+
+	id, ok, err := c.GetCommunity("B")
+	if err != nil || !ok || id != 1 {
+		t.Errorf("GetCommunity(B) = %d, %v, %v, want 1", id, ok, err)
+	}
+	if _, ok, _ := c.GetCommunity("Unknown"); ok {
+		t.Error("GetCommunity(Unknown) should not be found")
+	}
+
+	got, err := c.GetCommunities([]string{"A", "D", "Unknown"})
+	if err != nil {
+		t.Fatalf("GetCommunities error: %v", err)
+	}
+	if len(got) != 2 || got["A"] != 0 || got["D"] != 0 {
+		t.Errorf("GetCommunities = %v", got)
+	}
+
+	list, err := c.ListCommunities(10, 0)
+	if err != nil {
+		t.Fatalf("ListCommunities error: %v", err)
+	}
+	if len(list) != 2 || list[0] != (CommunitySummary{ID: 0, Size: 3}) {
+		t.Errorf("ListCommunities = %v", list)
+	}
+	if n, _ := c.CountCommunities(); n != 2 {
+		t.Errorf("CountCommunities = %d, want 2", n)
+	}
+
+	if size, ok, _ := c.GetCommunitySize(1); !ok || size != 1 {
+		t.Errorf("GetCommunitySize(1) = %d, %v, want 1", size, ok)
+	}
+	if _, ok, _ := c.GetCommunitySize(7); ok {
+		t.Error("GetCommunitySize(7) should not be found")
+	}
+
+	members, err := c.GetCommunityMembers(0, 2, 1)
+	if err != nil {
+		t.Fatalf("GetCommunityMembers error: %v", err)
+	}
+	if len(members) != 2 || members[0] != "C" || members[1] != "D" {
+		t.Errorf("GetCommunityMembers = %v, want [C D]", members)
+	}
+}
@@ -48,6 +48,10 @@ type APIConfig struct {
 	RateLimit       float64
 	RateBurst       int
 	Production      bool
+
+	// MaxExplored caps the nodes a single graph search may expand.
+	// Zero means no cap.
+	MaxExplored int
 }
 
 type GraphConfig struct {
@@ -65,6 +69,23 @@ type GraphConfig struct {
 
 	// ForceRebuild forces a complete rebuild ignoring any cache.
 	ForceRebuild bool
+
+	// Backend selects the in-memory representation: "pointer" or "csr".
+	// "csr" uses several times less memory and suits graphs above ~10M edges.
+	Backend string
+
+	// PageRank settings: damping factor, convergence tolerance, iteration
+	// cap and dangling-node handling ("uniform", "teleport" or "drop").
+	PageRankDamping       float64
+	PageRankTolerance     float64
+	PageRankMaxIterations int
+	PageRankDangling      string
+
+	// Landmarks is the number of landmark pages whose distance tables
+	// guide ALT path searches; zero disables them. Each landmark costs two
+	// bytes per page. LandmarkStrategy picks them: "degree" or "random".
+	Landmarks        int
+	LandmarkStrategy string
 }
 
 type Neo4jConfig struct {
@@ -120,12 +141,22 @@ var defaultConfig = Config{
 		RateLimit:       100.0,
 		RateBurst:       200,
 		Production:      false,
+		MaxExplored:     5_000_000,
 	},
 	Graph: GraphConfig{
 		CachePath:       "", // Will default to same directory as database
 		MaxCacheAge:     24 * time.Hour,
 		RefreshInterval: 5 * time.Minute,
 		ForceRebuild:    false,
+		Backend:         "pointer",
+
+		PageRankDamping:       0.85,
+		PageRankTolerance:     1e-6,
+		PageRankMaxIterations: 100,
+		PageRankDangling:      "uniform",
+
+		Landmarks:        16,
+		LandmarkStrategy: "degree",
 	},
 	Neo4j: Neo4jConfig{
 		URI:                          "bolt://localhost:7687",
@@ -182,11 +213,19 @@ func Load() (*Config, error) {
 	cfg.API.RateLimit = v.GetFloat64("api.rate_limit")
 	cfg.API.RateBurst = v.GetInt("api.rate_burst")
 	cfg.API.Production = v.GetBool("api.production")
+	cfg.API.MaxExplored = v.GetInt("api.max_explored")
 
 	cfg.Graph.CachePath = v.GetString("graph.cache_path")
 	cfg.Graph.MaxCacheAge = v.GetDuration("graph.max_cache_age")
 	cfg.Graph.RefreshInterval = v.GetDuration("graph.refresh_interval")
 	cfg.Graph.ForceRebuild = v.GetBool("graph.force_rebuild")
+	cfg.Graph.Backend = v.GetString("graph.backend")
+	cfg.Graph.PageRankDamping = v.GetFloat64("graph.pagerank_damping")
+	cfg.Graph.PageRankTolerance = v.GetFloat64("graph.pagerank_tolerance")
+	cfg.Graph.PageRankMaxIterations = v.GetInt("graph.pagerank_max_iterations")
+	cfg.Graph.PageRankDangling = v.GetString("graph.pagerank_dangling")
+	cfg.Graph.Landmarks = v.GetInt("graph.landmarks")
+	cfg.Graph.LandmarkStrategy = v.GetString("graph.landmark_strategy")
 
 	cfg.Neo4j.URI = v.GetString("neo4j.uri")
 	cfg.Neo4j.Username = v.GetString("neo4j.username")
@@ -220,11 +259,19 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("api.rate_limit", defaultConfig.API.RateLimit)
 	v.SetDefault("api.rate_burst", defaultConfig.API.RateBurst)
 	v.SetDefault("api.production", defaultConfig.API.Production)
+	v.SetDefault("api.max_explored", defaultConfig.API.MaxExplored)
 
 	v.SetDefault("graph.cache_path", defaultConfig.Graph.CachePath)
 	v.SetDefault("graph.max_cache_age", defaultConfig.Graph.MaxCacheAge)
 	v.SetDefault("graph.refresh_interval", defaultConfig.Graph.RefreshInterval)
 	v.SetDefault("graph.force_rebuild", defaultConfig.Graph.ForceRebuild)
+	v.SetDefault("graph.backend", defaultConfig.Graph.Backend)
+	v.SetDefault("graph.pagerank_damping", defaultConfig.Graph.PageRankDamping)
+	v.SetDefault("graph.pagerank_tolerance", defaultConfig.Graph.PageRankTolerance)
+	v.SetDefault("graph.pagerank_max_iterations", defaultConfig.Graph.PageRankMaxIterations)
+	v.SetDefault("graph.pagerank_dangling", defaultConfig.Graph.PageRankDangling)
+	v.SetDefault("graph.landmarks", defaultConfig.Graph.Landmarks)
+	v.SetDefault("graph.landmark_strategy", defaultConfig.Graph.LandmarkStrategy)
 
 	v.SetDefault("neo4j.uri", defaultConfig.Neo4j.URI)
 	v.SetDefault("neo4j.username", defaultConfig.Neo4j.Username)
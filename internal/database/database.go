@@ -4,6 +4,7 @@ package database
 import (
 	"database/sql"
 	"embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -18,6 +19,10 @@ var migrationsFS embed.FS
 type DB struct {
 	*sql.DB
 	path string
+
+	// reader is a read-only pool for long scans, so that they do not hold
+	// the single read-write connection.
+	reader *sql.DB
 }
 
 func Open(path string) (*DB, error) {
@@ -63,13 +68,35 @@ func Open(path string) (*DB, error) {
 		return nil, fmt.Errorf("pinging database: %w", err)
 	}
 
-	return &DB{DB: db, path: path}, nil
+	// In WAL mode readers do not block the writer, nor it them. The pool
+	// connects on first use; its pragmas are set on every connection.
+	reader, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=query_only(1)"+
+		"&_pragma=cache_size(-64000)&_pragma=mmap_size(268435456)")
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("opening read-only database: %w", err)
+	}
+	reader.SetMaxOpenConns(1)
+	reader.SetMaxIdleConns(1)
+
+	return &DB{DB: db, path: path, reader: reader}, nil
 }
 
 func (db *DB) Path() string {
 	return db.path
 }
 
+// Reader returns a read-only connection pool separate from db, for scans
+// that last long enough to hold up other queries.
+func (db *DB) Reader() *sql.DB {
+	return db.reader
+}
+
+// Close closes the read-only pool and the database.
+func (db *DB) Close() error {
+	return errors.Join(db.reader.Close(), db.DB.Close())
+}
+
 func (db *DB) Migrate() error {
 	migrations := []struct {
 		version int
@@ -80,6 +107,10 @@ func (db *DB) Migrate() error {
 		{2, "migrations/002_optimization_indexes.sql", "optimization_indexes"},
 		{3, "migrations/003_graph_optimization.sql", "graph_optimization"},
 		{4, "migrations/004_remove_anchor_text.sql", "remove_anchor_text"},
+		{5, "migrations/005_restore_covering_index.sql", "restore_covering_index"},
+		{6, "migrations/006_communities.sql", "communities"},
+		{7, "migrations/007_link_order.sql", "link_order"},
+		{8, "migrations/008_link_history.sql", "link_history"},
 	}
 
 	var currentVersion int
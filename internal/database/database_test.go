@@ -93,8 +93,8 @@ func TestMigrate(t *testing.T) {
 	}
 
 	_, err = db.Exec(`
-		INSERT INTO links (source_id, target_title, anchor_text)
-		VALUES (?, 'Target Page', 'click here')
+		INSERT INTO links (source_id, target_title)
+		VALUES (?, 'Target Page')
 	`, pageID)
 	if err != nil {
 		t.Fatalf("inserting into links: %v", err)
@@ -0,0 +1,6 @@
+-- Restore the covering index dropped when 004 recreated the links table.
+-- GetGraphData forces it with INDEXED BY, so loading fails without it.
+CREATE INDEX IF NOT EXISTS idx_links_source_target_covering
+    ON links(source_id, target_title);
+
+INSERT INTO schema_migrations (version, name) VALUES (5, 'restore_covering_index');
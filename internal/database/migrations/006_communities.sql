@@ -0,0 +1,27 @@
+-- Community detection results
+--
+-- Written in full by 'wikigraph communities', which replaces any earlier
+-- run. Community IDs are ordered by size, so community 0 is the largest.
+--
+-- Design decision: page_communities is keyed by title, not pages.id,
+-- because the graph includes link targets that have no page row yet
+-- (same reasoning as links.target_title).
+
+CREATE TABLE IF NOT EXISTS communities (
+    id           INTEGER PRIMARY KEY,
+    size         INTEGER NOT NULL CHECK(size > 0),
+    computed_at  TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now'))
+);
+
+CREATE TABLE IF NOT EXISTS page_communities (
+    title         TEXT PRIMARY KEY,
+    community_id  INTEGER NOT NULL,
+
+    FOREIGN KEY (community_id) REFERENCES communities(id) ON DELETE CASCADE
+) WITHOUT ROWID;
+
+-- Member listing for the communities endpoint
+CREATE INDEX IF NOT EXISTS idx_page_communities_community
+    ON page_communities(community_id, title);
+
+INSERT INTO schema_migrations (version, name) VALUES (6, 'communities');
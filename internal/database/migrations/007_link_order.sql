@@ -0,0 +1,20 @@
+-- Link order and context for "first link" chains
+--
+-- position orders a page's links as they appear in its HTML. in_parens,
+-- in_italics and in_prose say whether a link sits inside parentheses,
+-- inside italics, and in the article text rather than an infobox, table,
+-- hatnote or navigation box. A page's first link is its lowest-positioned
+-- link in prose outside parentheses and italics.
+--
+-- Links stored before this migration have no position. Clearing the
+-- content hash of fetched pages makes the next refresh rewrite their links
+-- even if the page itself has not changed.
+
+ALTER TABLE links ADD COLUMN position INTEGER;
+ALTER TABLE links ADD COLUMN in_parens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN in_italics INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN in_prose INTEGER NOT NULL DEFAULT 0;
+
+UPDATE pages SET content_hash = NULL WHERE fetch_status = 'success';
+
+INSERT INTO schema_migrations (version, name) VALUES (7, 'link_order');
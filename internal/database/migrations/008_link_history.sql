@@ -0,0 +1,25 @@
+-- Link history for comparing the graph at two points in time
+--
+-- When a page is refetched, the links it no longer has are moved here with
+-- the time they were removed, and the links it keeps are left in place so
+-- their created_at still records when they first appeared. A link existed
+-- at time T if it is in links with created_at <= T, or in link_history
+-- with created_at <= T < removed_at.
+--
+-- Links removed before this migration are not recorded, so the graph as of
+-- an earlier time includes only links that still exist.
+
+CREATE TABLE IF NOT EXISTS link_history (
+    source_id     INTEGER NOT NULL,
+    target_title  TEXT NOT NULL,
+    created_at    TEXT NOT NULL,
+    removed_at    TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+
+    FOREIGN KEY (source_id) REFERENCES pages(id) ON DELETE CASCADE
+);
+
+-- The graph as of a time reads the links removed after it
+CREATE INDEX IF NOT EXISTS idx_link_history_removed_at
+    ON link_history(removed_at);
+
+INSERT INTO schema_migrations (version, name) VALUES (8, 'link_history');
@@ -15,6 +15,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/parser"
+	"github.com/Thinh-nguyen-03/wikigraph/internal/wikititle"
 )
 
 type pendingRequest struct {
@@ -173,7 +174,7 @@ func detectRedirect(originalURL, finalURL string) string {
 		return title
 	}
 
-	return strings.ReplaceAll(decoded, "_", " ")
+	return wikititle.Normalize(decoded)
 }
 
 func hashContent(content string) string {
@@ -0,0 +1,163 @@
+package graph
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// PathPair is one query of a batch path search.
+type PathPair struct {
+	From string
+	To   string
+}
+
+// BatchPaths finds a shortest path for every pair. Pairs are grouped by
+// source, and one breadth-first search from each distinct source answers
+// all of its targets, stopping as soon as the last of them is reached.
+// Distinct sources are searched concurrently, one per core.
+//
+// maxDepth and maxExplored bound each source's search, and every result
+// reports the explored count of the search that answered it. Results are
+// in the order of pairs; a pair naming a page not in the graph is not
+// found.
+func BatchPaths(ctx context.Context, a Adjacency, pairs []PathPair, maxDepth, maxExplored int) []PathResult {
+	results := make([]PathResult, len(pairs))
+
+	bySource := make(map[uint32][]int)
+	var sources []uint32
+	for i, p := range pairs {
+		fromID, okFrom := a.Lookup(p.From)
+		_, okTo := a.Lookup(p.To)
+		if !okFrom || !okTo {
+			continue
+		}
+		if _, ok := bySource[fromID]; !ok {
+			sources = append(sources, fromID)
+		}
+		bySource[fromID] = append(bySource[fromID], i)
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	for range min(runtime.GOMAXPROCS(0), len(sources)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(sources) {
+					return
+				}
+				fromID := sources[i]
+				searchTargets(ctx, a, fromID, pairs, bySource[fromID], results, maxDepth, maxExplored)
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+// searchTargets runs the breadth-first search from fromID for the pairs at
+// indices and fills in their results.
+func searchTargets(ctx context.Context, a Adjacency, fromID uint32, pairs []PathPair, indices []int, results []PathResult, maxDepth, maxExplored int) {
+	targets := make(map[uint32]bool, len(indices))
+	for _, i := range indices {
+		toID, _ := a.Lookup(pairs[i].To)
+		targets[toID] = true
+	}
+	remaining := len(targets)
+	if targets[fromID] {
+		remaining--
+	}
+
+	parent := map[uint32]uint32{fromID: fromID}
+	frontier := []uint32{fromID}
+	b := newBudget(ctx, maxExplored)
+
+search:
+	for depth := 0; remaining > 0 && len(frontier) > 0; depth++ {
+		if maxDepth >= 0 && depth >= maxDepth {
+			break
+		}
+
+		var next []uint32
+		for _, u := range frontier {
+			if !b.spend() {
+				break search
+			}
+			for _, v := range a.Out(u) {
+				if _, seen := parent[v]; seen {
+					continue
+				}
+				parent[v] = u
+				next = append(next, v)
+				if targets[v] {
+					if remaining--; remaining == 0 {
+						break search
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+
+	// Nodes are reached in level order, so a target reached before the
+	// search stopped has its shortest path in parent.
+	for _, i := range indices {
+		toID, _ := a.Lookup(pairs[i].To)
+		r := PathResult{Explored: b.explored}
+		if _, ok := parent[toID]; ok {
+			r.Found = true
+			r.Path = reconstructIndexedPath(a, parent, toID)
+			r.Hops = len(r.Path) - 1
+		} else {
+			r.Truncated = b.stopped()
+			r.BudgetExhausted = b.exhausted
+		}
+		results[i] = r
+	}
+}
+
+// DistanceMatrix lays out the hop counts of a batch as a table with one
+// row per distinct source and one column per distinct target, each in the
+// order it first appears in the batch. Hops is -1 where no path was found
+// or the pair was not asked for.
+type DistanceMatrix struct {
+	Sources []string
+	Targets []string
+	Hops    [][]int
+}
+
+// NewDistanceMatrix builds the matrix for pairs from the results BatchPaths
+// returned for them.
+func NewDistanceMatrix(pairs []PathPair, results []PathResult) *DistanceMatrix {
+	m := &DistanceMatrix{}
+	rows, cols := make(map[string]int), make(map[string]int)
+	for _, p := range pairs {
+		if _, ok := rows[p.From]; !ok {
+			rows[p.From] = len(m.Sources)
+			m.Sources = append(m.Sources, p.From)
+		}
+		if _, ok := cols[p.To]; !ok {
+			cols[p.To] = len(m.Targets)
+			m.Targets = append(m.Targets, p.To)
+		}
+	}
+
+	m.Hops = make([][]int, len(m.Sources))
+	for i := range m.Hops {
+		m.Hops[i] = make([]int, len(m.Targets))
+		for j := range m.Hops[i] {
+			m.Hops[i][j] = -1
+		}
+	}
+	for i, p := range pairs {
+		if results[i].Found {
+			m.Hops[rows[p.From]][cols[p.To]] = results[i].Hops
+		}
+	}
+	return m
+}
@@ -0,0 +1,96 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestBatchPathsMatchesSingleSearches(t *testing.T) {
+	forceParallel(t)
+	ctx := context.Background()
+	c := randomCSR(500, 1500, 3)
+
+	// Few sources with many targets each, as a batch is meant to be used.
+	var pairs []PathPair
+	for i := range 300 {
+		pairs = append(pairs, PathPair{nodeName(i % 7 * 31), nodeName((i*53 + 11) % 500)})
+	}
+	pairs = append(pairs, PathPair{nodeName(0), nodeName(0)})
+
+	results := BatchPaths(ctx, c, pairs, -1, 0)
+	if len(results) != len(pairs) {
+		t.Fatalf("got %d results for %d pairs", len(results), len(pairs))
+	}
+	for i, p := range pairs {
+		want := findPathIndexed(ctx, c, p.From, p.To, -1, 0)
+		got := results[i]
+		if got.Found != want.Found || got.Hops != want.Hops {
+			t.Fatalf("%s -> %s: found %v in %d hops, want %v in %d",
+				p.From, p.To, got.Found, got.Hops, want.Found, want.Hops)
+		}
+		if got.Found && (!isPath(c, got.Path) || got.Path[0] != p.From || got.Path[len(got.Path)-1] != p.To) {
+			t.Fatalf("%s -> %s: %v is not a path between them", p.From, p.To, got.Path)
+		}
+		if !got.Found && got.Truncated {
+			t.Fatalf("%s -> %s: truncated without a limit", p.From, p.To)
+		}
+	}
+
+	// Pairs sharing a source share its search.
+	if results[0].Explored != results[7].Explored {
+		t.Errorf("explored %d and %d for the same source", results[0].Explored, results[7].Explored)
+	}
+}
+
+func TestBatchPathsLimits(t *testing.T) {
+	ctx := context.Background()
+	c := buildChainGraph(10).Compact()
+	pairs := []PathPair{
+		{nodeName(0), nodeName(3)},
+		{nodeName(0), nodeName(9)},
+		{nodeName(0), "Missing"},
+	}
+
+	results := BatchPaths(ctx, c, pairs, 5, 0)
+	if !results[0].Found || results[0].Hops != 3 {
+		t.Errorf("within depth: %+v, want 3 hops", results[0])
+	}
+	if results[1].Found || results[1].Truncated {
+		t.Errorf("beyond depth: %+v, want a plain miss", results[1])
+	}
+	if results[2].Found || results[2].Explored != 0 {
+		t.Errorf("missing page: %+v, want no search", results[2])
+	}
+
+	results = BatchPaths(ctx, c, pairs[:2], -1, 4)
+	if !results[0].Found {
+		t.Errorf("budget: %+v, want the near target found", results[0])
+	}
+	if results[1].Found || !results[1].BudgetExhausted || results[1].Explored != 4 {
+		t.Errorf("budget: %+v, want exhausted after 4 nodes", results[1])
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if r := BatchPaths(canceled, c, pairs[1:2], -1, 0); r[0].Found || !r[0].Truncated {
+		t.Errorf("canceled: %+v, want truncated", r[0])
+	}
+}
+
+func TestNewDistanceMatrix(t *testing.T) {
+	pairs := []PathPair{{"A", "X"}, {"B", "Y"}, {"A", "Y"}}
+	results := []PathResult{{Found: true, Hops: 2}, {}, {Found: true, Hops: 1}}
+
+	m := NewDistanceMatrix(pairs, results)
+	if !equalSlices(m.Sources, []string{"A", "B"}) || !equalSlices(m.Targets, []string{"X", "Y"}) {
+		t.Fatalf("sources %v, targets %v", m.Sources, m.Targets)
+	}
+	want := [][]int{{2, 1}, {-1, -1}}
+	for i := range want {
+		for j := range want[i] {
+			if m.Hops[i][j] != want[i][j] {
+				t.Errorf("Hops = %v, want %v", m.Hops, want)
+			}
+		}
+	}
+}
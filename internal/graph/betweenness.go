@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"context"
+	"math/rand/v2"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// BetweennessOptions configures Betweenness.
+type BetweennessOptions struct {
+	// Samples is the number of source pivots. Zero, or at least the number
+	// of pages, computes exact betweenness from every page.
+	Samples int
+
+	// Workers is the number of pivots processed in parallel.
+	// Defaults to GOMAXPROCS. Each worker holds about 32 bytes per page.
+	Workers int
+
+	// Seed drives the choice of pivots; runs with the same seed on the same
+	// graph give the same result.
+	Seed uint64
+
+	// Progress, if set, is called after each pivot with the number of pivots
+	// done and the total. Calls are serialized.
+	Progress func(done, total int)
+}
+
+// Centrality is a betweenness centrality estimate.
+type Centrality struct {
+	NodeScores
+
+	// Samples is the number of source pivots used.
+	Samples int
+
+	// Exact is set when every page was used as a pivot.
+	Exact bool
+}
+
+// Betweenness estimates the betweenness centrality of every page with
+// Brandes' algorithm: a BFS from each of a random sample of pivots counts
+// the shortest paths through each page, and the totals are scaled up to the
+// whole graph. Scores are normalized by (n-1)(n-2), the number of ordered
+// pairs a page could sit between, so they lie in [0, 1].
+//
+// Returns ctx.Err() if ctx is done before every pivot was processed.
+func Betweenness(ctx context.Context, a Adjacency, opts BetweennessOptions) (*Centrality, error) {
+	n := a.NodeCount()
+
+	var pivots []uint32
+	if opts.Samples <= 0 || opts.Samples >= n {
+		pivots = make([]uint32, n)
+		for i := range pivots {
+			pivots[i] = uint32(i)
+		}
+	} else {
+		pivots = samplePivots(n, opts.Samples, opts.Seed)
+	}
+
+	c := &Centrality{
+		NodeScores: NodeScores{Scores: make([]float64, n), a: a},
+		Samples:    len(pivots),
+		Exact:      len(pivots) == n,
+	}
+	if len(pivots) == 0 {
+		return c, nil
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(pivots))
+
+	var (
+		next     atomic.Int64
+		done     int
+		progress sync.Mutex
+		wg       sync.WaitGroup
+	)
+	partials := make([][]float64, workers)
+
+	for w := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			st := newBrandesState(n)
+			for ctx.Err() == nil {
+				i := next.Add(1) - 1
+				if i >= int64(len(pivots)) {
+					break
+				}
+				st.accumulate(a, pivots[i])
+
+				if opts.Progress != nil {
+					progress.Lock()
+					done++
+					opts.Progress(done, len(pivots))
+					progress.Unlock()
+				}
+			}
+			partials[w] = st.scores
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	scale := float64(n) / float64(len(pivots))
+	if n > 2 {
+		scale /= float64(n-1) * float64(n-2)
+	}
+	for _, partial := range partials {
+		for v, s := range partial {
+			c.Scores[v] += s
+		}
+	}
+	for v := range c.Scores {
+		c.Scores[v] *= scale
+	}
+
+	return c, nil
+}
+
+// samplePivots picks k distinct node IDs out of n.
+func samplePivots(n, k int, seed uint64) []uint32 {
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	ids := make([]uint32, n)
+	for i := range ids {
+		ids[i] = uint32(i)
+	}
+	for i := range k {
+		j := i + rng.IntN(n-i)
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+	return ids[:k]
+}
+
+// brandesState holds one worker's per-page scratch space. Only the pages
+// reached from a pivot are touched and reset, so a pivot costs time
+// proportional to what it reaches rather than to the graph.
+type brandesState struct {
+	dist   []int32   // BFS depth, -1 if unreached
+	sigma  []float64 // number of shortest paths from the pivot
+	delta  []float64 // dependency of the pivot on each page
+	order  []uint32  // pages in BFS order
+	scores []float64 // accumulated betweenness
+}
+
+func newBrandesState(n int) *brandesState {
+	st := &brandesState{
+		dist:   make([]int32, n),
+		sigma:  make([]float64, n),
+		delta:  make([]float64, n),
+		scores: make([]float64, n),
+	}
+	for i := range st.dist {
+		st.dist[i] = -1
+	}
+	return st
+}
+
+// accumulate adds the dependencies of source s to the scores.
+func (st *brandesState) accumulate(a Adjacency, s uint32) {
+	st.order = append(st.order[:0], s)
+	st.dist[s] = 0
+	st.sigma[s] = 1
+
+	for i := 0; i < len(st.order); i++ {
+		v := st.order[i]
+		for _, w := range a.Out(v) {
+			if st.dist[w] < 0 {
+				st.dist[w] = st.dist[v] + 1
+				st.order = append(st.order, w)
+			}
+			if st.dist[w] == st.dist[v]+1 {
+				st.sigma[w] += st.sigma[v]
+			}
+		}
+	}
+
+	// Walk back from the farthest pages, pushing each page's dependency to
+	// its predecessors on shortest paths. Predecessors are found through
+	// the in-links, so no predecessor lists are kept.
+	for i := len(st.order) - 1; i > 0; i-- {
+		w := st.order[i]
+		coeff := (1 + st.delta[w]) / st.sigma[w]
+		for _, v := range a.In(w) {
+			if st.dist[v] == st.dist[w]-1 {
+				st.delta[v] += st.sigma[v] * coeff
+			}
+		}
+		st.scores[w] += st.delta[w]
+	}
+
+	for _, v := range st.order {
+		st.dist[v] = -1
+		st.sigma[v] = 0
+		st.delta[v] = 0
+	}
+}
@@ -0,0 +1,95 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestBetweennessExact(t *testing.T) {
+	// On a directed chain of n pages, page i sits between i*(n-1-i) pairs.
+	g := buildChainGraph(6)
+	a := g.Adjacency()
+
+	c, err := Betweenness(context.Background(), a, BetweennessOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.Exact || c.Samples != 6 {
+		t.Errorf("Exact = %v, Samples = %d, want exact over 6 pivots", c.Exact, c.Samples)
+	}
+	for i := range 6 {
+		want := float64(i*(5-i)) / (5 * 4)
+		if got, _ := c.Score(nodeName(i)); math.Abs(got-want) > 1e-12 {
+			t.Errorf("%s: score = %g, want %g", nodeName(i), got, want)
+		}
+	}
+}
+
+func TestBetweennessSplitsAcrossShortestPaths(t *testing.T) {
+	// A reaches E through B and C equally; D and F form a longer route.
+	c := buildCSR([][2]string{
+		{"A", "B"}, {"A", "C"}, {"B", "E"}, {"C", "E"},
+		{"A", "D"}, {"D", "F"}, {"F", "E"},
+	})
+
+	r, err := Betweenness(context.Background(), c, BetweennessOptions{Workers: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	scale := 1.0 / (5 * 4)
+	for title, want := range map[string]float64{"B": 0.5, "C": 0.5, "D": 1, "F": 1, "A": 0, "E": 0} {
+		if got, _ := r.Score(title); math.Abs(got-want*scale) > 1e-12 {
+			t.Errorf("%s: score = %g, want %g", title, got, want*scale)
+		}
+	}
+}
+
+func TestBetweennessSampled(t *testing.T) {
+	g := buildChainGraph(200)
+	a := g.Adjacency()
+	ctx := context.Background()
+
+	calls := 0
+	opts := BetweennessOptions{Samples: 50, Workers: 4, Seed: 7, Progress: func(done, total int) {
+		calls++
+		if total != 50 || done != calls {
+			t.Errorf("Progress(%d, %d) on call %d", done, total, calls)
+		}
+	}}
+	first, err := Betweenness(ctx, a, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Exact || first.Samples != 50 || calls != 50 {
+		t.Errorf("Exact = %v, Samples = %d, progress calls = %d", first.Exact, first.Samples, calls)
+	}
+
+	opts.Progress = nil
+	opts.Workers = 1
+	second, err := Betweenness(ctx, a, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range first.Scores {
+		if math.Abs(first.Scores[i]-second.Scores[i]) > 1e-12 {
+			t.Fatalf("same seed gave different scores for node %d", i)
+		}
+	}
+
+	// The middle of the chain carries far more paths than its ends.
+	if top := first.Top(1); top[0].Title == nodeName(0) || top[0].Title == nodeName(199) {
+		t.Errorf("top page = %s", top[0].Title)
+	}
+}
+
+func TestBetweennessCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := Betweenness(ctx, buildChainGraph(100).Adjacency(), BetweennessOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
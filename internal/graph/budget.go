@@ -0,0 +1,80 @@
+package graph
+
+import "context"
+
+// cancelCheckInterval is how many nodes a traversal expands between
+// context checks, keeping ctx.Err off the hot path.
+const cancelCheckInterval = 256
+
+// budget bounds a traversal by a context and a cap on expanded nodes.
+// Searches that serve one query, such as the legs of a waypoint path or
+// the spur searches of Yen's algorithm, share a single budget.
+type budget struct {
+	ctx         context.Context
+	maxExplored int
+	explored    int
+	exhausted   bool
+	canceled    bool
+}
+
+// newBudget creates a budget; maxExplored <= 0 means no cap.
+func newBudget(ctx context.Context, maxExplored int) *budget {
+	return &budget{ctx: ctx, maxExplored: maxExplored}
+}
+
+// spend records the expansion of one node. Once the search must stop it
+// returns false without counting the node.
+func (b *budget) spend() bool {
+	if b.exhausted || b.canceled {
+		return false
+	}
+	if b.maxExplored > 0 && b.explored >= b.maxExplored {
+		b.exhausted = true
+		return false
+	}
+	if b.explored%cancelCheckInterval == 0 && b.ctx.Err() != nil {
+		b.canceled = true
+		return false
+	}
+	b.explored++
+	return true
+}
+
+// stopped reports whether the search was cut short.
+func (b *budget) stopped() bool {
+	return b.exhausted || b.canceled
+}
+
+// finish fills in the bookkeeping fields of r.
+func (b *budget) finish(r PathResult) PathResult {
+	r.Explored = b.explored
+	r.Truncated = b.stopped()
+	r.BudgetExhausted = b.exhausted
+	return r
+}
+
+// spendLevel records the expansion of up to n nodes at once, for searches
+// that expand a whole BFS level in parallel, and returns how many of them
+// may be expanded. It returns 0 once the search must stop. Granting fewer
+// than n exhausts the budget, since the rest of the level goes unexpanded.
+func (b *budget) spendLevel(n int) int {
+	if b.exhausted || b.canceled {
+		return 0
+	}
+	if b.ctx.Err() != nil {
+		b.canceled = true
+		return 0
+	}
+	if b.maxExplored > 0 {
+		if b.explored >= b.maxExplored {
+			b.exhausted = true
+			return 0
+		}
+		if left := b.maxExplored - b.explored; n > left {
+			n = left
+			b.exhausted = true
+		}
+	}
+	b.explored += n
+	return n
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSearchBudgetExhausted(t *testing.T) {
+	g := buildChainGraph(1000)
+	views := map[string]View{"pointer": g, "csr": g.Compact()}
+
+	for name, v := range views {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			r := v.FindPathContext(ctx, "node_0", "node_999", -1, 100)
+			if r.Found || !r.Truncated || !r.BudgetExhausted {
+				t.Errorf("FindPathContext = %+v, want truncated by budget", r)
+			}
+			if r.Explored != 100 {
+				t.Errorf("explored %d nodes, want 100", r.Explored)
+			}
+
+			r = v.FindPathBidirectionalContext(ctx, "node_0", "node_999", -1, 100)
+			if r.Found || !r.Truncated || !r.BudgetExhausted {
+				t.Errorf("FindPathBidirectionalContext = %+v, want truncated by budget", r)
+			}
+
+			// A budget large enough to finish changes nothing.
+			r = v.FindPathContext(ctx, "node_0", "node_999", -1, 5000)
+			if !r.Found || r.Truncated || r.Hops != 999 {
+				t.Errorf("FindPathContext with ample budget = %+v", r)
+			}
+
+			sub := v.GetNeighborhoodContext(ctx, "node_0", 500, 10000, 10)
+			if !sub.Truncated || !sub.BudgetExhausted || len(sub.Nodes) != 11 {
+				t.Errorf("neighborhood: %d nodes, truncated=%v, exhausted=%v",
+					len(sub.Nodes), sub.Truncated, sub.BudgetExhausted)
+			}
+		})
+	}
+}
+
+func TestSearchCanceled(t *testing.T) {
+	g := buildChainGraph(1000)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for name, v := range map[string]View{"pointer": g, "csr": g.Compact()} {
+		r := v.FindPathContext(ctx, "node_0", "node_999", -1, 0)
+		if r.Found || !r.Truncated || r.BudgetExhausted {
+			t.Errorf("%s: FindPathContext = %+v, want canceled", name, r)
+		}
+		if sub := v.GetNeighborhoodContext(ctx, "node_0", 3, 100, 0); !sub.Truncated {
+			t.Errorf("%s: neighborhood should be truncated", name)
+		}
+	}
+
+	a := g.Adjacency()
+	if r := KShortestPaths(ctx, a, "node_0", "node_999", 3, -1, 0); r.Found || !r.Truncated {
+		t.Errorf("KShortestPaths = %+v, want canceled", r)
+	}
+	if r := AllShortestPaths(ctx, a, "node_0", "node_999", -1, 0, 0); r.Found || !r.Truncated {
+		t.Errorf("AllShortestPaths = %+v, want canceled", r)
+	}
+	if r := FindPathWithOptions(ctx, a, "node_0", "node_999", PathOptions{}); r.Found || !r.Truncated {
+		t.Errorf("FindPathWithOptions = %+v, want canceled", r)
+	}
+}
@@ -0,0 +1,65 @@
+package graph
+
+// LinkChain is the route taken by repeatedly following one link out of
+// each page, as in "Getting to Philosophy" with a page's first link.
+type LinkChain struct {
+	// Path holds the start page, then each page reached in turn. A page
+	// that closes a cycle is not repeated.
+	Path []string
+	// CycleAt is the index in Path where the cycle the chain ended in
+	// begins, or -1 if it ended without one.
+	CycleAt int
+	// Truncated reports that the chain stopped after the step limit.
+	Truncated bool
+}
+
+// FollowChain walks from start, asking next for the successor of each page,
+// until a page repeats, next reports that a page has none, or maxSteps
+// steps have been taken (maxSteps <= 0 means no limit).
+func FollowChain(start string, maxSteps int, next func(title string) (string, bool, error)) (*LinkChain, error) {
+	chain := &LinkChain{Path: []string{start}, CycleAt: -1}
+	index := map[string]int{start: 0}
+
+	for title := start; ; {
+		if maxSteps > 0 && len(chain.Path)-1 >= maxSteps {
+			chain.Truncated = true
+			return chain, nil
+		}
+		succ, ok, err := next(title)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return chain, nil
+		}
+		if i, seen := index[succ]; seen {
+			chain.CycleAt = i
+			return chain, nil
+		}
+		index[succ] = len(chain.Path)
+		chain.Path = append(chain.Path, succ)
+		title = succ
+	}
+}
+
+// ConvergesTo returns the page the chain settles on: the first page of its
+// cycle, or the last page reached if it ended without one.
+func (c *LinkChain) ConvergesTo() string {
+	if c.CycleAt >= 0 {
+		return c.Path[c.CycleAt]
+	}
+	return c.Path[len(c.Path)-1]
+}
+
+// Cycle returns the pages of the cycle the chain ended in, in order, or nil.
+func (c *LinkChain) Cycle() []string {
+	if c.CycleAt < 0 {
+		return nil
+	}
+	return c.Path[c.CycleAt:]
+}
+
+// Steps returns the number of links followed.
+func (c *LinkChain) Steps() int {
+	return len(c.Path) - 1
+}
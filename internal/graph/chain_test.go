@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFollowChain(t *testing.T) {
+	first := map[string]string{
+		"Cat":        "Mammal",
+		"Mammal":     "Animal",
+		"Animal":     "Philosophy",
+		"Philosophy": "Knowledge",
+		"Knowledge":  "Philosophy",
+		"Self":       "Self",
+		"Stub":       "Nowhere",
+	}
+	next := func(title string) (string, bool, error) {
+		succ, ok := first[title]
+		return succ, ok, nil
+	}
+
+	tests := []struct {
+		start       string
+		maxSteps    int
+		path        []string
+		cycle       []string
+		convergesTo string
+		truncated   bool
+	}{
+		{"Cat", 0, []string{"Cat", "Mammal", "Animal", "Philosophy", "Knowledge"}, []string{"Philosophy", "Knowledge"}, "Philosophy", false},
+		{"Self", 0, []string{"Self"}, []string{"Self"}, "Self", false},
+		{"Stub", 0, []string{"Stub", "Nowhere"}, nil, "Nowhere", false},
+		{"Cat", 2, []string{"Cat", "Mammal", "Animal"}, nil, "Animal", true},
+	}
+	for _, tt := range tests {
+		chain, err := FollowChain(tt.start, tt.maxSteps, next)
+		if err != nil {
+			t.Fatalf("FollowChain(%s) error: %v", tt.start, err)
+		}
+		if !equalSlices(chain.Path, tt.path) || !equalSlices(chain.Cycle(), tt.cycle) {
+			t.Errorf("FollowChain(%s, %d) = %v cycle %v, want %v cycle %v",
+				tt.start, tt.maxSteps, chain.Path, chain.Cycle(), tt.path, tt.cycle)
+		}
+		if chain.ConvergesTo() != tt.convergesTo || chain.Truncated != tt.truncated {
+			t.Errorf("FollowChain(%s, %d) converges to %s, truncated %v, want %s, %v",
+				tt.start, tt.maxSteps, chain.ConvergesTo(), chain.Truncated, tt.convergesTo, tt.truncated)
+		}
+		if chain.Steps() != len(tt.path)-1 {
+			t.Errorf("Steps = %d, want %d", chain.Steps(), len(tt.path)-1)
+		}
+	}
+
+	boom := errors.New("boom")
+	if _, err := FollowChain("Cat", 0, func(string) (string, bool, error) { return "", false, boom }); !errors.Is(err, boom) {
+		t.Errorf("error = %v, want %v", err, boom)
+	}
+}
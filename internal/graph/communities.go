@@ -0,0 +1,381 @@
+package graph
+
+import (
+	"context"
+	"iter"
+	"math/rand/v2"
+	"slices"
+)
+
+// CommunityOptions configures DetectCommunities. Zero fields take the defaults.
+type CommunityOptions struct {
+	// ReciprocalWeight is the weight of a pair of pages that link to each
+	// other; a one-way link weighs 1. Default 2.
+	ReciprocalWeight float64
+
+	// Resolution scales the modularity penalty for large communities.
+	// Values above 1 give more, smaller communities. Default 1.
+	Resolution float64
+
+	// MaxPasses bounds the local-moving passes per level. Default 20.
+	MaxPasses int
+
+	// Seed drives the order pages are visited in; runs with the same seed
+	// on the same graph give the same communities.
+	Seed uint64
+}
+
+func (o CommunityOptions) withDefaults() CommunityOptions {
+	if o.ReciprocalWeight == 0 {
+		o.ReciprocalWeight = 2
+	}
+	if o.Resolution == 0 {
+		o.Resolution = 1
+	}
+	if o.MaxPasses == 0 {
+		o.MaxPasses = 20
+	}
+	return o
+}
+
+// Communities assigns every page to a community.
+// Community IDs are ordered by size, so community 0 is the largest.
+type Communities struct {
+	comm  []uint32 // community of each node
+	sizes []uint32 // number of pages in each community
+
+	// Modularity of the partition on the weighted undirected graph.
+	Modularity float64
+
+	// Levels is the number of aggregation levels Louvain went through.
+	Levels int
+
+	a Adjacency
+}
+
+// Count returns the number of communities.
+func (c *Communities) Count() int {
+	return len(c.sizes)
+}
+
+// Size returns the number of pages in a community.
+func (c *Communities) Size(id int) int {
+	return int(c.sizes[id])
+}
+
+// Community returns the community of a page.
+func (c *Communities) Community(title string) (int, bool) {
+	id, ok := c.a.Lookup(title)
+	if !ok {
+		return 0, false
+	}
+	return int(c.comm[id]), true
+}
+
+// Assignments yields every page with its community, in title order.
+func (c *Communities) Assignments() iter.Seq2[string, int] {
+	return func(yield func(string, int) bool) {
+		for id, comm := range c.comm {
+			if !yield(c.a.Title(uint32(id)), int(comm)) {
+				return
+			}
+		}
+	}
+}
+
+// DetectCommunities clusters a with the Louvain method, treating links as
+// undirected: each level moves pages greedily between neighbouring
+// communities while modularity improves, then collapses every community
+// into a single node for the next level. Pages that link to each other are
+// joined more strongly than one-way links.
+//
+// Returns ctx.Err() if ctx is done first.
+func DetectCommunities(ctx context.Context, a Adjacency, opts CommunityOptions) (*Communities, error) {
+	opts = opts.withDefaults()
+	n := a.NodeCount()
+
+	wg := undirectedGraph(a, opts.ReciprocalWeight)
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed^0x9e3779b97f4a7c15))
+
+	// membership maps every page to its node in the current level.
+	membership := make([]uint32, n)
+	for i := range membership {
+		membership[i] = uint32(i)
+	}
+
+	levels := 0
+	for {
+		comm, moved, err := wg.localMoving(ctx, opts, rng)
+		if err != nil {
+			return nil, err
+		}
+		if !moved {
+			break
+		}
+		levels++
+
+		count := renumber(comm)
+		for i, node := range membership {
+			membership[i] = comm[node]
+		}
+		if count == wg.nodeCount() {
+			break
+		}
+		wg = wg.aggregate(comm, count)
+	}
+
+	c := &Communities{comm: membership, Levels: levels, a: a}
+	c.orderBySize()
+	c.Modularity = undirectedGraph(a, opts.ReciprocalWeight).modularity(c.comm, opts.Resolution)
+	return c, nil
+}
+
+// orderBySize renumbers communities so that larger ones come first.
+func (c *Communities) orderBySize() {
+	count := 0
+	for _, comm := range c.comm {
+		count = max(count, int(comm)+1)
+	}
+	sizes := make([]uint32, count)
+	for _, comm := range c.comm {
+		sizes[comm]++
+	}
+
+	order := make([]uint32, count)
+	for i := range order {
+		order[i] = uint32(i)
+	}
+	slices.SortStableFunc(order, func(x, y uint32) int {
+		return int(sizes[y]) - int(sizes[x])
+	})
+
+	rename := make([]uint32, count)
+	c.sizes = make([]uint32, count)
+	for newID, oldID := range order {
+		rename[oldID] = uint32(newID)
+		c.sizes[newID] = sizes[oldID]
+	}
+	for i, comm := range c.comm {
+		c.comm[i] = rename[comm]
+	}
+}
+
+// renumber relabels comm in place to 0..count-1 and returns count.
+func renumber(comm []uint32) int {
+	ids := make(map[uint32]uint32)
+	for i, c := range comm {
+		id, ok := ids[c]
+		if !ok {
+			id = uint32(len(ids))
+			ids[c] = id
+		}
+		comm[i] = id
+	}
+	return len(ids)
+}
+
+// weightedGraph is a weighted undirected graph in CSR form. Every edge is
+// stored in both directions; self-loops, which appear once communities are
+// collapsed, are kept apart in self.
+type weightedGraph struct {
+	offs     []int
+	adj      []uint32
+	weights  []float64
+	self     []float64
+	strength []float64 // weighted degree, with self-loops counted twice
+	total    float64   // sum of strengths, twice the total edge weight
+}
+
+func (g *weightedGraph) nodeCount() int {
+	return len(g.strength)
+}
+
+// undirectedGraph folds the links of a into undirected edges, weighting
+// pairs that link both ways by reciprocal and ignoring self-links.
+func undirectedGraph(a Adjacency, reciprocal float64) *weightedGraph {
+	n := a.NodeCount()
+	g := &weightedGraph{
+		offs:     make([]int, n+1),
+		self:     make([]float64, n),
+		strength: make([]float64, n),
+	}
+
+	// dir records which directions link u and v: 1 for u→v, 2 for v→u.
+	dir := make([]uint8, n)
+	var neighbors []uint32
+
+	for u := range n {
+		neighbors = neighbors[:0]
+		for _, v := range a.Out(uint32(u)) {
+			if v != uint32(u) && dir[v] == 0 {
+				neighbors = append(neighbors, v)
+			}
+			dir[v] |= 1
+		}
+		for _, v := range a.In(uint32(u)) {
+			if v != uint32(u) && dir[v] == 0 {
+				neighbors = append(neighbors, v)
+			}
+			dir[v] |= 2
+		}
+		dir[u] = 0
+
+		for _, v := range neighbors {
+			w := 1.0
+			if dir[v] == 3 {
+				w = reciprocal
+			}
+			dir[v] = 0
+			g.adj = append(g.adj, v)
+			g.weights = append(g.weights, w)
+			g.strength[u] += w
+		}
+		g.offs[u+1] = len(g.adj)
+		g.total += g.strength[u]
+	}
+	return g
+}
+
+// localMoving runs the first phase of a Louvain level: visit the nodes in
+// random order, moving each into the neighbouring community with the best
+// modularity gain, until a pass moves nothing. Reports whether any node
+// left its own singleton community.
+func (g *weightedGraph) localMoving(ctx context.Context, opts CommunityOptions, rng *rand.Rand) ([]uint32, bool, error) {
+	n := g.nodeCount()
+	comm := make([]uint32, n)
+	tot := make([]float64, n)
+	for i := range comm {
+		comm[i] = uint32(i)
+		tot[i] = g.strength[i]
+	}
+	if g.total == 0 {
+		return comm, false, nil
+	}
+
+	order := rng.Perm(n)
+	linkWeight := make([]float64, n) // weight from the current node to each community
+	var touched []uint32
+	movedAny := false
+
+	for range opts.MaxPasses {
+		moved := 0
+		for k, i := range order {
+			if k%cancelCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, false, err
+				}
+			}
+
+			old := comm[i]
+			touched = append(touched[:0], old)
+			for e := g.offs[i]; e < g.offs[i+1]; e++ {
+				c := comm[g.adj[e]]
+				if linkWeight[c] == 0 && c != old {
+					touched = append(touched, c)
+				}
+				linkWeight[c] += g.weights[e]
+			}
+
+			ki := g.strength[i]
+			tot[old] -= ki
+
+			// Stay put on a tie, so passes cannot cycle between equals.
+			best, bestGain := old, linkWeight[old]-opts.Resolution*tot[old]*ki/g.total
+			for _, c := range touched[1:] {
+				gain := linkWeight[c] - opts.Resolution*tot[c]*ki/g.total
+				if gain > bestGain || (gain == bestGain && best != old && c < best) {
+					best, bestGain = c, gain
+				}
+			}
+
+			tot[best] += ki
+			comm[i] = best
+			if best != old {
+				moved++
+			}
+			for _, c := range touched {
+				linkWeight[c] = 0
+			}
+		}
+		if moved == 0 {
+			break
+		}
+		movedAny = true
+	}
+
+	return comm, movedAny, nil
+}
+
+// aggregate collapses each community into a single node. comm must be
+// numbered 0..count-1.
+func (g *weightedGraph) aggregate(comm []uint32, count int) *weightedGraph {
+	members := make([][]uint32, count)
+	for i, c := range comm {
+		members[c] = append(members[c], uint32(i))
+	}
+
+	agg := &weightedGraph{
+		offs:     make([]int, count+1),
+		self:     make([]float64, count),
+		strength: make([]float64, count),
+		total:    g.total,
+	}
+	linkWeight := make([]float64, count)
+	var touched []uint32
+
+	for c, nodes := range members {
+		touched = touched[:0]
+		for _, i := range nodes {
+			agg.self[c] += g.self[i]
+			agg.strength[c] += g.strength[i]
+			for e := g.offs[i]; e < g.offs[i+1]; e++ {
+				d := comm[g.adj[e]]
+				if d == uint32(c) {
+					// Internal edges are seen from both ends.
+					agg.self[c] += g.weights[e] / 2
+					continue
+				}
+				if linkWeight[d] == 0 {
+					touched = append(touched, d)
+				}
+				linkWeight[d] += g.weights[e]
+			}
+		}
+		for _, d := range touched {
+			agg.adj = append(agg.adj, d)
+			agg.weights = append(agg.weights, linkWeight[d])
+			linkWeight[d] = 0
+		}
+		agg.offs[c+1] = len(agg.adj)
+	}
+	return agg
+}
+
+// modularity returns the modularity of the partition comm of g.
+func (g *weightedGraph) modularity(comm []uint32, resolution float64) float64 {
+	if g.total == 0 {
+		return 0
+	}
+
+	count := 0
+	for _, c := range comm {
+		count = max(count, int(c)+1)
+	}
+	tot := make([]float64, count)
+	internal := 0.0
+	for i := range comm {
+		tot[comm[i]] += g.strength[i]
+		internal += 2 * g.self[i]
+		for e := g.offs[i]; e < g.offs[i+1]; e++ {
+			if comm[g.adj[e]] == comm[i] {
+				internal += g.weights[e]
+			}
+		}
+	}
+
+	q := internal / g.total
+	for _, t := range tot {
+		q -= resolution * (t / g.total) * (t / g.total)
+	}
+	return q
+}
@@ -0,0 +1,114 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// twoCliqueGraph links every pair within {A1..A4} and within {B1..B4} both
+// ways, with a single one-way bridge from A1 to B1.
+func twoCliqueGraph() *CSR {
+	var edges [][2]string
+	for _, group := range [][]string{{"A1", "A2", "A3", "A4"}, {"B1", "B2", "B3", "B4"}} {
+		for _, u := range group {
+			for _, v := range group {
+				if u != v {
+					edges = append(edges, [2]string{u, v})
+				}
+			}
+		}
+	}
+	edges = append(edges, [2]string{"A1", "B1"})
+	return buildCSR(edges)
+}
+
+func TestDetectCommunities(t *testing.T) {
+	c := twoCliqueGraph()
+
+	comms, err := DetectCommunities(context.Background(), c, CommunityOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comms.Count() != 2 {
+		t.Fatalf("Count() = %d, want 2", comms.Count())
+	}
+	if comms.Size(0) != 4 || comms.Size(1) != 4 {
+		t.Errorf("sizes = %d, %d, want 4, 4", comms.Size(0), comms.Size(1))
+	}
+
+	a, _ := comms.Community("A1")
+	b, _ := comms.Community("B1")
+	if a == b {
+		t.Error("the two cliques share a community")
+	}
+	for _, title := range []string{"A2", "A3", "A4"} {
+		if got, _ := comms.Community(title); got != a {
+			t.Errorf("%s is in community %d, want %d", title, got, a)
+		}
+	}
+	if comms.Modularity < 0.4 {
+		t.Errorf("Modularity = %g, want above 0.4", comms.Modularity)
+	}
+
+	count := 0
+	for title, id := range comms.Assignments() {
+		if want, _ := comms.Community(title); id != want {
+			t.Errorf("Assignments: %s in %d, want %d", title, id, want)
+		}
+		count++
+	}
+	if count != c.NodeCount() {
+		t.Errorf("Assignments yielded %d pages, want %d", count, c.NodeCount())
+	}
+}
+
+func TestUndirectedGraphReciprocalWeight(t *testing.T) {
+	// X links both ways with P but only one way with each of Q and R.
+	c := buildCSR([][2]string{
+		{"X", "P"}, {"P", "X"}, {"X", "Q"}, {"X", "R"},
+		{"P", "P2"}, {"P2", "P"}, {"Q", "R"}, {"R", "Q"},
+	})
+
+	g := undirectedGraph(c, 3)
+	x, _ := c.Lookup("X")
+	if g.strength[x] != 5 {
+		t.Errorf("strength of X = %g, want 3 + 1 + 1", g.strength[x])
+	}
+	if g.total != 2*(3+1+1+3+3) {
+		t.Errorf("total = %g", g.total)
+	}
+}
+
+func TestDetectCommunitiesDeterministic(t *testing.T) {
+	g := buildChainGraph(300)
+	ctx := context.Background()
+
+	first, err := DetectCommunities(ctx, g.Adjacency(), CommunityOptions{Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := DetectCommunities(ctx, g.Compact(), CommunityOptions{Seed: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Count() < 2 || first.Count() != second.Count() {
+		t.Fatalf("Count() = %d and %d", first.Count(), second.Count())
+	}
+	for i := range 300 {
+		x, _ := first.Community(nodeName(i))
+		y, _ := second.Community(nodeName(i))
+		if x != y {
+			t.Fatalf("%s: community %d != %d", nodeName(i), x, y)
+		}
+	}
+}
+
+func TestDetectCommunitiesCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := DetectCommunities(ctx, twoCliqueGraph(), CommunityOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,220 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+const noComponent = ^uint32(0)
+
+// Components is the strongly connected component decomposition of a graph.
+//
+// Component IDs are assigned in reverse topological order of the
+// condensation: a link between two components always points from the
+// higher ID to the lower one. This makes MayReach an O(1) test.
+type Components struct {
+	comp  []uint32 // component ID of each node
+	sizes []uint32 // number of nodes in each component
+
+	a Adjacency
+}
+
+// ComponentInfo describes one strongly connected component.
+type ComponentInfo struct {
+	ID   int
+	Size int
+
+	// Example is the alphabetically first page of the component.
+	Example string
+}
+
+// StronglyConnectedComponents computes the SCCs of a with an iterative
+// Tarjan's algorithm, so arbitrarily long link chains cannot overflow the
+// stack. Returns ctx.Err() if ctx is done first; it is checked as pages
+// are finished, so a single giant component can be canceled too.
+func StronglyConnectedComponents(ctx context.Context, a Adjacency) (*Components, error) {
+	n := a.NodeCount()
+
+	index := make([]uint32, n)
+	low := make([]uint32, n)
+	comp := make([]uint32, n)
+	for i := range index {
+		index[i] = noComponent
+		comp[i] = noComponent
+	}
+
+	type frame struct {
+		v    uint32
+		next int // position in Out(v) of the next link to follow
+	}
+	var (
+		calls   []frame
+		stack   []uint32
+		sizes   []uint32
+		counter uint32
+		done    int
+	)
+
+	visit := func(v uint32) {
+		index[v] = counter
+		low[v] = counter
+		counter++
+		stack = append(stack, v)
+		calls = append(calls, frame{v: v})
+	}
+
+	for root := range n {
+		if index[root] != noComponent {
+			continue
+		}
+
+		visit(uint32(root))
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			v := f.v
+
+			if out := a.Out(v); f.next < len(out) {
+				w := out[f.next]
+				f.next++
+				if index[w] == noComponent {
+					visit(w)
+				} else if comp[w] == noComponent {
+					// w is still on the stack, so it is in v's component.
+					low[v] = min(low[v], index[w])
+				}
+				continue
+			}
+
+			// Every link of v is done: v either roots a component or
+			// passes its lowlink up to its caller.
+			if done%cancelCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+			done++
+			calls = calls[:len(calls)-1]
+			if low[v] == index[v] {
+				id := uint32(len(sizes))
+				size := uint32(0)
+				for {
+					w := stack[len(stack)-1]
+					stack = stack[:len(stack)-1]
+					comp[w] = id
+					size++
+					if w == v {
+						break
+					}
+				}
+				sizes = append(sizes, size)
+			}
+			if len(calls) > 0 {
+				parent := calls[len(calls)-1].v
+				low[parent] = min(low[parent], low[v])
+			}
+		}
+	}
+
+	return &Components{comp: comp, sizes: sizes, a: a}, nil
+}
+
+// Count returns the number of components.
+func (c *Components) Count() int {
+	return len(c.sizes)
+}
+
+// Component returns the component ID of a page.
+func (c *Components) Component(title string) (int, bool) {
+	id, ok := c.a.Lookup(title)
+	if !ok {
+		return 0, false
+	}
+	return int(c.comp[id]), true
+}
+
+// Size returns the number of pages in a component.
+func (c *Components) Size(id int) int {
+	return int(c.sizes[id])
+}
+
+// MayReach reports whether a path from one page to another can exist.
+// False means there is certainly none; true means both pages are in the
+// same component, where a path always exists, or that a search is needed.
+// Unknown pages are never reachable.
+func (c *Components) MayReach(from, to string) bool {
+	fromID, ok := c.a.Lookup(from)
+	if !ok {
+		return false
+	}
+	toID, ok := c.a.Lookup(to)
+	if !ok {
+		return false
+	}
+	return c.comp[toID] <= c.comp[fromID]
+}
+
+// Singletons returns the number of components with a single page.
+func (c *Components) Singletons() int {
+	count := 0
+	for _, size := range c.sizes {
+		if size == 1 {
+			count++
+		}
+	}
+	return count
+}
+
+// Largest returns the n largest components, biggest first. Components of
+// the same size are ordered by their alphabetically first page.
+func (c *Components) Largest(n int) []ComponentInfo {
+	ids := make([]int, len(c.sizes))
+	for i := range ids {
+		ids[i] = i
+	}
+	slices.SortStableFunc(ids, func(x, y int) int {
+		return int(c.sizes[y]) - int(c.sizes[x])
+	})
+	n = min(n, len(ids))
+	if n <= 0 {
+		return []ComponentInfo{}
+	}
+
+	// Every component as big as the nth could be among the n, depending
+	// on its first page.
+	end := n
+	for end < len(ids) && c.sizes[ids[end]] == c.sizes[ids[n-1]] {
+		end++
+	}
+	ids = ids[:end]
+
+	// Node IDs need not follow title order, as in an Overlay with added
+	// pages, so every member's title is compared.
+	examples := make([]string, len(c.sizes))
+	want := make([]bool, len(c.sizes))
+	for _, id := range ids {
+		want[id] = true
+	}
+	seen := make([]bool, len(c.sizes))
+	for node, id := range c.comp {
+		if !want[id] {
+			continue
+		}
+		if title := c.a.Title(uint32(node)); !seen[id] || title < examples[id] {
+			examples[id], seen[id] = title, true
+		}
+	}
+
+	slices.SortStableFunc(ids, func(x, y int) int {
+		if c.sizes[x] != c.sizes[y] {
+			return int(c.sizes[y]) - int(c.sizes[x])
+		}
+		return strings.Compare(examples[x], examples[y])
+	})
+
+	infos := make([]ComponentInfo, n)
+	for i, id := range ids[:n] {
+		infos[i] = ComponentInfo{ID: id, Size: int(c.sizes[id]), Example: examples[id]}
+	}
+	return infos
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	// Two cycles, the first linking into the second, plus a dangling page.
+	c := buildCSR([][2]string{
+		{"A", "B"}, {"B", "C"}, {"C", "A"},
+		{"C", "D"},
+		{"D", "E"}, {"E", "D"},
+		{"E", "F"},
+	})
+
+	sccs, err := StronglyConnectedComponents(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sccs.Count() != 3 {
+		t.Fatalf("Count() = %d, want 3", sccs.Count())
+	}
+	if sccs.Singletons() != 1 {
+		t.Errorf("Singletons() = %d, want 1", sccs.Singletons())
+	}
+
+	same := func(x, y string) bool {
+		cx, _ := sccs.Component(x)
+		cy, _ := sccs.Component(y)
+		return cx == cy
+	}
+	if !same("A", "C") || !same("D", "E") || same("A", "D") || same("E", "F") {
+		t.Error("pages grouped into the wrong components")
+	}
+
+	largest := sccs.Largest(2)
+	if len(largest) != 2 || largest[0].Size != 3 || largest[0].Example != "A" || largest[1].Example != "D" {
+		t.Errorf("Largest(2) = %+v", largest)
+	}
+
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{"A", "C", true},
+		{"A", "F", true},
+		{"D", "A", false},
+		{"F", "E", false},
+		{"A", "Nowhere", false},
+	}
+	for _, tt := range tests {
+		if got := sccs.MayReach(tt.from, tt.to); got != tt.want {
+			t.Errorf("MayReach(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestStronglyConnectedComponentsDeepCycle(t *testing.T) {
+	// A cycle this long would overflow a recursive implementation's stack.
+	g := buildChainGraph(200000)
+	g.AddEdge(nodeName(199999), nodeName(0))
+
+	sccs, err := StronglyConnectedComponents(context.Background(), g.Adjacency())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sccs.Count() != 1 || sccs.Size(0) != 200000 {
+		t.Errorf("Count() = %d, want a single component of every page", sccs.Count())
+	}
+}
+
+func TestStronglyConnectedComponentsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := StronglyConnectedComponents(ctx, buildChainGraph(10).Adjacency()); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestLargestComponentsOfOverlay(t *testing.T) {
+	// A, D and E are added after the base, so their IDs come after X and Y
+	// although their titles sort first.
+	o := NewOverlay(buildCSR([][2]string{{"B", "C"}, {"C", "B"}, {"X", "Y"}, {"Y", "X"}})).
+		WithOutLinks(map[string][]string{"A": {"B"}, "B": {"A", "C"}, "D": {"E"}, "E": {"D"}})
+
+	sccs, err := StronglyConnectedComponents(context.Background(), o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	largest := sccs.Largest(2)
+	if len(largest) != 2 || largest[0].Size != 3 || largest[0].Example != "A" ||
+		largest[1].Size != 2 || largest[1].Example != "D" {
+		t.Errorf("Largest(2) = %+v, want A's component of 3, then D's of 2", largest)
+	}
+	if got := sccs.Largest(0); len(got) != 0 {
+		t.Errorf("Largest(0) = %+v", got)
+	}
+}
+
+// cancelAfter is a context whose Err reports cancellation from its nth call
+// on, so a computation is canceled part-way through.
+type cancelAfter struct {
+	context.Context
+	calls, n int
+}
+
+func (c *cancelAfter) Err() error {
+	if c.calls++; c.calls >= c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestStronglyConnectedComponentsCanceledInsideComponent(t *testing.T) {
+	// A single cycle is a single root, so only checks during the
+	// traversal can see the cancellation.
+	g := buildChainGraph(10 * cancelCheckInterval)
+	g.AddEdge(nodeName(10*cancelCheckInterval-1), nodeName(0))
+
+	ctx := &cancelAfter{Context: context.Background(), n: 2}
+	if _, err := StronglyConnectedComponents(ctx, g.Adjacency()); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,147 @@
+package graph
+
+import (
+	"context"
+	"regexp"
+)
+
+// PathOptions constrains a path search. The zero value places no constraints.
+//
+// Filters apply only to intermediate pages: the endpoints and waypoints are
+// always allowed, even if they match an exclusion.
+type PathOptions struct {
+	// MaxDepth limits the total number of hops. Zero or negative means no limit.
+	MaxDepth int
+
+	// MaxExplored caps the number of nodes expanded across all legs.
+	// Zero means no cap.
+	MaxExplored int
+
+	// Bidirectional searches each leg from both ends.
+	Bidirectional bool
+
+	// Exclude lists pages the path must not pass through.
+	Exclude []string
+
+	// ExcludePatterns rejects pages whose title matches any of the patterns.
+	ExcludePatterns []*regexp.Regexp
+
+	// Waypoints lists pages the path must visit, in order. Each leg between
+	// consecutive stops is a shortest constrained path on its own, so the
+	// full path can revisit a page across legs.
+	Waypoints []string
+
+	// MaxDegree skips hub pages with more than this many out-links.
+	// Zero means no cap.
+	MaxDegree int
+}
+
+// HasConstraints reports whether opts filters pages or requires waypoints.
+// MaxDepth and Bidirectional do not count as constraints.
+func (o *PathOptions) HasConstraints() bool {
+	return o.hasFilters() || len(o.Waypoints) > 0
+}
+
+func (o *PathOptions) hasFilters() bool {
+	return len(o.Exclude) > 0 || len(o.ExcludePatterns) > 0 || o.MaxDegree > 0
+}
+
+// skipFunc compiles the filters into a node predicate for a. The stops are
+// never skipped. Returns nil when there is nothing to filter.
+func (o *PathOptions) skipFunc(a Adjacency, stops []uint32) func(uint32) bool {
+	if !o.hasFilters() {
+		return nil
+	}
+
+	allowed := make(map[uint32]bool, len(stops))
+	for _, id := range stops {
+		allowed[id] = true
+	}
+	excluded := make(map[uint32]bool, len(o.Exclude))
+	for _, title := range o.Exclude {
+		if id, ok := a.Lookup(title); ok && !allowed[id] {
+			excluded[id] = true
+		}
+	}
+
+	// Pattern matches need the title, which may be copied out of a mapped
+	// snapshot, so remember each verdict.
+	matched := make(map[uint32]bool)
+
+	return func(id uint32) bool {
+		if allowed[id] {
+			return false
+		}
+		if excluded[id] {
+			return true
+		}
+		if o.MaxDegree > 0 && len(a.Out(id)) > o.MaxDegree {
+			return true
+		}
+		if len(o.ExcludePatterns) == 0 {
+			return false
+		}
+		if m, ok := matched[id]; ok {
+			return m
+		}
+		title := a.Title(id)
+		m := false
+		for _, re := range o.ExcludePatterns {
+			if re.MatchString(title) {
+				m = true
+				break
+			}
+		}
+		matched[id] = m
+		return m
+	}
+}
+
+// FindPathWithOptions finds a shortest path from one page to another that
+// satisfies opts. With waypoints the result is the concatenation of the
+// shortest legs between consecutive stops. The search stops when ctx is done.
+func FindPathWithOptions(ctx context.Context, a Adjacency, from, to string, opts PathOptions) PathResult {
+	titles := make([]string, 0, len(opts.Waypoints)+2)
+	titles = append(titles, from)
+	titles = append(titles, opts.Waypoints...)
+	titles = append(titles, to)
+
+	stops := make([]uint32, len(titles))
+	for i, title := range titles {
+		id, ok := a.Lookup(title)
+		if !ok {
+			return PathResult{}
+		}
+		stops[i] = id
+	}
+
+	skip := opts.skipFunc(a, stops)
+	path := []uint32{stops[0]}
+	b := newBudget(ctx, opts.MaxExplored)
+
+	for i := 0; i < len(stops)-1; i++ {
+		depth := -1
+		if opts.MaxDepth > 0 {
+			depth = opts.MaxDepth - (len(path) - 1)
+		}
+
+		var leg []uint32
+		if opts.Bidirectional {
+			leg = bidirectionalPathIDs(a, stops[i], stops[i+1], depth, skip, b)
+		} else {
+			leg = shortestPathIDs(a, stops[i], stops[i+1], depth, skip, nil, b)
+		}
+		if leg == nil {
+			return b.finish(PathResult{})
+		}
+		path = append(path, leg[1:]...)
+	}
+
+	r := b.finish(PathResult{
+		Found: true,
+		Path:  idPathsToTitles(a, [][]uint32{path})[0],
+		Hops:  len(path) - 1,
+	})
+	r.Explored = max(r.Explored, 1)
+	return r
+}
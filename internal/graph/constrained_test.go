@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"context"
+	"regexp"
+	"testing"
+)
+
+// hubGraph routes A→E through a hub, a "List of" page, or a long detour.
+func hubGraph() *CSR {
+	return buildCSR([][2]string{
+		{"A", "Hub"}, {"Hub", "E"}, {"Hub", "P"}, {"Hub", "Q"}, {"Hub", "R"},
+		{"A", "List of things"}, {"List of things", "E"},
+		{"A", "B"}, {"B", "C"}, {"C", "E"},
+		{"A", "W"}, {"W", "B"},
+	})
+}
+
+func TestFindPathWithOptions(t *testing.T) {
+	c := hubGraph()
+
+	tests := []struct {
+		name     string
+		opts     PathOptions
+		wantPath []string
+	}{
+		{
+			name:     "no constraints",
+			opts:     PathOptions{},
+			wantPath: []string{"A", "Hub", "E"},
+		},
+		{
+			name:     "exclude",
+			opts:     PathOptions{Exclude: []string{"Hub", "List of things"}},
+			wantPath: []string{"A", "B", "C", "E"},
+		},
+		{
+			name:     "degree cap and pattern",
+			opts:     PathOptions{MaxDegree: 3, ExcludePatterns: []*regexp.Regexp{regexp.MustCompile(`^List of`)}},
+			wantPath: []string{"A", "B", "C", "E"},
+		},
+		{
+			name:     "waypoint",
+			opts:     PathOptions{Waypoints: []string{"W"}},
+			wantPath: []string{"A", "W", "B", "C", "E"},
+		},
+		{
+			name:     "excluded waypoint is still visited",
+			opts:     PathOptions{Waypoints: []string{"Hub"}, Exclude: []string{"Hub"}},
+			wantPath: []string{"A", "Hub", "E"},
+		},
+		{
+			name:     "depth limit across legs",
+			opts:     PathOptions{Waypoints: []string{"W"}, MaxDepth: 3},
+			wantPath: nil,
+		},
+		{
+			name:     "unknown waypoint",
+			opts:     PathOptions{Waypoints: []string{"Nowhere"}},
+			wantPath: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, bidi := range []bool{false, true} {
+				opts := tt.opts
+				opts.Bidirectional = bidi
+				r := FindPathWithOptions(context.Background(), c, "A", "E", opts)
+
+				if r.Found != (tt.wantPath != nil) {
+					t.Fatalf("bidirectional=%v: Found = %v, want %v", bidi, r.Found, tt.wantPath != nil)
+				}
+				if r.Found && len(r.Path) != len(tt.wantPath) {
+					t.Errorf("bidirectional=%v: path = %v, want %v", bidi, r.Path, tt.wantPath)
+				}
+				if !bidi && r.Found && !equalSlices(r.Path, tt.wantPath) {
+					t.Errorf("path = %v, want %v", r.Path, tt.wantPath)
+				}
+				if r.Found && r.Hops != len(r.Path)-1 {
+					t.Errorf("Hops = %d for path %v", r.Hops, r.Path)
+				}
+			}
+		})
+	}
+}
+
+func TestFindPathWithOptionsMatchesUnconstrained(t *testing.T) {
+	g := buildChainGraph(50)
+	a := g.Adjacency()
+
+	want := g.FindPath("node_0", "node_49")
+	got := FindPathWithOptions(context.Background(), a, "node_0", "node_49", PathOptions{Bidirectional: true})
+	if got.Found != want.Found || got.Hops != want.Hops {
+		t.Errorf("FindPathWithOptions = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,352 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Adjacency is read-only, integer-indexed access to a directed graph.
+// Node IDs are dense in [0, NodeCount()) and neighbor slices must not be modified.
+//
+// The neighbor slices of a memory-mapped CSR point into the mapping and are
+// valid until the CSR is closed.
+type Adjacency interface {
+	NodeCount() int
+	EdgeCount() int
+	Lookup(title string) (uint32, bool)
+	Title(id uint32) string
+	Out(id uint32) []uint32
+	In(id uint32) []uint32
+}
+
+// CSR is an immutable compressed-sparse-row graph.
+//
+// Node IDs are assigned in sorted title order, so title lookup is a binary
+// search over the interned title table and no per-title hash map is kept.
+// Adjacency for both directions is stored as flat offset/target arrays:
+// the out-links of node i are outAdj[outOffs[i]:outOffs[i+1]].
+type CSR struct {
+	names    string   // every title concatenated in ID order
+	nameOffs []uint64 // names[nameOffs[i]:nameOffs[i+1]] is the title of node i
+	outOffs  []uint64
+	outAdj   []uint32
+	inOffs   []uint64
+	inAdj    []uint32
+
+	// mapping is the memory-mapped snapshot backing the arrays above, if any.
+	mapping []byte
+	// snapshot is the header of the snapshot file c was opened from, if any.
+	snapshot *SnapshotHeader
+
+	redirects *Redirects
+}
+
+func (c *CSR) NodeCount() int {
+	return len(c.nameOffs) - 1
+}
+
+func (c *CSR) EdgeCount() int {
+	return len(c.outAdj)
+}
+
+// Lookup returns the ID of the node with the given title.
+func (c *CSR) Lookup(title string) (uint32, bool) {
+	n := c.NodeCount()
+	i := sort.Search(n, func(i int) bool { return c.name(uint32(i)) >= title })
+	if i < n && c.name(uint32(i)) == title {
+		return uint32(i), true
+	}
+	return 0, false
+}
+
+// Title returns the title of the node with the given ID.
+func (c *CSR) Title(id uint32) string {
+	if c.mapping != nil {
+		// Titles may outlive the mapping, so never hand out views into it.
+		return strings.Clone(c.name(id))
+	}
+	return c.name(id)
+}
+
+func (c *CSR) name(id uint32) string {
+	return c.names[c.nameOffs[id]:c.nameOffs[id+1]]
+}
+
+// Out returns the IDs of the nodes id links to, in ascending order.
+func (c *CSR) Out(id uint32) []uint32 {
+	return c.outAdj[c.outOffs[id]:c.outOffs[id+1]]
+}
+
+// In returns the IDs of the nodes linking to id, in ascending order.
+func (c *CSR) In(id uint32) []uint32 {
+	return c.inAdj[c.inOffs[id]:c.inOffs[id+1]]
+}
+
+// HasEdge reports whether there is a link from u to v.
+func (c *CSR) HasEdge(u, v uint32) bool {
+	_, found := slices.BinarySearch(c.Out(u), v)
+	return found
+}
+
+// Canonical returns the page a title redirects to, or the title itself if
+// it is not a redirect. A title that is neither a page nor a redirect is
+// looked up again in normalized form.
+func (c *CSR) Canonical(title string) string {
+	return c.redirects.resolve(title, c.HasNode)
+}
+
+func (c *CSR) HasNode(title string) bool {
+	_, ok := c.Lookup(title)
+	return ok
+}
+
+func (c *CSR) OutLinkTitles(title string) []string {
+	id, ok := c.Lookup(title)
+	if !ok {
+		return nil
+	}
+	return c.titles(c.Out(id))
+}
+
+func (c *CSR) InLinkTitles(title string) []string {
+	id, ok := c.Lookup(title)
+	if !ok {
+		return nil
+	}
+	return c.titles(c.In(id))
+}
+
+func (c *CSR) titles(ids []uint32) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = c.Title(id)
+	}
+	return result
+}
+
+// Adjacency returns c itself; CSR is already integer-indexed.
+func (c *CSR) Adjacency() Adjacency {
+	return c
+}
+
+// Mapped reports whether c reads from a memory-mapped snapshot.
+func (c *CSR) Mapped() bool {
+	return c.mapping != nil
+}
+
+// Close releases the memory mapping backing c, if any. Call it only once no
+// goroutine can still be using c or a neighbor slice it returned.
+func (c *CSR) Close() error {
+	if c.mapping == nil {
+		return nil
+	}
+	data := c.mapping
+	*c = CSR{nameOffs: []uint64{0}, outOffs: []uint64{0}, inOffs: []uint64{0}}
+	return unmapFile(data)
+}
+
+// MemoryUsage returns the approximate number of heap bytes held by the CSR
+// arrays. A memory-mapped CSR uses no heap for them.
+func (c *CSR) MemoryUsage() int64 {
+	if c.mapping != nil {
+		return 0
+	}
+	return int64(len(c.names)) +
+		int64(len(c.nameOffs)+len(c.outOffs)+len(c.inOffs))*8 +
+		int64(len(c.outAdj)+len(c.inAdj))*4
+}
+
+func (c *CSR) FindPath(from, to string) PathResult {
+	return c.FindPathWithLimit(from, to, -1)
+}
+
+func (c *CSR) FindPathWithLimit(from, to string, maxDepth int) PathResult {
+	return c.FindPathContext(context.Background(), from, to, maxDepth, 0)
+}
+
+func (c *CSR) FindPathContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult {
+	return findPathIndexed(ctx, c, from, to, maxDepth, maxExplored)
+}
+
+func (c *CSR) FindPathBidirectional(from, to string) PathResult {
+	return c.FindPathBidirectionalWithLimit(from, to, -1)
+}
+
+func (c *CSR) FindPathBidirectionalWithLimit(from, to string, maxDepth int) PathResult {
+	return c.FindPathBidirectionalContext(context.Background(), from, to, maxDepth, 0)
+}
+
+func (c *CSR) FindPathBidirectionalContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult {
+	return findPathBidirectionalIndexed(ctx, c, from, to, maxDepth, maxExplored)
+}
+
+func (c *CSR) GetNeighborhood(title string, maxDepth, maxNodes int) *Subgraph {
+	return c.GetNeighborhoodContext(context.Background(), title, maxDepth, maxNodes, 0)
+}
+
+func (c *CSR) GetNeighborhoodContext(ctx context.Context, title string, maxDepth, maxNodes, maxExplored int) *Subgraph {
+	return c.GetNeighborhoodWithOptions(ctx, title, NeighborhoodOptions{
+		MaxDepth:    maxDepth,
+		MaxNodes:    maxNodes,
+		MaxExplored: maxExplored,
+	})
+}
+
+func (c *CSR) GetNeighborhoodWithOptions(ctx context.Context, title string, opts NeighborhoodOptions) *Subgraph {
+	center, ok := c.Lookup(title)
+	if !ok {
+		return nil
+	}
+	return neighborhood(ctx, center, opts, c.Out, c.In, c.Title)
+}
+
+// CSRBuilder accumulates nodes and edges and freezes them into a CSR.
+// It is not safe for concurrent use.
+type CSRBuilder struct {
+	ids    map[string]uint32
+	titles []string
+	src    []uint32
+	dst    []uint32
+}
+
+// NewCSRBuilder creates a builder with room for the given number of nodes and edges.
+func NewCSRBuilder(nodeCapacity, edgeCapacity int) *CSRBuilder {
+	return &CSRBuilder{
+		ids:    make(map[string]uint32, nodeCapacity),
+		titles: make([]string, 0, nodeCapacity),
+		src:    make([]uint32, 0, edgeCapacity),
+		dst:    make([]uint32, 0, edgeCapacity),
+	}
+}
+
+// AddNode adds a node if it does not exist yet and returns its temporary ID.
+func (b *CSRBuilder) AddNode(title string) uint32 {
+	if id, ok := b.ids[title]; ok {
+		return id
+	}
+	id := uint32(len(b.titles))
+	b.ids[title] = id
+	b.titles = append(b.titles, title)
+	return id
+}
+
+// AddEdge adds a directed edge, creating both endpoints as needed.
+// Duplicate edges are removed by Build.
+func (b *CSRBuilder) AddEdge(source, target string) {
+	b.src = append(b.src, b.AddNode(source))
+	b.dst = append(b.dst, b.AddNode(target))
+}
+
+// Build freezes the accumulated graph. The builder must not be used afterwards.
+func (b *CSRBuilder) Build() *CSR {
+	n := len(b.titles)
+
+	// Assign final IDs in sorted title order.
+	order := make([]uint32, n)
+	for i := range order {
+		order[i] = uint32(i)
+	}
+	slices.SortFunc(order, func(x, y uint32) int {
+		return strings.Compare(b.titles[x], b.titles[y])
+	})
+	rank := make([]uint32, n)
+	for newID, oldID := range order {
+		rank[oldID] = uint32(newID)
+	}
+
+	c := &CSR{nameOffs: make([]uint64, n+1)}
+
+	var names strings.Builder
+	total := 0
+	for _, t := range b.titles {
+		total += len(t)
+	}
+	names.Grow(total)
+	for i, oldID := range order {
+		names.WriteString(b.titles[oldID])
+		c.nameOffs[i+1] = uint64(names.Len())
+	}
+	c.names = names.String()
+	b.ids, b.titles = nil, nil
+
+	// Counting sort edges by source, then sort and dedupe each row in place.
+	outOffs := make([]uint64, n+1)
+	for _, s := range b.src {
+		outOffs[rank[s]+1]++
+	}
+	for i := 0; i < n; i++ {
+		outOffs[i+1] += outOffs[i]
+	}
+	outAdj := make([]uint32, len(b.src))
+	next := slices.Clone(outOffs[:n])
+	for i, s := range b.src {
+		u := rank[s]
+		outAdj[next[u]] = rank[b.dst[i]]
+		next[u]++
+	}
+	b.src, b.dst = nil, nil
+
+	c.outOffs = make([]uint64, n+1)
+	w := uint64(0)
+	for u := 0; u < n; u++ {
+		row := outAdj[outOffs[u]:outOffs[u+1]]
+		slices.Sort(row)
+		rowStart := w
+		for _, v := range row {
+			if w > rowStart && outAdj[w-1] == v {
+				continue
+			}
+			outAdj[w] = v
+			w++
+		}
+		c.outOffs[u+1] = w
+	}
+	c.outAdj = slices.Clip(outAdj[:w])
+
+	// Transpose; visiting sources in ascending order keeps in-rows sorted.
+	c.inOffs = make([]uint64, n+1)
+	for _, v := range c.outAdj {
+		c.inOffs[v+1]++
+	}
+	for i := 0; i < n; i++ {
+		c.inOffs[i+1] += c.inOffs[i]
+	}
+	c.inAdj = make([]uint32, len(c.outAdj))
+	next = slices.Clone(c.inOffs[:n])
+	for u := 0; u < n; u++ {
+		for _, v := range c.Out(uint32(u)) {
+			c.inAdj[next[v]] = uint32(u)
+			next[v]++
+		}
+	}
+
+	return c
+}
+
+// WithOutLinks returns a new CSR in which the out-links of each page in
+// updates are replaced by the given targets. A nil target list removes all
+// of a page's out-links. Pages and targets not yet in the graph are added.
+func (c *CSR) WithOutLinks(updates map[string][]string) *CSR {
+	b := NewCSRBuilder(c.NodeCount()+len(updates), c.EdgeCount())
+	for id := uint32(0); int(id) < c.NodeCount(); id++ {
+		title := c.Title(id)
+		b.AddNode(title)
+		if _, replaced := updates[title]; replaced {
+			continue
+		}
+		for _, target := range c.Out(id) {
+			b.AddEdge(title, c.Title(target))
+		}
+	}
+	for title, targets := range updates {
+		b.AddNode(title)
+		for _, target := range targets {
+			b.AddEdge(title, target)
+		}
+	}
+	next := b.Build()
+	next.redirects = c.redirects
+	return next
+}
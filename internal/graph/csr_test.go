@@ -0,0 +1,192 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
+)
+
+func buildCSR(edges [][2]string) *CSR {
+	b := NewCSRBuilder(0, len(edges))
+	for _, e := range edges {
+		b.AddEdge(e[0], e[1])
+	}
+	return b.Build()
+}
+
+func TestCSRBuild(t *testing.T) {
+	c := buildCSR([][2]string{{"C", "A"}, {"A", "B"}, {"A", "C"}, {"A", "B"}, {"B", "C"}})
+
+	if c.NodeCount() != 3 {
+		t.Errorf("expected 3 nodes, got %d", c.NodeCount())
+	}
+	if c.EdgeCount() != 4 {
+		t.Errorf("expected 4 edges after dedupe, got %d", c.EdgeCount())
+	}
+
+	// IDs follow sorted title order
+	for i, want := range []string{"A", "B", "C"} {
+		if got := c.Title(uint32(i)); got != want {
+			t.Errorf("Title(%d) = %q, want %q", i, got, want)
+		}
+		id, ok := c.Lookup(want)
+		if !ok || id != uint32(i) {
+			t.Errorf("Lookup(%q) = %d, %v, want %d, true", want, id, ok, i)
+		}
+	}
+	if _, ok := c.Lookup("Z"); ok {
+		t.Error("Lookup should fail for missing title")
+	}
+
+	if got := c.OutLinkTitles("A"); !equalSlices(got, []string{"B", "C"}) {
+		t.Errorf("OutLinkTitles(A) = %v", got)
+	}
+	if got := c.InLinkTitles("C"); !equalSlices(got, []string{"A", "B"}) {
+		t.Errorf("InLinkTitles(C) = %v", got)
+	}
+	if !c.HasEdge(0, 2) || c.HasEdge(2, 1) {
+		t.Error("HasEdge mismatch")
+	}
+}
+
+func TestCSRMatchesGraph(t *testing.T) {
+	edges := [][2]string{
+		{"A", "B"}, {"B", "C"}, {"C", "D"}, {"D", "E"}, {"E", "F"},
+		{"A", "X"}, {"X", "Y"}, {"Y", "F"}, {"G", "H"},
+	}
+	g := New()
+	for _, e := range edges {
+		g.AddEdge(e[0], e[1])
+	}
+	c := g.Compact()
+
+	if c.NodeCount() != g.NodeCount() || c.EdgeCount() != g.EdgeCount() {
+		t.Fatalf("counts differ: csr %d/%d, graph %d/%d",
+			c.NodeCount(), c.EdgeCount(), g.NodeCount(), g.EdgeCount())
+	}
+
+	pairs := [][2]string{{"A", "F"}, {"A", "A"}, {"A", "H"}, {"B", "E"}, {"A", "Z"}}
+	for _, p := range pairs {
+		for _, depth := range []int{-1, 2, 4} {
+			want := g.FindPathWithLimit(p[0], p[1], depth)
+			got := c.FindPathWithLimit(p[0], p[1], depth)
+			if got.Found != want.Found || got.Hops != want.Hops {
+				t.Errorf("FindPathWithLimit(%s, %s, %d) = %+v, want %+v", p[0], p[1], depth, got, want)
+			}
+			if got.Found && (got.Path[0] != p[0] || got.Path[len(got.Path)-1] != p[1]) {
+				t.Errorf("path %v does not connect %s and %s", got.Path, p[0], p[1])
+			}
+
+			want = g.FindPathBidirectionalWithLimit(p[0], p[1], depth)
+			got = c.FindPathBidirectionalWithLimit(p[0], p[1], depth)
+			if got.Found != want.Found || got.Hops != want.Hops {
+				t.Errorf("FindPathBidirectionalWithLimit(%s, %s, %d) = %+v, want %+v", p[0], p[1], depth, got, want)
+			}
+		}
+	}
+
+	want := g.GetNeighborhood("A", 2, 100)
+	got := c.GetNeighborhood("A", 2, 100)
+	if len(got.Nodes) != len(want.Nodes) || len(got.Edges) != len(want.Edges) {
+		t.Errorf("neighborhood size = %d/%d, want %d/%d",
+			len(got.Nodes), len(got.Edges), len(want.Nodes), len(want.Edges))
+	}
+	if c.GetNeighborhood("missing", 2, 100) != nil {
+		t.Error("neighborhood of missing node should be nil")
+	}
+}
+
+func TestGraphCompactInvalidation(t *testing.T) {
+	g := New()
+	g.AddEdge("A", "B")
+
+	first := g.Compact()
+	if g.Compact() != first {
+		t.Error("Compact should be cached between mutations")
+	}
+
+	g.AddEdge("B", "C")
+	second := g.Compact()
+	if second == first {
+		t.Error("Compact should be rebuilt after a mutation")
+	}
+	if second.EdgeCount() != 2 {
+		t.Errorf("expected 2 edges, got %d", second.EdgeCount())
+	}
+}
+
+func TestCSRWithOutLinks(t *testing.T) {
+	c := buildCSR([][2]string{{"A", "B"}, {"B", "C"}})
+
+	updated := c.WithOutLinks(map[string][]string{
+		"A": {"C", "D"},
+		"B": nil,
+	})
+
+	if got := updated.OutLinkTitles("A"); !equalSlices(got, []string{"C", "D"}) {
+		t.Errorf("OutLinkTitles(A) = %v", got)
+	}
+	if got := updated.OutLinkTitles("B"); len(got) != 0 {
+		t.Errorf("B should have no out-links, got %v", got)
+	}
+	if updated.EdgeCount() != 2 {
+		t.Errorf("expected 2 edges, got %d", updated.EdgeCount())
+	}
+	// The original is unchanged
+	if c.EdgeCount() != 2 || !equalSlices(c.OutLinkTitles("A"), []string{"B"}) {
+		t.Error("WithOutLinks must not modify the receiver")
+	}
+}
+
+func TestLoader_LoadViewCSR(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	c := cache.New(db)
+	pageA, _ := c.CreatePage("A")
+	pageB, _ := c.CreatePage("B")
+	c.CreatePage("D")
+	c.UpdatePageStatus("A", cache.StatusSuccess, "", "")
+	c.UpdatePageStatus("B", cache.StatusSuccess, "", "")
+	c.UpdatePageStatus("D", cache.StatusSuccess, "", "")
+	c.AddLinks(pageA.ID, []cache.Link{{TargetTitle: "B"}, {TargetTitle: "C"}})
+	c.AddLinks(pageB.ID, []cache.Link{{TargetTitle: "C"}})
+
+	loader := NewLoaderWithConfig(c, LoaderConfig{Backend: BackendCSR})
+	v, err := loader.LoadView()
+	if err != nil {
+		t.Fatalf("LoadView failed: %v", err)
+	}
+	if _, ok := v.(*CSR); !ok {
+		t.Fatalf("expected *CSR, got %T", v)
+	}
+	if v.NodeCount() != 4 {
+		t.Errorf("expected 4 nodes, got %d", v.NodeCount())
+	}
+	if v.EdgeCount() != 3 {
+		t.Errorf("expected 3 edges, got %d", v.EdgeCount())
+	}
+	if r := v.FindPath("A", "C"); !r.Found || r.Hops != 1 {
+		t.Errorf("FindPath(A, C) = %+v", r)
+	}
+}
+
+func TestParseBackend(t *testing.T) {
+	for name, want := range map[string]Backend{"": BackendPointer, "pointer": BackendPointer, "csr": BackendCSR} {
+		got, err := ParseBackend(name)
+		if err != nil || got != want {
+			t.Errorf("ParseBackend(%q) = %q, %v", name, got, err)
+		}
+	}
+	if _, err := ParseBackend("btree"); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func BenchmarkFindPath_CSRLargeGraph(b *testing.B) {
+	c := buildChainGraph(10000).Compact()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		c.FindPath("node_0", "node_9999")
+	}
+}
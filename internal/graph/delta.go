@@ -0,0 +1,182 @@
+package graph
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// Delta file layout (all integers little-endian):
+//
+//	header  32 bytes: magic, version, checksum of the base snapshot,
+//	        CRC-32C of the body, added page count, changed row count
+//	added   per page: uint32 title length, title
+//	rows    per row: uint32 node ID, uint32 length, uint32 × length targets
+//
+// A delta holds the pages an Overlay added and the out-link rows it
+// replaced, under the IDs the Overlay gave them, so loading it over the
+// same snapshot numbers every page as before.
+const (
+	deltaMagic      = "WGDELTA\x00"
+	deltaVersion    = 1
+	deltaHeaderSize = 32
+)
+
+// ErrStaleDelta is returned when a delta was saved over another snapshot
+// than the one it is loaded over.
+var ErrStaleDelta = errors.New("graph delta does not match the snapshot")
+
+// DeltaPath returns where the changes layered over the graph cache at
+// cachePath are stored.
+func DeltaPath(cachePath string) string {
+	return cachePath + ".delta"
+}
+
+// Persist saves o to the graph cache at path. If the base of o is the
+// snapshot at path, only the changes are written, to DeltaPath(path), and o
+// is returned. Otherwise o is compacted into a new snapshot at path and
+// Persist returns an Overlay over that snapshot, mapped from disk, to use in
+// place of o so neither its base nor the compacted copy stays in memory.
+func (o *Overlay) Persist(path string) (*Overlay, error) {
+	if h := o.base.snapshot; h != nil {
+		cur, err := ReadSnapshotHeader(path)
+		if err == nil && cur.Checksum == h.Checksum && cur.Timestamp.Equal(h.Timestamp) {
+			return o, saveDelta(DeltaPath(path), o)
+		}
+	}
+
+	if err := saveSnapshot(path, o.Compact()); err != nil {
+		return nil, err
+	}
+	if err := os.Remove(DeltaPath(path)); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("removing graph delta: %w", err)
+	}
+	c, _, err := OpenSnapshot(path)
+	if err != nil {
+		return nil, err
+	}
+	c.redirects = o.base.redirects
+	return NewOverlay(c), nil
+}
+
+// saveDelta writes the changes of o over its base snapshot to path.
+// Uses atomic write (temp file + rename) to prevent corruption.
+func saveDelta(path string, o *Overlay) error {
+	buf := make([]byte, deltaHeaderSize)
+	for _, title := range o.added {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(title)))
+		buf = append(buf, title...)
+	}
+	for _, u := range slices.Sorted(maps.Keys(o.out)) {
+		row := o.out[u]
+		buf = binary.LittleEndian.AppendUint32(buf, u)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(row)))
+		for _, v := range row {
+			buf = binary.LittleEndian.AppendUint32(buf, v)
+		}
+	}
+
+	copy(buf, deltaMagic)
+	binary.LittleEndian.PutUint32(buf[8:], deltaVersion)
+	binary.LittleEndian.PutUint32(buf[12:], o.base.snapshot.Checksum)
+	binary.LittleEndian.PutUint32(buf[16:], crc32.Checksum(buf[deltaHeaderSize:], crcTable))
+	binary.LittleEndian.PutUint32(buf[20:], uint32(len(o.added)))
+	binary.LittleEndian.PutUint32(buf[24:], uint32(len(o.out)))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing graph delta: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming graph delta: %w", err)
+	}
+	return nil
+}
+
+// LoadDelta reads the changes saved by Persist and layers them over base.
+// Returns ErrStaleDelta unless base was opened from the snapshot they were
+// saved over.
+func LoadDelta(path string, base *CSR) (*Overlay, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < deltaHeaderSize || string(buf[:8]) != deltaMagic {
+		return nil, errors.New("not a graph delta")
+	}
+	if v := binary.LittleEndian.Uint32(buf[8:]); v != deltaVersion {
+		return nil, fmt.Errorf("graph delta version mismatch: got %d, want %d", v, deltaVersion)
+	}
+	if base.snapshot == nil || binary.LittleEndian.Uint32(buf[12:]) != base.snapshot.Checksum {
+		return nil, ErrStaleDelta
+	}
+	if crc32.Checksum(buf[deltaHeaderSize:], crcTable) != binary.LittleEndian.Uint32(buf[16:]) {
+		return nil, errors.New("graph delta checksum mismatch")
+	}
+	added := binary.LittleEndian.Uint32(buf[20:])
+	rows := binary.LittleEndian.Uint32(buf[24:])
+
+	body := buf[deltaHeaderSize:]
+	corrupt := errors.New("corrupt graph delta")
+	next := func() (uint32, bool) {
+		if len(body) < 4 {
+			return 0, false
+		}
+		v := binary.LittleEndian.Uint32(body)
+		body = body[4:]
+		return v, true
+	}
+
+	o := NewOverlay(base)
+	o.out = make(map[uint32][]uint32, rows)
+	o.in = make(map[uint32][]uint32)
+	for range added {
+		n, ok := next()
+		if !ok || uint64(n) > uint64(len(body)) {
+			return nil, corrupt
+		}
+		title := string(body[:n])
+		body = body[n:]
+		if _, ok := o.Lookup(title); ok {
+			return nil, corrupt
+		}
+		o.node(title)
+	}
+
+	nodes := uint32(o.NodeCount())
+	for range rows {
+		u, ok := next()
+		if !ok || u >= nodes {
+			return nil, corrupt
+		}
+		if _, ok := o.out[u]; ok {
+			return nil, corrupt
+		}
+		n, ok := next()
+		if !ok || uint64(n)*4 > uint64(len(body)) {
+			return nil, corrupt
+		}
+		row := make([]uint32, n)
+		for i := range row {
+			row[i], _ = next()
+			if row[i] >= nodes || (i > 0 && row[i] <= row[i-1]) {
+				return nil, corrupt
+			}
+		}
+		o.setOut(u, row)
+	}
+	if len(body) != 0 {
+		return nil, corrupt
+	}
+	return o, nil
+}
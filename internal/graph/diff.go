@@ -0,0 +1,217 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// DiffOptions configures Diff. Zero fields take the defaults.
+type DiffOptions struct {
+	// Limit is the most pages and edges listed in each of the added and
+	// removed lists; the counts always cover every change. Zero lists all.
+	Limit int
+
+	// TopDegreeChanges is the number of pages with the biggest degree
+	// changes to report. Default 20.
+	TopDegreeChanges int
+
+	// Pairs are compared by shortest-path length in both graphs.
+	Pairs []PathPair
+
+	// MaxDepth and MaxExplored bound each path search, as in BatchPaths,
+	// except that a zero MaxDepth means no limit.
+	MaxDepth    int
+	MaxExplored int
+}
+
+func (o DiffOptions) withDefaults() DiffOptions {
+	if o.TopDegreeChanges <= 0 {
+		o.TopDegreeChanges = 20
+	}
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = -1
+	}
+	return o
+}
+
+// GraphDiff describes how one graph changed into another. Pages and edges
+// are matched by title.
+type GraphDiff struct {
+	AddedPages   []string
+	RemovedPages []string
+	AddedEdges   [][2]string
+	RemovedEdges [][2]string
+
+	AddedPageCount   int
+	RemovedPageCount int
+	AddedEdgeCount   int
+	RemovedEdgeCount int
+
+	// DegreeChanges lists the pages whose in- plus out-degree changed
+	// most, biggest change first.
+	DegreeChanges []DegreeChange
+
+	// PathChanges lists the pairs whose shortest-path length changed, in
+	// the order given.
+	PathChanges []PathChange
+}
+
+// DegreeChange is a page's degree before and after. A page missing from
+// one of the graphs has degree zero there.
+type DegreeChange struct {
+	Title     string
+	InBefore  int
+	InAfter   int
+	OutBefore int
+	OutAfter  int
+}
+
+// Delta returns the total change in in- and out-degree, ignoring direction.
+func (d DegreeChange) Delta() int {
+	return abs(d.InAfter-d.InBefore) + abs(d.OutAfter-d.OutBefore)
+}
+
+// PathChange is a pair whose shortest path has a different length after.
+// Before and After are hop counts, -1 when no path was found. Truncated is
+// set when either search stopped early, so a missing path may exist.
+type PathChange struct {
+	From      string
+	To        string
+	Before    int
+	After     int
+	Truncated bool
+}
+
+// Diff compares two graphs. Edge and degree comparisons take time linear in
+// the size of both graphs; each distinct source in opts.Pairs costs one
+// search per graph. Returns ctx.Err() if ctx is done first.
+func Diff(ctx context.Context, before, after Adjacency, opts DiffOptions) (*GraphDiff, error) {
+	opts = opts.withDefaults()
+	d := &GraphDiff{}
+
+	// Each graph is walked once for the pages and edges only it has.
+	oneWay := func(from, to Adjacency, pages *[]string, pageCount *int, edges *[][2]string, edgeCount *int) error {
+		for u := range uint32(from.NodeCount()) {
+			if u%cancelCheckInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			title := from.Title(u)
+			w, ok := to.Lookup(title)
+			if !ok {
+				*pageCount++
+				if opts.Limit <= 0 || len(*pages) < opts.Limit {
+					*pages = append(*pages, title)
+				}
+			}
+			for _, v := range from.Out(u) {
+				target := from.Title(v)
+				if ok {
+					if x, found := to.Lookup(target); found && hasEdge(to, w, x) {
+						continue
+					}
+				}
+				*edgeCount++
+				if opts.Limit <= 0 || len(*edges) < opts.Limit {
+					*edges = append(*edges, [2]string{title, target})
+				}
+			}
+		}
+		return nil
+	}
+	if err := oneWay(before, after, &d.RemovedPages, &d.RemovedPageCount, &d.RemovedEdges, &d.RemovedEdgeCount); err != nil {
+		return nil, err
+	}
+	if err := oneWay(after, before, &d.AddedPages, &d.AddedPageCount, &d.AddedEdges, &d.AddedEdgeCount); err != nil {
+		return nil, err
+	}
+	slices.Sort(d.AddedPages)
+	slices.Sort(d.RemovedPages)
+	sortEdges(d.AddedEdges)
+	sortEdges(d.RemovedEdges)
+
+	d.DegreeChanges = degreeChanges(before, after, opts.TopDegreeChanges)
+
+	if len(opts.Pairs) > 0 {
+		was := BatchPaths(ctx, before, opts.Pairs, opts.MaxDepth, opts.MaxExplored)
+		now := BatchPaths(ctx, after, opts.Pairs, opts.MaxDepth, opts.MaxExplored)
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		for i, p := range opts.Pairs {
+			hops := func(r PathResult) int {
+				if !r.Found {
+					return -1
+				}
+				return r.Hops
+			}
+			if b, a := hops(was[i]), hops(now[i]); b != a {
+				d.PathChanges = append(d.PathChanges, PathChange{
+					From:      p.From,
+					To:        p.To,
+					Before:    b,
+					After:     a,
+					Truncated: was[i].Truncated || now[i].Truncated,
+				})
+			}
+		}
+	}
+
+	return d, nil
+}
+
+// degreeChanges returns the n pages whose degree changed most, ties
+// broken by title.
+func degreeChanges(before, after Adjacency, n int) []DegreeChange {
+	var changes []DegreeChange
+	for u := range uint32(after.NodeCount()) {
+		c := DegreeChange{Title: after.Title(u), InAfter: len(after.In(u)), OutAfter: len(after.Out(u))}
+		if v, ok := before.Lookup(c.Title); ok {
+			c.InBefore, c.OutBefore = len(before.In(v)), len(before.Out(v))
+		}
+		if c.Delta() > 0 {
+			changes = append(changes, c)
+		}
+	}
+	for u := range uint32(before.NodeCount()) {
+		title := before.Title(u)
+		if _, ok := after.Lookup(title); !ok && len(before.In(u))+len(before.Out(u)) > 0 {
+			changes = append(changes, DegreeChange{Title: title, InBefore: len(before.In(u)), OutBefore: len(before.Out(u))})
+		}
+	}
+
+	slices.SortFunc(changes, func(x, y DegreeChange) int {
+		if c := cmp.Compare(y.Delta(), x.Delta()); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Title, y.Title)
+	})
+	return changes[:min(n, len(changes))]
+}
+
+// hasEdge reports whether a links u to v. A CSR answers by binary search;
+// other graphs by a scan of u's out-links.
+func hasEdge(a Adjacency, u, v uint32) bool {
+	if c, ok := a.(*CSR); ok {
+		return c.HasEdge(u, v)
+	}
+	return slices.Contains(a.Out(u), v)
+}
+
+func sortEdges(edges [][2]string) {
+	slices.SortFunc(edges, func(x, y [2]string) int {
+		if c := cmp.Compare(x[0], y[0]); c != 0 {
+			return c
+		}
+		return cmp.Compare(x[1], y[1])
+	})
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	before := buildCSR([][2]string{
+		{"A", "B"}, {"B", "C"}, {"C", "D"}, {"A", "Old"},
+	})
+	after := buildCSR([][2]string{
+		{"A", "B"}, {"B", "C"}, {"C", "D"}, {"A", "C"}, {"New", "A"}, {"New", "D"},
+	})
+	ctx := context.Background()
+
+	d, err := Diff(ctx, before, after, DiffOptions{
+		Pairs: []PathPair{{From: "A", To: "D"}, {From: "B", To: "D"}, {From: "A", To: "Old"}},
+	})
+	if err != nil {
+		t.Fatalf("Diff error: %v", err)
+	}
+
+	if !equalSlices(d.AddedPages, []string{"New"}) || !equalSlices(d.RemovedPages, []string{"Old"}) {
+		t.Errorf("pages added %v, removed %v, want [New], [Old]", d.AddedPages, d.RemovedPages)
+	}
+	if d.AddedPageCount != 1 || d.RemovedPageCount != 1 {
+		t.Errorf("page counts = +%d -%d, want +1 -1", d.AddedPageCount, d.RemovedPageCount)
+	}
+	wantAdded := [][2]string{{"A", "C"}, {"New", "A"}, {"New", "D"}}
+	if len(d.AddedEdges) != len(wantAdded) || d.AddedEdgeCount != 3 {
+		t.Fatalf("added edges = %v (%d), want %v", d.AddedEdges, d.AddedEdgeCount, wantAdded)
+	}
+	for i, e := range wantAdded {
+		if d.AddedEdges[i] != e {
+			t.Errorf("added edges = %v, want %v", d.AddedEdges, wantAdded)
+		}
+	}
+	if len(d.RemovedEdges) != 1 || d.RemovedEdges[0] != [2]string{"A", "Old"} || d.RemovedEdgeCount != 1 {
+		t.Errorf("removed edges = %v (%d), want [[A Old]]", d.RemovedEdges, d.RemovedEdgeCount)
+	}
+
+	// A gains an in-link from New and swaps Old for C: in +1, out unchanged
+	// in count. D and C each gain an in-link; New gains two out-links.
+	if len(d.DegreeChanges) == 0 || d.DegreeChanges[0].Title != "New" || d.DegreeChanges[0].Delta() != 2 {
+		t.Errorf("biggest degree change = %+v, want New with 2", d.DegreeChanges)
+	}
+	for _, c := range d.DegreeChanges {
+		if c.Title == "Old" && (c.InBefore != 1 || c.InAfter != 0) {
+			t.Errorf("Old degree change = %+v, want in 1 -> 0", c)
+		}
+	}
+
+	want := []PathChange{{From: "A", To: "D", Before: 3, After: 2}, {From: "A", To: "Old", Before: 1, After: -1}}
+	if len(d.PathChanges) != len(want) {
+		t.Fatalf("path changes = %+v, want %+v", d.PathChanges, want)
+	}
+	for i := range want {
+		if d.PathChanges[i] != want[i] {
+			t.Errorf("path change %d = %+v, want %+v", i, d.PathChanges[i], want[i])
+		}
+	}
+
+	limited, _ := Diff(ctx, before, after, DiffOptions{Limit: 1, TopDegreeChanges: 1})
+	if len(limited.AddedEdges) != 1 || limited.AddedEdgeCount != 3 || len(limited.DegreeChanges) != 1 {
+		t.Errorf("limited diff lists %d of %d added edges and %d degree changes, want 1 of 3 and 1",
+			len(limited.AddedEdges), limited.AddedEdgeCount, len(limited.DegreeChanges))
+	}
+
+	same, _ := Diff(ctx, before, pointerGraph(before).Adjacency(), DiffOptions{})
+	if same.AddedEdgeCount+same.RemovedEdgeCount+same.AddedPageCount+same.RemovedPageCount != 0 || len(same.DegreeChanges) != 0 {
+		t.Errorf("diff of equal graphs = %+v, want no changes", same)
+	}
+}
@@ -1,7 +1,38 @@
 // Package graph provides an in-memory directed graph for Wikipedia pages.
 package graph
 
-import "sync"
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// View is the read-only query surface shared by the pointer-based Graph and
+// the compact CSR representation. API handlers and commands depend on View so
+// either backend can serve them.
+type View interface {
+	NodeCount() int
+	EdgeCount() int
+	HasNode(title string) bool
+	Canonical(title string) string
+	OutLinkTitles(title string) []string
+	InLinkTitles(title string) []string
+	FindPath(from, to string) PathResult
+	FindPathWithLimit(from, to string, maxDepth int) PathResult
+	FindPathBidirectional(from, to string) PathResult
+	FindPathBidirectionalWithLimit(from, to string, maxDepth int) PathResult
+	GetNeighborhood(title string, maxDepth, maxNodes int) *Subgraph
+
+	// The Context variants stop when ctx is done or after expanding
+	// maxExplored nodes (zero for no cap) and report it in the result.
+	FindPathContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult
+	FindPathBidirectionalContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult
+	GetNeighborhoodContext(ctx context.Context, title string, maxDepth, maxNodes, maxExplored int) *Subgraph
+	GetNeighborhoodWithOptions(ctx context.Context, title string, opts NeighborhoodOptions) *Subgraph
+
+	// Adjacency returns an integer-indexed view for analytics.
+	Adjacency() Adjacency
+}
 
 type Node struct {
 	Title    string
@@ -13,6 +44,11 @@ type Graph struct {
 	nodes map[string]*Node
 	edges int
 	mu    sync.RWMutex
+
+	// compact caches the CSR form built by Compact; any mutation clears it.
+	compact atomic.Pointer[CSR]
+
+	redirects *Redirects
 }
 
 func New() *Graph {
@@ -35,6 +71,7 @@ func (g *Graph) addNode(title string) *Node {
 	}
 	n := &Node{Title: title}
 	g.nodes[title] = n
+	g.compact.Store(nil)
 	return n
 }
 
@@ -54,6 +91,7 @@ func (g *Graph) AddEdge(source, target string) {
 	src.OutLinks = append(src.OutLinks, tgt)
 	tgt.InLinks = append(tgt.InLinks, src)
 	g.edges++
+	g.compact.Store(nil)
 }
 
 // AddEdgeUnchecked adds an edge without duplicate checking.
@@ -68,6 +106,7 @@ func (g *Graph) AddEdgeUnchecked(source, target string) {
 	src.OutLinks = append(src.OutLinks, tgt)
 	tgt.InLinks = append(tgt.InLinks, src)
 	g.edges++
+	g.compact.Store(nil)
 }
 
 // RemoveOutLinks removes all outgoing edges from a node.
@@ -95,12 +134,108 @@ func (g *Graph) RemoveOutLinks(title string) {
 
 	// Clear outlinks
 	node.OutLinks = nil
+	g.compact.Store(nil)
 }
 
+// GetNode returns the node of a page, following a redirect title to its
+// canonical page and normalizing a title it does not know as given; the
+// node's Title is the canonical title.
 func (g *Graph) GetNode(title string) *Node {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
-	return g.nodes[title]
+	return g.nodes[g.redirects.resolve(title, g.hasNodeLocked)]
+}
+
+// HasNode reports whether a page is in the graph. Redirect titles are not
+// followed; use Canonical first.
+func (g *Graph) HasNode(title string) bool {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.nodes[title] != nil
+}
+
+// SetRedirects sets the redirects Canonical and GetNode resolve.
+func (g *Graph) SetRedirects(r *Redirects) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.redirects = r
+	g.compact.Store(nil)
+}
+
+// Canonical returns the page a title redirects to, or the title itself if
+// it is not a redirect. A title that is neither a page nor a redirect is
+// looked up again in normalized form.
+func (g *Graph) Canonical(title string) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.redirects.resolve(title, g.hasNodeLocked)
+}
+
+// hasNodeLocked reports whether a page is in the graph. Callers must hold g.mu.
+func (g *Graph) hasNodeLocked(title string) bool {
+	return g.nodes[title] != nil
+}
+
+// OutLinkTitles returns the titles a page links to, or nil if it is not in the graph.
+func (g *Graph) OutLinkTitles(title string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	node := g.nodes[title]
+	if node == nil {
+		return nil
+	}
+	return nodeTitles(node.OutLinks)
+}
+
+// InLinkTitles returns the titles linking to a page, or nil if it is not in the graph.
+func (g *Graph) InLinkTitles(title string) []string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	node := g.nodes[title]
+	if node == nil {
+		return nil
+	}
+	return nodeTitles(node.InLinks)
+}
+
+func nodeTitles(nodes []*Node) []string {
+	titles := make([]string, len(nodes))
+	for i, n := range nodes {
+		titles[i] = n.Title
+	}
+	return titles
+}
+
+// Compact returns the graph in CSR form. The result is cached until the next mutation.
+func (g *Graph) Compact() *CSR {
+	if c := g.compact.Load(); c != nil {
+		return c
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	b := NewCSRBuilder(len(g.nodes), g.edges)
+	for title, node := range g.nodes {
+		b.AddNode(title)
+		for _, target := range node.OutLinks {
+			b.AddEdge(title, target.Title)
+		}
+	}
+	c := b.Build()
+	c.redirects = g.redirects
+
+	// Store while still holding the read lock so a concurrent mutation
+	// cannot be overwritten by a stale CSR.
+	g.compact.Store(c)
+	return c
+}
+
+// Adjacency returns the cached CSR form of the graph.
+func (g *Graph) Adjacency() Adjacency {
+	return g.Compact()
 }
 
 func (g *Graph) NodeCount() int {
@@ -119,12 +254,21 @@ func (g *Graph) EdgeCount() int {
 type Subgraph struct {
 	Nodes []SubgraphNode
 	Edges []SubgraphEdge
+
+	// Truncated reports that the traversal stopped early because its
+	// context was done or its node budget ran out.
+	Truncated bool
+	// BudgetExhausted reports that the traversal hit its explored-node cap.
+	BudgetExhausted bool
 }
 
-// SubgraphNode represents a node in a subgraph with distance from center.
+// SubgraphNode represents a node in a subgraph with distance from center
+// and its link counts in the whole graph.
 type SubgraphNode struct {
-	Title string
-	Hops  int
+	Title     string
+	Hops      int
+	InDegree  int
+	OutDegree int
 }
 
 // SubgraphEdge represents an edge in a subgraph.
@@ -135,6 +279,22 @@ type SubgraphEdge struct {
 
 // GetNeighborhood returns the N-hop neighborhood around a node using BFS.
 func (g *Graph) GetNeighborhood(title string, maxDepth, maxNodes int) *Subgraph {
+	return g.GetNeighborhoodContext(context.Background(), title, maxDepth, maxNodes, 0)
+}
+
+// GetNeighborhoodContext is GetNeighborhood that stops when ctx is done or
+// after expanding maxExplored nodes (zero for no cap), returning what it has.
+func (g *Graph) GetNeighborhoodContext(ctx context.Context, title string, maxDepth, maxNodes, maxExplored int) *Subgraph {
+	return g.GetNeighborhoodWithOptions(ctx, title, NeighborhoodOptions{
+		MaxDepth:    maxDepth,
+		MaxNodes:    maxNodes,
+		MaxExplored: maxExplored,
+	})
+}
+
+// GetNeighborhoodWithOptions returns the neighborhood around a node as
+// configured by opts, or nil if the node does not exist.
+func (g *Graph) GetNeighborhoodWithOptions(ctx context.Context, title string, opts NeighborhoodOptions) *Subgraph {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -143,53 +303,9 @@ func (g *Graph) GetNeighborhood(title string, maxDepth, maxNodes int) *Subgraph
 		return nil
 	}
 
-	result := &Subgraph{
-		Nodes: make([]SubgraphNode, 0, maxNodes),
-		Edges: make([]SubgraphEdge, 0),
-	}
-
-	// Track visited nodes with their hop distance
-	visited := make(map[*Node]int)
-	visited[center] = 0
-	result.Nodes = append(result.Nodes, SubgraphNode{Title: title, Hops: 0})
-
-	// BFS queue: pairs of (node, depth)
-	type queueItem struct {
-		node  *Node
-		depth int
-	}
-	queue := []queueItem{{center, 0}}
-
-	for len(queue) > 0 && len(result.Nodes) < maxNodes {
-		item := queue[0]
-		queue = queue[1:]
-
-		if item.depth >= maxDepth {
-			continue
-		}
-
-		// Process outgoing links
-		for _, neighbor := range item.node.OutLinks {
-			// Add edge (even if neighbor was visited, we want all edges)
-			result.Edges = append(result.Edges, SubgraphEdge{
-				Source: item.node.Title,
-				Target: neighbor.Title,
-			})
-
-			// Add node if not visited
-			if _, seen := visited[neighbor]; !seen {
-				if len(result.Nodes) >= maxNodes {
-					break
-				}
-				visited[neighbor] = item.depth + 1
-				result.Nodes = append(result.Nodes, SubgraphNode{
-					Title: neighbor.Title,
-					Hops:  item.depth + 1,
-				})
-				queue = append(queue, queueItem{neighbor, item.depth + 1})
-			}
-		}
-	}
-
-	return result
+	return neighborhood(ctx, center, opts,
+		func(n *Node) []*Node { return n.OutLinks },
+		func(n *Node) []*Node { return n.InLinks },
+		func(n *Node) string { return n.Title },
+	)
 }
@@ -0,0 +1,557 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+)
+
+// LandmarkStrategy selects how ALT landmarks are chosen.
+type LandmarkStrategy string
+
+const (
+	// LandmarksByDegree picks the best-linked pages, skipping neighbours
+	// of pages already picked so the landmarks spread out.
+	LandmarksByDegree LandmarkStrategy = "degree"
+	// LandmarksRandom picks pages uniformly at random.
+	LandmarksRandom LandmarkStrategy = "random"
+)
+
+// ParseLandmarkStrategy validates a landmark strategy name. The empty
+// string selects LandmarksByDegree.
+func ParseLandmarkStrategy(name string) (LandmarkStrategy, error) {
+	switch LandmarkStrategy(name) {
+	case "", LandmarksByDegree:
+		return LandmarksByDegree, nil
+	case LandmarksRandom:
+		return LandmarksRandom, nil
+	default:
+		return "", fmt.Errorf("unknown landmark strategy %q (want %q or %q)",
+			name, LandmarksByDegree, LandmarksRandom)
+	}
+}
+
+// LandmarkOptions configures ComputeLandmarks. Zero fields take the defaults.
+type LandmarkOptions struct {
+	// Count is the number of landmarks. More landmarks give tighter
+	// bounds but cost 2 bytes per page each. Default 16.
+	Count int
+
+	// Strategy selects how landmarks are picked. Default LandmarksByDegree.
+	Strategy LandmarkStrategy
+
+	// Seed seeds the random strategy and is ignored by the others.
+	Seed uint64
+}
+
+func (o LandmarkOptions) withDefaults() LandmarkOptions {
+	if o.Count <= 0 {
+		o.Count = 16
+	}
+	if o.Strategy == "" {
+		o.Strategy = LandmarksByDegree
+	}
+	if o.Strategy != LandmarksRandom {
+		o.Seed = 0
+	}
+	return o
+}
+
+// Distances are stored in one byte. Longer distances are clamped, which
+// keeps the heuristic admissible and consistent, only less sharp.
+const (
+	landmarkUnreachable = math.MaxUint8
+	landmarkMaxDistance = landmarkUnreachable - 1
+)
+
+// Landmarks holds BFS distances between every page and a small set of
+// landmark pages. By the triangle inequality they give a lower bound on
+// the distance between any two pages, which guides an A* search (ALT)
+// towards the target.
+type Landmarks struct {
+	Options LandmarkOptions
+
+	ids  []uint32
+	from [][]uint8 // from[i][v] is the distance from landmark i to v
+	to   [][]uint8 // to[i][v] is the distance from v to landmark i
+	a    Adjacency
+}
+
+// ComputeLandmarks picks landmarks in a and runs a forward and a backward
+// BFS from each of them. It returns ctx.Err() if ctx is done first.
+func ComputeLandmarks(ctx context.Context, a Adjacency, opts LandmarkOptions) (*Landmarks, error) {
+	opts = opts.withDefaults()
+	n := a.NodeCount()
+
+	var ids []uint32
+	switch k := min(opts.Count, n); opts.Strategy {
+	case LandmarksRandom:
+		ids = samplePivots(n, k, opts.Seed)
+	default:
+		ids = degreeLandmarks(a, k)
+	}
+
+	l := &Landmarks{
+		Options: opts,
+		ids:     ids,
+		from:    make([][]uint8, len(ids)),
+		to:      make([][]uint8, len(ids)),
+		a:       a,
+	}
+
+	jobs := 2 * len(ids)
+	parallelRange(jobs, min(runtime.GOMAXPROCS(0), max(jobs, 1)), func(j int) {
+		if j%2 == 0 {
+			l.from[j/2] = landmarkDistances(ctx, n, ids[j/2], a.Out)
+		} else {
+			l.to[j/2] = landmarkDistances(ctx, n, ids[j/2], a.In)
+		}
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// degreeLandmarks returns the k pages with the most links in and out,
+// passing over pages next to one already chosen while others remain.
+func degreeLandmarks(a Adjacency, k int) []uint32 {
+	n := a.NodeCount()
+	order := make([]uint32, n)
+	for i := range order {
+		order[i] = uint32(i)
+	}
+	degree := func(v uint32) int { return len(a.Out(v)) + len(a.In(v)) }
+	slices.SortFunc(order, func(x, y uint32) int {
+		return cmp.Or(cmp.Compare(degree(y), degree(x)), cmp.Compare(x, y))
+	})
+
+	chosen := make([]uint32, 0, k)
+	covered := make(map[uint32]bool)
+	var skipped []uint32
+	for _, v := range order {
+		if len(chosen) == k {
+			break
+		}
+		if covered[v] {
+			skipped = append(skipped, v)
+			continue
+		}
+		chosen = append(chosen, v)
+		covered[v] = true
+		for _, w := range a.Out(v) {
+			covered[w] = true
+		}
+		for _, w := range a.In(v) {
+			covered[w] = true
+		}
+	}
+	for _, v := range skipped {
+		if len(chosen) == k {
+			break
+		}
+		chosen = append(chosen, v)
+	}
+	return chosen
+}
+
+// landmarkDistances runs a BFS from root along next and returns the depth
+// of every page, clamped to landmarkMaxDistance. It stops early, leaving
+// the result incomplete, once ctx is done.
+func landmarkDistances(ctx context.Context, n int, root uint32, next func(uint32) []uint32) []uint8 {
+	dist := make([]uint8, n)
+	for i := range dist {
+		dist[i] = landmarkUnreachable
+	}
+	depth := make([]int32, 0, 64)
+	queue := make([]uint32, 0, 64)
+
+	dist[root] = 0
+	queue = append(queue, root)
+	depth = append(depth, 0)
+	for head := 0; head < len(queue); head++ {
+		if head%cancelCheckInterval == 0 && ctx.Err() != nil {
+			return dist
+		}
+		d := depth[head] + 1
+		for _, w := range next(queue[head]) {
+			if dist[w] != landmarkUnreachable {
+				continue
+			}
+			dist[w] = uint8(min(d, landmarkMaxDistance))
+			queue = append(queue, w)
+			depth = append(depth, d)
+		}
+	}
+	return dist
+}
+
+// Count returns the number of landmarks.
+func (l *Landmarks) Count() int {
+	return len(l.ids)
+}
+
+// Titles returns the landmark pages in the order they were picked.
+func (l *Landmarks) Titles() []string {
+	titles := make([]string, len(l.ids))
+	for i, id := range l.ids {
+		titles[i] = l.a.Title(id)
+	}
+	return titles
+}
+
+// bound returns a lower bound on the distance from v to the target whose
+// landmark distances are fromT and toT, or -1 if v cannot reach it:
+//
+//	d(v,t) >= d(L,t) - d(L,v)   and   d(v,t) >= d(v,L) - d(t,L)
+func (l *Landmarks) bound(v uint32, fromT, toT []uint8) int {
+	best := 0
+	for i := range l.ids {
+		fv, tv := l.from[i][v], l.to[i][v]
+		if fv != landmarkUnreachable {
+			if fromT[i] == landmarkUnreachable {
+				// L reaches v but not t, so v cannot reach t either.
+				return -1
+			}
+			best = max(best, int(fromT[i])-int(fv))
+		}
+		if toT[i] != landmarkUnreachable {
+			if tv == landmarkUnreachable {
+				// t reaches L but v does not, so v cannot reach t.
+				return -1
+			}
+			best = max(best, int(tv)-int(toT[i]))
+		}
+	}
+	return best
+}
+
+// DistanceEstimate bounds the number of hops between two pages.
+type DistanceEstimate struct {
+	// Lower is a lower bound on the hop count.
+	Lower int
+	// Upper is an upper bound on the hop count, from the shortest detour
+	// through a landmark, or -1 if no landmark lies on a known route.
+	Upper int
+	// Unreachable reports that the landmarks prove there is no path; both
+	// bounds are then -1.
+	Unreachable bool
+}
+
+// Exact reports whether the bounds pin down the distance.
+func (e DistanceEstimate) Exact() bool {
+	return e.Unreachable || e.Lower == e.Upper
+}
+
+// Estimate bounds the distance from one page to another from the landmark
+// tables alone, without searching. It reports false if either page is not
+// in the graph.
+func (l *Landmarks) Estimate(from, to string) (DistanceEstimate, bool) {
+	fromID, okFrom := l.a.Lookup(from)
+	toID, okTo := l.a.Lookup(to)
+	if !okFrom || !okTo {
+		return DistanceEstimate{}, false
+	}
+	if fromID == toID {
+		return DistanceEstimate{}, true
+	}
+
+	fromT := make([]uint8, len(l.ids))
+	toT := make([]uint8, len(l.ids))
+	for i := range l.ids {
+		fromT[i], toT[i] = l.from[i][toID], l.to[i][toID]
+	}
+	lower := l.bound(fromID, fromT, toT)
+	if lower < 0 {
+		return DistanceEstimate{Lower: -1, Upper: -1, Unreachable: true}, true
+	}
+
+	// d(v,t) <= d(v,L) + d(L,t). Clamped distances are only lower bounds,
+	// so they cannot give an upper one.
+	upper := -1
+	for i := range l.ids {
+		dv, dt := l.to[i][fromID], fromT[i]
+		if dv >= landmarkMaxDistance || dt >= landmarkMaxDistance {
+			continue
+		}
+		if d := int(dv) + int(dt); upper < 0 || d < upper {
+			upper = d
+		}
+	}
+	// A page next to the target is at most one hop away, even when every
+	// landmark route runs longer.
+	if (upper < 0 || upper > 1) && slices.Contains(l.a.Out(fromID), toID) {
+		upper = 1
+	}
+	return DistanceEstimate{Lower: max(lower, 1), Upper: upper}, true
+}
+
+// FindPath finds a shortest path with A* search, using the landmark
+// distances as the heuristic. It searches the graph the landmarks were
+// computed for, gives up after expanding maxExplored nodes (zero for no
+// cap) or when ctx is done, and never returns a path longer than maxDepth
+// hops (negative for no limit).
+func (l *Landmarks) FindPath(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult {
+	a := l.a
+	fromID, okFrom := a.Lookup(from)
+	toID, okTo := a.Lookup(to)
+
+	if !okFrom || !okTo {
+		return PathResult{}
+	}
+
+	if fromID == toID {
+		return PathResult{Found: true, Path: []string{a.Title(fromID)}, Hops: 0, Explored: 1}
+	}
+
+	fromT := make([]uint8, len(l.ids))
+	toT := make([]uint8, len(l.ids))
+	for i := range l.ids {
+		fromT[i], toT[i] = l.from[i][toID], l.to[i][toID]
+	}
+
+	b := newBudget(ctx, maxExplored)
+	h := l.bound(fromID, fromT, toT)
+	if h < 0 || (maxDepth >= 0 && h > maxDepth) {
+		return b.finish(PathResult{})
+	}
+
+	// The heuristic is consistent, so f = g + h never decreases along a
+	// path and a page has its final distance when it is first expanded.
+	// That makes a bucket queue indexed by f enough.
+	dist := map[uint32]int{fromID: 0}
+	parent := map[uint32]uint32{fromID: fromID}
+	closed := make(map[uint32]bool)
+	buckets := make([][]uint32, h+1)
+	buckets[h] = append(buckets[h], fromID)
+
+	for f := h; f < len(buckets); f++ {
+		for len(buckets[f]) > 0 {
+			last := len(buckets[f]) - 1
+			current := buckets[f][last]
+			buckets[f] = buckets[f][:last]
+			if closed[current] {
+				continue
+			}
+			closed[current] = true
+			if current == toID {
+				return b.finish(PathResult{
+					Found: true,
+					Path:  reconstructIndexedPath(a, parent, toID),
+					Hops:  dist[toID],
+				})
+			}
+			if !b.spend() {
+				return b.finish(PathResult{})
+			}
+
+			g := dist[current] + 1
+			for _, neighbor := range a.Out(current) {
+				if d, seen := dist[neighbor]; seen && d <= g {
+					continue
+				}
+				if neighbor == toID && g == f {
+					// Nothing left in the queue can lead to a shorter path.
+					parent[neighbor] = current
+					return b.finish(PathResult{
+						Found: true,
+						Path:  reconstructIndexedPath(a, parent, toID),
+						Hops:  g,
+					})
+				}
+				h := l.bound(neighbor, fromT, toT)
+				if h < 0 || (maxDepth >= 0 && g+h > maxDepth) {
+					continue
+				}
+				dist[neighbor] = g
+				parent[neighbor] = current
+				for len(buckets) <= g+h {
+					buckets = append(buckets, nil)
+				}
+				buckets[g+h] = append(buckets[g+h], neighbor)
+			}
+		}
+	}
+
+	return b.finish(PathResult{})
+}
+
+// Landmark cache file layout (little-endian):
+//
+//	magic          [8]byte "WGLMARK\0"
+//	version        uint32
+//	graphKey       uint32  fingerprint of the graph the landmarks belong to
+//	checksum       uint32  CRC32-C of everything after the header
+//	landmarks      uint32
+//	nodeCount      uint64
+//	count          uint32  requested number of landmarks
+//	strategy       uint8
+//	padding        to 40
+//	seed           uint64
+//	padding        to 64 bytes
+//	ids            uint32 × landmarks
+//	from           uint8 × nodeCount, per landmark
+//	to             uint8 × nodeCount, per landmark
+const (
+	landmarksMagic      = "WGLMARK\x00"
+	landmarksVersion    = 2
+	landmarksHeaderSize = 64
+)
+
+// ErrStaleLandmarks is returned when a landmark cache belongs to a
+// different graph, or to the same pages numbered differently.
+var ErrStaleLandmarks = errors.New("landmark cache does not match the graph")
+
+var landmarkStrategyCodes = []LandmarkStrategy{LandmarksByDegree, LandmarksRandom}
+
+// LandmarksPath returns where the landmark distances for the graph cache
+// at cachePath are stored.
+func LandmarksPath(cachePath string) string {
+	return cachePath + ".landmarks"
+}
+
+// SaveLandmarks writes landmark distances to path, tagged with graphKey,
+// the fingerprint of the graph they were computed from.
+// Uses atomic write (temp file + rename) to prevent corruption.
+func SaveLandmarks(path string, l *Landmarks, graphKey uint32) error {
+	k, n := len(l.ids), l.a.NodeCount()
+	buf := make([]byte, landmarksHeaderSize+4*k+2*k*n)
+
+	body := buf[landmarksHeaderSize:]
+	for i, id := range l.ids {
+		binary.LittleEndian.PutUint32(body[4*i:], id)
+	}
+	tables := body[4*k:]
+	for i := range l.ids {
+		copy(tables[i*n:], l.from[i])
+		copy(tables[(k+i)*n:], l.to[i])
+	}
+
+	copy(buf, landmarksMagic)
+	binary.LittleEndian.PutUint32(buf[8:], landmarksVersion)
+	binary.LittleEndian.PutUint32(buf[12:], graphKey)
+	binary.LittleEndian.PutUint32(buf[16:], crc32.Checksum(body, crcTable))
+	binary.LittleEndian.PutUint32(buf[20:], uint32(k))
+	binary.LittleEndian.PutUint64(buf[24:], uint64(n))
+	binary.LittleEndian.PutUint32(buf[32:], uint32(l.Options.Count))
+	for code, s := range landmarkStrategyCodes {
+		if s == l.Options.Strategy {
+			buf[36] = byte(code)
+		}
+	}
+	binary.LittleEndian.PutUint64(buf[40:], l.Options.Seed)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing landmark cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming landmark cache: %w", err)
+	}
+	return nil
+}
+
+// LoadLandmarks reads landmark distances saved by SaveLandmarks and
+// attaches them to a. Returns ErrStaleLandmarks if the file was computed
+// from another graph.
+func LoadLandmarks(path string, a Adjacency, graphKey uint32) (*Landmarks, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < landmarksHeaderSize || string(buf[:8]) != landmarksMagic {
+		return nil, errors.New("not a landmark cache")
+	}
+	if v := binary.LittleEndian.Uint32(buf[8:]); v != landmarksVersion {
+		return nil, fmt.Errorf("landmark cache version mismatch: got %d, want %d", v, landmarksVersion)
+	}
+
+	k := uint64(binary.LittleEndian.Uint32(buf[20:]))
+	n := binary.LittleEndian.Uint64(buf[24:])
+	if binary.LittleEndian.Uint32(buf[12:]) != graphKey || n != uint64(a.NodeCount()) {
+		return nil, ErrStaleLandmarks
+	}
+	if want := landmarksHeaderSize + 4*k + 2*k*n; uint64(len(buf)) != want {
+		return nil, fmt.Errorf("landmark cache truncated: %d bytes, want %d", len(buf), want)
+	}
+	body := buf[landmarksHeaderSize:]
+	if crc32.Checksum(body, crcTable) != binary.LittleEndian.Uint32(buf[16:]) {
+		return nil, errors.New("landmark cache checksum mismatch")
+	}
+	code := int(buf[36])
+	if code >= len(landmarkStrategyCodes) {
+		return nil, fmt.Errorf("landmark cache has unknown strategy %d", code)
+	}
+
+	l := &Landmarks{
+		Options: LandmarkOptions{
+			Count:    int(binary.LittleEndian.Uint32(buf[32:])),
+			Strategy: landmarkStrategyCodes[code],
+			Seed:     binary.LittleEndian.Uint64(buf[40:]),
+		},
+		ids:  make([]uint32, k),
+		from: make([][]uint8, k),
+		to:   make([][]uint8, k),
+		a:    a,
+	}
+	for i := range l.ids {
+		l.ids[i] = binary.LittleEndian.Uint32(body[4*i:])
+		if uint64(l.ids[i]) >= n {
+			return nil, fmt.Errorf("landmark cache has out-of-range page %d", l.ids[i])
+		}
+	}
+	tables := body[4*k:]
+	for i := range l.ids {
+		l.from[i] = tables[uint64(i)*n : uint64(i+1)*n]
+		l.to[i] = tables[(k+uint64(i))*n : (k+uint64(i)+1)*n]
+	}
+	return l, nil
+}
+
+// CachedLandmarks returns landmarks for a, reusing the distances stored
+// next to the graph cache at cachePath when they were computed from a
+// graph with the same fingerprint and the same options, and storing freshly
+// computed ones otherwise. Nothing is cached when there is no graph cache.
+func CachedLandmarks(ctx context.Context, a Adjacency, cachePath string, opts LandmarkOptions) (*Landmarks, error) {
+	opts = opts.withDefaults()
+
+	var key uint32
+	cacheable := CacheExists(cachePath)
+	if cacheable {
+		key = fingerprint(a)
+		l, err := LoadLandmarks(LandmarksPath(cachePath), a, key)
+		switch {
+		case err == nil && l.Options == opts:
+			slog.Debug("landmarks loaded from cache", "path", LandmarksPath(cachePath))
+			return l, nil
+		case err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, ErrStaleLandmarks):
+			slog.Warn("ignoring unreadable landmark cache", "error", err)
+		}
+	}
+
+	l, err := ComputeLandmarks(ctx, a, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		if err := SaveLandmarks(LandmarksPath(cachePath), l, key); err != nil {
+			slog.Warn("failed to save landmark cache", "error", err)
+		}
+	}
+	return l, nil
+}
@@ -0,0 +1,258 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"path/filepath"
+	"testing"
+)
+
+func randomCSR(n, edges int, seed uint64) *CSR {
+	rng := rand.New(rand.NewPCG(seed, seed))
+	b := NewCSRBuilder(n, edges)
+	for range edges {
+		b.AddEdge(nodeName(rng.IntN(n)), nodeName(rng.IntN(n)))
+	}
+	return b.Build()
+}
+
+func TestLandmarksFindPathMatchesBFS(t *testing.T) {
+	ctx := context.Background()
+	c := randomCSR(300, 900, 7)
+
+	for _, strategy := range []LandmarkStrategy{LandmarksByDegree, LandmarksRandom} {
+		l, err := ComputeLandmarks(ctx, c, LandmarkOptions{Count: 4, Strategy: strategy, Seed: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.Count() != 4 {
+			t.Fatalf("%s: Count = %d, want 4", strategy, l.Count())
+		}
+
+		for i := range 300 {
+			from, to := nodeName(i), nodeName((i*37+11)%300)
+			want := findPathIndexed(ctx, c, from, to, -1, 0)
+			got := l.FindPath(ctx, from, to, -1, 0)
+
+			if got.Found != want.Found || got.Hops != want.Hops {
+				t.Fatalf("%s: %s -> %s: found %v in %d hops, want %v in %d",
+					strategy, from, to, got.Found, got.Hops, want.Found, want.Hops)
+			}
+			if got.Found && !isPath(c, got.Path) {
+				t.Fatalf("%s: %s -> %s: %v is not a path", strategy, from, to, got.Path)
+			}
+		}
+	}
+}
+
+func TestLandmarksFindPathExploresLess(t *testing.T) {
+	ctx := context.Background()
+	c := buildChainGraph(2000).Compact()
+
+	l, err := ComputeLandmarks(ctx, c, LandmarkOptions{Count: 2, Strategy: LandmarksRandom, Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := nodeName(500), nodeName(1500)
+	bfs := findPathIndexed(ctx, c, from, to, -1, 0)
+	alt := l.FindPath(ctx, from, to, -1, 0)
+	if !alt.Found || alt.Hops != 1000 {
+		t.Fatalf("found %v in %d hops, want 1000", alt.Found, alt.Hops)
+	}
+	if alt.Explored > bfs.Explored {
+		t.Errorf("ALT explored %d nodes, BFS %d", alt.Explored, bfs.Explored)
+	}
+
+	// Distances past 254 hops are clamped but still bound the search.
+	if r := l.FindPath(ctx, nodeName(0), nodeName(1999), -1, 0); !r.Found || r.Hops != 1999 {
+		t.Errorf("long path: found %v in %d hops, want 1999", r.Found, r.Hops)
+	}
+
+	// The heuristic rules out a path the wrong way down the chain.
+	if r := l.FindPath(ctx, to, from, -1, 0); r.Found || r.Explored > 1 {
+		t.Errorf("reverse: found %v after %d nodes, want an early miss", r.Found, r.Explored)
+	}
+}
+
+func TestLandmarksFindPathLimits(t *testing.T) {
+	ctx := context.Background()
+	c := buildChainGraph(100).Compact()
+
+	l, err := ComputeLandmarks(ctx, c, LandmarkOptions{Count: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := l.FindPath(ctx, nodeName(0), nodeName(50), 10, 0); r.Found {
+		t.Errorf("max depth 10: found %d-hop path", r.Hops)
+	}
+	r := l.FindPath(ctx, nodeName(0), nodeName(50), -1, 5)
+	if r.Found || !r.BudgetExhausted || r.Explored != 5 {
+		t.Errorf("budget: %+v, want exhausted after 5 nodes", r)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if r := l.FindPath(canceled, nodeName(0), nodeName(50), -1, 0); r.Found || !r.Truncated {
+		t.Errorf("canceled: %+v, want truncated", r)
+	}
+	if _, err := ComputeLandmarks(canceled, c, LandmarkOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ComputeLandmarks err = %v, want context.Canceled", err)
+	}
+}
+
+func TestLandmarksEstimate(t *testing.T) {
+	ctx := context.Background()
+	c := randomCSR(300, 900, 11)
+
+	l, err := ComputeLandmarks(ctx, c, LandmarkOptions{Count: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	exact := 0
+	for i := range 300 {
+		from, to := nodeName(i), nodeName((i*53+7)%300)
+		e, ok := l.Estimate(from, to)
+		if !ok {
+			t.Fatalf("%s -> %s: page not found", from, to)
+		}
+		want := findPathIndexed(ctx, c, from, to, -1, 0)
+
+		switch {
+		case e.Unreachable:
+			if want.Found {
+				t.Fatalf("%s -> %s: estimated unreachable, BFS found %d hops", from, to, want.Hops)
+			}
+		case want.Found:
+			if e.Lower > want.Hops || (e.Upper >= 0 && e.Upper < want.Hops) {
+				t.Fatalf("%s -> %s: bounds [%d, %d] miss %d hops", from, to, e.Lower, e.Upper, want.Hops)
+			}
+		case e.Upper >= 0:
+			t.Fatalf("%s -> %s: upper bound %d, but no path exists", from, to, e.Upper)
+		}
+		if e.Exact() {
+			exact++
+		}
+	}
+	if exact == 0 {
+		t.Error("no estimate was exact")
+	}
+
+	chain := buildChainGraph(10).Compact()
+	l, err = ComputeLandmarks(ctx, chain, LandmarkOptions{Count: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The landmark is node_1, behind both pages: it bounds the distance
+	// from below but offers no route.
+	if e, _ := l.Estimate(nodeName(2), nodeName(7)); e.Lower != 5 || e.Upper != -1 {
+		t.Errorf("chain estimate = %+v, want lower bound 5 and no upper bound", e)
+	}
+
+	// With every page a landmark, each estimate is exact.
+	l, err = ComputeLandmarks(ctx, chain, LandmarkOptions{Count: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e, _ := l.Estimate(nodeName(2), nodeName(7)); !e.Exact() || e.Lower != 5 {
+		t.Errorf("chain estimate = %+v, want exactly 5", e)
+	}
+	if e, _ := l.Estimate(nodeName(7), nodeName(2)); !e.Unreachable {
+		t.Errorf("reverse chain estimate = %+v, want unreachable", e)
+	}
+	if _, ok := l.Estimate(nodeName(0), "missing"); ok {
+		t.Error("estimate for a missing page should report false")
+	}
+}
+
+func TestDegreeLandmarksSpreadOut(t *testing.T) {
+	// H1 and H2 are the best-linked pages, but H2 is next to H1.
+	c := buildCSR([][2]string{
+		{"H1", "A"}, {"H1", "B"}, {"H1", "C"}, {"H1", "H2"},
+		{"H2", "D"}, {"H2", "E"}, {"H2", "I"},
+		{"F", "G"}, {"G", "F"}, {"F", "A"},
+	})
+
+	l, err := ComputeLandmarks(context.Background(), c, LandmarkOptions{Count: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := l.Titles(); len(got) != 2 || got[0] != "H1" || got[1] != "F" {
+		t.Errorf("landmarks = %v, want [H1 F]", got)
+	}
+}
+
+func TestCachedLandmarks(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "graph.cache")
+	ctx := context.Background()
+
+	c := randomCSR(100, 300, 1)
+	if err := c.Save(cachePath); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := CachedLandmarks(ctx, c, cachePath, LandmarkOptions{Count: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CacheExists(LandmarksPath(cachePath)) {
+		t.Fatal("landmark cache was not written")
+	}
+
+	key := fingerprint(c)
+	loaded, err := LoadLandmarks(LandmarksPath(cachePath), c, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Options != first.Options || !equalSlices(loaded.Titles(), first.Titles()) {
+		t.Errorf("loaded %+v %v, want %+v %v", loaded.Options, loaded.Titles(), first.Options, first.Titles())
+	}
+	for i := range 100 {
+		from, to := nodeName(i), nodeName(99-i)
+		if a, b := first.FindPath(ctx, from, to, -1, 0), loaded.FindPath(ctx, from, to, -1, 0); a.Hops != b.Hops || a.Explored != b.Explored {
+			t.Fatalf("%s -> %s: loaded landmarks search differently", from, to)
+		}
+	}
+
+	if _, err := LoadLandmarks(LandmarksPath(cachePath), c, key+1); !errors.Is(err, ErrStaleLandmarks) {
+		t.Errorf("other graph: err = %v, want ErrStaleLandmarks", err)
+	}
+
+	// Different options are recomputed, not served from the cache.
+	other, err := CachedLandmarks(ctx, c, cachePath, LandmarkOptions{Count: 3, Strategy: LandmarksRandom})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Options.Strategy != LandmarksRandom {
+		t.Errorf("strategy = %q, want random", other.Options.Strategy)
+	}
+}
+
+func TestParseLandmarkStrategy(t *testing.T) {
+	if s, err := ParseLandmarkStrategy(""); err != nil || s != LandmarksByDegree {
+		t.Errorf("empty: %q, %v", s, err)
+	}
+	if _, err := ParseLandmarkStrategy("farthest"); err == nil {
+		t.Error("expected an error for an unknown strategy")
+	}
+}
+
+// isPath reports whether every step of path follows a link in a.
+func isPath(a Adjacency, path []string) bool {
+	for i := 1; i < len(path); i++ {
+		u, _ := a.Lookup(path[i-1])
+		v, _ := a.Lookup(path[i])
+		found := false
+		for _, w := range a.Out(u) {
+			found = found || w == v
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
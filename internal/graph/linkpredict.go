@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// LinkScorer selects how SuggestLinks scores a page that is not yet linked.
+type LinkScorer string
+
+const (
+	// ScorerCommonNeighbors counts the pages linked to or from both pages.
+	ScorerCommonNeighbors LinkScorer = "common_neighbors"
+	// ScorerAdamicAdar sums 1/log(degree) over the shared neighbours.
+	ScorerAdamicAdar LinkScorer = "adamic_adar"
+	// ScorerResourceAllocation sums 1/degree over the shared neighbours,
+	// penalising hubs more strongly than Adamic-Adar.
+	ScorerResourceAllocation LinkScorer = "resource_allocation"
+)
+
+// ParseLinkScorer validates a link scorer name. The empty string selects
+// ScorerAdamicAdar.
+func ParseLinkScorer(name string) (LinkScorer, error) {
+	switch LinkScorer(name) {
+	case "":
+		return ScorerAdamicAdar, nil
+	case ScorerCommonNeighbors, ScorerAdamicAdar, ScorerResourceAllocation:
+		return LinkScorer(name), nil
+	default:
+		return "", fmt.Errorf("unknown link scorer %q (want %q, %q or %q)",
+			name, ScorerCommonNeighbors, ScorerAdamicAdar, ScorerResourceAllocation)
+	}
+}
+
+// weight returns how much a shared neighbour of the given degree adds to
+// a score, or nil when each counts 1.
+func (s LinkScorer) weight() func(degree int) float64 {
+	switch s {
+	case ScorerAdamicAdar:
+		return adamicAdarWeight
+	case ScorerResourceAllocation:
+		return func(degree int) float64 { return 1 / float64(degree) }
+	default:
+		return nil
+	}
+}
+
+// LinkPredictionOptions configures SuggestLinks. Zero fields take the
+// defaults.
+type LinkPredictionOptions struct {
+	// Scorer selects the score. Default ScorerAdamicAdar.
+	Scorer LinkScorer
+
+	// Limit is the most suggestions returned. Default 10.
+	Limit int
+
+	// MaxShared is the most shared neighbours listed with each
+	// suggestion. Default 10.
+	MaxShared int
+}
+
+func (o LinkPredictionOptions) withDefaults() LinkPredictionOptions {
+	if o.Scorer == "" {
+		o.Scorer = ScorerAdamicAdar
+	}
+	if o.Limit <= 0 {
+		o.Limit = 10
+	}
+	if o.MaxShared <= 0 {
+		o.MaxShared = 10
+	}
+	return o
+}
+
+// LinkSuggestion is a page that a page does not link to but probably
+// should.
+type LinkSuggestion struct {
+	Title string
+	Score float64
+	// Shared lists the neighbours supporting the suggestion, those that
+	// contribute most first.
+	Shared []string
+	// SharedCount is the number of shared neighbours, which may exceed
+	// len(Shared).
+	SharedCount int
+}
+
+// SuggestLinks predicts the links missing from title: pages it does not
+// link to that share the most neighbours with it, taking links in both
+// directions. Suggestions come best first, ties broken by title. Returns
+// ErrPageNotFound if title is not in the graph and ctx.Err() if ctx is
+// done first.
+func SuggestLinks(ctx context.Context, a Adjacency, title string, opts LinkPredictionOptions) ([]LinkSuggestion, error) {
+	opts = opts.withDefaults()
+	if _, err := ParseLinkScorer(string(opts.Scorer)); err != nil {
+		return nil, err
+	}
+
+	src, ok := a.Lookup(title)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPageNotFound, title)
+	}
+
+	own, shared, err := sharedNeighbors(ctx, a, src, sideBoth, opts.Scorer.weight())
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range a.Out(src) {
+		delete(shared, v)
+	}
+
+	suggestions := make([]LinkSuggestion, 0, len(shared))
+	for y, score := range shared {
+		suggestions = append(suggestions, LinkSuggestion{Title: a.Title(y), Score: score})
+	}
+	slices.SortFunc(suggestions, func(x, y LinkSuggestion) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Title, y.Title)
+	})
+	suggestions = suggestions[:min(opts.Limit, len(suggestions))]
+
+	// Only the suggestions returned need their evidence, found by
+	// intersecting sorted neighbour lists.
+	var buf []uint32
+	for i := range suggestions {
+		y, _ := a.Lookup(suggestions[i].Title)
+		buf = neighbors(a, y, sideBoth, buf)
+		common := intersectSorted(own, buf)
+		suggestions[i].SharedCount = len(common)
+
+		// Rarely linked neighbours say most about a pair, so they
+		// come first.
+		degrees := make(map[uint32]int, len(common))
+		for _, z := range common {
+			degrees[z] = len(neighbors(a, z, sideBoth, buf))
+		}
+		slices.SortFunc(common, func(x, y uint32) int {
+			if c := cmp.Compare(degrees[x], degrees[y]); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.Title(x), a.Title(y))
+		})
+		for _, z := range common[:min(opts.MaxShared, len(common))] {
+			suggestions[i].Shared = append(suggestions[i].Shared, a.Title(z))
+		}
+	}
+	return suggestions, nil
+}
+
+// intersectSorted returns the values present in both ascending slices.
+func intersectSorted(x, y []uint32) []uint32 {
+	var out []uint32
+	for i, j := 0, 0; i < len(x) && j < len(y); {
+		switch {
+		case x[i] < y[j]:
+			i++
+		case x[i] > y[j]:
+			j++
+		default:
+			out = append(out, x[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSuggestLinks(t *testing.T) {
+	// Cat links to Mammal and Pet; Dog is linked with both but not from
+	// Cat. Lion shares only Mammal, which is also linked with Whale.
+	c := buildCSR([][2]string{
+		{"Cat", "Mammal"}, {"Cat", "Pet"},
+		{"Dog", "Mammal"}, {"Pet", "Dog"},
+		{"Lion", "Mammal"}, {"Whale", "Mammal"},
+	})
+	ctx := context.Background()
+
+	// Mammal is linked with Cat, Dog, Lion and Whale; Pet with Cat and Dog.
+	tests := []struct {
+		scorer LinkScorer
+		dog    float64
+		lion   float64
+	}{
+		{ScorerCommonNeighbors, 2, 1},
+		{ScorerAdamicAdar, 1/math.Log(4) + 1/math.Log(2), 1 / math.Log(4)},
+		{ScorerResourceAllocation, 1.0/4 + 1.0/2, 1.0 / 4},
+	}
+	for _, tt := range tests {
+		got, err := SuggestLinks(ctx, c, "Cat", LinkPredictionOptions{Scorer: tt.scorer})
+		if err != nil {
+			t.Fatalf("SuggestLinks(%s) error: %v", tt.scorer, err)
+		}
+		if len(got) != 3 || got[0].Title != "Dog" || got[1].Title != "Lion" || got[2].Title != "Whale" {
+			t.Fatalf("SuggestLinks(%s) = %v, want Dog, Lion, Whale", tt.scorer, got)
+		}
+		if math.Abs(got[0].Score-tt.dog) > 1e-9 || math.Abs(got[1].Score-tt.lion) > 1e-9 {
+			t.Errorf("SuggestLinks(%s) scores = %v, %v, want %v, %v", tt.scorer, got[0].Score, got[1].Score, tt.dog, tt.lion)
+		}
+	}
+
+	got, _ := SuggestLinks(ctx, c, "Cat", LinkPredictionOptions{Limit: 1})
+	if len(got) != 1 || got[0].SharedCount != 2 || !equalSlices(got[0].Shared, []string{"Pet", "Mammal"}) {
+		t.Errorf("Dog suggestion = %+v, want shared [Pet Mammal] (least linked first)", got)
+	}
+	got, _ = SuggestLinks(ctx, c, "Cat", LinkPredictionOptions{Limit: 1, MaxShared: 1})
+	if got[0].SharedCount != 2 || !equalSlices(got[0].Shared, []string{"Pet"}) {
+		t.Errorf("with MaxShared 1 = %+v, want shared [Pet] of 2", got[0])
+	}
+
+	// Pet already links to Dog, so Dog is not suggested for Pet.
+	got, _ = SuggestLinks(ctx, c, "Pet", LinkPredictionOptions{})
+	for _, s := range got {
+		if s.Title == "Dog" {
+			t.Errorf("SuggestLinks(Pet) suggested an existing link: %+v", s)
+		}
+	}
+
+	if _, err := SuggestLinks(ctx, c, "Missing", LinkPredictionOptions{}); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("missing page error = %v, want ErrPageNotFound", err)
+	}
+	if _, err := SuggestLinks(ctx, c, "Cat", LinkPredictionOptions{Scorer: "nope"}); err == nil {
+		t.Error("unknown scorer: expected an error")
+	}
+}
+
+func TestSuggestLinksMatchesSimilar(t *testing.T) {
+	// Adamic-Adar suggestions are the Adamic-Adar similar pages minus
+	// those already linked to.
+	c := randomCSR(80, 400, 11)
+	g := pointerGraph(c)
+	ctx := context.Background()
+
+	for u := range uint32(c.NodeCount()) {
+		title := c.Title(u)
+		similar, err := Similar(ctx, c, title, SimilarityOptions{Method: SimilarityAdamicAdar, Limit: c.NodeCount()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want []RankedPage
+		for _, p := range similar {
+			if v, _ := c.Lookup(p.Title); !c.HasEdge(u, v) {
+				want = append(want, p)
+			}
+		}
+
+		for _, a := range []Adjacency{c, g.Adjacency()} {
+			got, err := SuggestLinks(ctx, a, title, LinkPredictionOptions{Limit: c.NodeCount(), MaxShared: c.NodeCount()})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("SuggestLinks(%s): %d suggestions, want %d", title, len(got), len(want))
+			}
+			for i, s := range got {
+				if s.Title != want[i].Title || math.Abs(s.Score-want[i].Score) > 1e-9 {
+					t.Errorf("SuggestLinks(%s)[%d] = %s %v, want %s %v", title, i, s.Title, s.Score, want[i].Title, want[i].Score)
+				}
+				if s.SharedCount == 0 || len(s.Shared) != s.SharedCount {
+					t.Errorf("SuggestLinks(%s)[%d] lists %d of %d shared neighbours", title, i, len(s.Shared), s.SharedCount)
+				}
+			}
+		}
+	}
+}
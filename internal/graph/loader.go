@@ -1,13 +1,38 @@
 package graph
 
 import (
+	"errors"
 	"fmt"
 	"log/slog"
+	"os"
 	"time"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
 )
 
+// Backend selects the in-memory graph representation.
+type Backend string
+
+const (
+	// BackendPointer is the mutable pointer-based Graph.
+	BackendPointer Backend = "pointer"
+	// BackendCSR is the immutable compressed-sparse-row CSR, which uses
+	// several times less memory on large graphs.
+	BackendCSR Backend = "csr"
+)
+
+// ParseBackend validates a backend name. An empty name selects BackendPointer.
+func ParseBackend(name string) (Backend, error) {
+	switch Backend(name) {
+	case "", BackendPointer:
+		return BackendPointer, nil
+	case BackendCSR:
+		return BackendCSR, nil
+	default:
+		return "", fmt.Errorf("unknown graph backend %q (want %q or %q)", name, BackendPointer, BackendCSR)
+	}
+}
+
 // LoaderConfig configures the graph loader behavior.
 type LoaderConfig struct {
 	// CachePath is the path to the graph cache file.
@@ -21,6 +46,34 @@ type LoaderConfig struct {
 
 	// ForceRebuild forces a rebuild from the database, ignoring any cache.
 	ForceRebuild bool
+
+	// Backend selects the representation returned by LoadView and RebuildView.
+	// The zero value selects BackendPointer.
+	Backend Backend
+
+	// Progress, if set, is called from the loading goroutine as a load
+	// moves through its stages, and every progressInterval rows within a
+	// stage that reads rows.
+	Progress func(Progress)
+}
+
+// The stages of a load reported through LoaderConfig.Progress.
+const (
+	StageReadingCache = "reading_cache"
+	StageRedirects    = "loading_redirects"
+	StageLinks        = "loading_links"
+	StagePages        = "loading_pages"
+	StageBuilding     = "building"
+	StageSavingCache  = "saving_cache"
+)
+
+// Progress reports how far a load has got. Rows counts the rows the stage
+// has read so far and EstimatedRows guesses its final count, zero when
+// unknown. The estimate can be off either way.
+type Progress struct {
+	Stage         string
+	Rows          int64
+	EstimatedRows int64
 }
 
 // Loader loads graphs from the cache/database with optional disk caching.
@@ -48,6 +101,7 @@ func (l *Loader) Load() (*Graph, error) {
 	}
 
 	// Try to load from cache
+	l.newProgress().stage(StageReadingCache, 0)
 	g, age, err := LoadFromCache(l.config.CachePath)
 	if err == nil {
 		// Check if cache is too old
@@ -58,6 +112,13 @@ func (l *Loader) Load() (*Graph, error) {
 			)
 			// Still return the stale cache - caller can trigger background rebuild
 		}
+
+		// The snapshot holds rewritten links but not the redirect titles.
+		redirects, err := l.loadRedirects()
+		if err != nil {
+			return nil, err
+		}
+		g.SetRedirects(redirects)
 		return g, nil
 	}
 
@@ -66,37 +127,122 @@ func (l *Loader) Load() (*Graph, error) {
 	return l.loadFromDatabaseAndCache()
 }
 
-// loadFromDatabase loads the graph from the database without caching.
+// loadFromDatabase loads the graph from the database without caching,
+// adding each row to the graph as it is read.
 func (l *Loader) loadFromDatabase() (*Graph, error) {
 	start := time.Now()
 	slog.Info("loading graph from database...")
 
-	data, err := l.cache.GetGraphData()
+	progress := l.newProgress()
+	redirects, estimatedLinks, err := l.prepareLoad(progress)
 	if err != nil {
-		return nil, fmt.Errorf("loading graph data: %w", err)
+		return nil, err
 	}
+	g := NewWithCapacity(int(estimatedLinks / 5))
 
-	estimatedNodes := len(data.Edges)/5 + len(data.Nodes)
-	g := NewWithCapacity(estimatedNodes)
-
-	// Use unchecked add for bulk loading - database guarantees uniqueness
-	for _, edge := range data.Edges {
-		g.AddEdgeUnchecked(edge[0], edge[1])
+	// Use unchecked add for bulk loading - database guarantees uniqueness.
+	// Links through a redirect can duplicate a direct link, so they are
+	// added afterwards with the duplicate check.
+	var redirected [][2]string
+	err = streamGraph(progress, estimatedLinks, redirects, l.cache.ScanGraphData,
+		func(source, target string, viaRedirect bool) {
+			if viaRedirect {
+				redirected = append(redirected, [2]string{source, target})
+				return
+			}
+			g.AddEdgeUnchecked(source, target)
+		},
+		func(title string) { g.AddNode(title) },
+	)
+	if err != nil {
+		return nil, err
 	}
-
-	for _, title := range data.Nodes {
-		g.AddNode(title)
+	for _, edge := range redirected {
+		if edge[0] != edge[1] {
+			g.AddEdge(edge[0], edge[1])
+		}
 	}
+	g.SetRedirects(redirects)
 
 	slog.Info("graph loaded from database",
 		"nodes", g.NodeCount(),
 		"edges", g.EdgeCount(),
+		"redirects", redirects.Len(),
 		"duration", time.Since(start).Round(time.Millisecond),
 	)
 
 	return g, nil
 }
 
+// prepareLoad loads the redirects, which links are resolved through as
+// they stream in, and estimates the number of links to come.
+func (l *Loader) prepareLoad(progress *progressReporter) (*Redirects, int64, error) {
+	progress.stage(StageRedirects, 0)
+	redirects, err := l.loadRedirects()
+	if err != nil {
+		return nil, 0, err
+	}
+	estimatedLinks, err := l.cache.EstimateLinkCount()
+	if err != nil {
+		return nil, 0, err
+	}
+	return redirects, estimatedLinks, nil
+}
+
+// graphScan reads the rows of a graph from the database, as
+// cache.ScanGraphData does.
+type graphScan func(edge func(source, target string) error, node func(title string) error) error
+
+// streamGraph reads the rows of a graph with scan, passing each link to
+// edge and each page without links to node. A link to a redirect title is
+// passed on to its canonical page, with viaRedirect set.
+func streamGraph(
+	progress *progressReporter,
+	estimatedLinks int64,
+	redirects *Redirects,
+	scan graphScan,
+	edge func(source, target string, viaRedirect bool),
+	node func(title string),
+) error {
+	progress.stage(StageLinks, estimatedLinks)
+	pages := false
+	err := scan(func(source, target string) error {
+		canonical := redirects.Canonical(target)
+		edge(source, canonical, canonical != target)
+		progress.row()
+		return nil
+	}, func(title string) error {
+		if !pages {
+			progress.report()
+			progress.stage(StagePages, 0)
+			pages = true
+		}
+		node(title)
+		progress.row()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("loading graph data: %w", err)
+	}
+	progress.report()
+	return nil
+}
+
+// loadRedirects reads the redirect pages from the database and resolves
+// their chains. Redirect loops are logged and left unresolved.
+func (l *Loader) loadRedirects() (*Redirects, error) {
+	targets, err := l.cache.GetRedirects()
+	if err != nil {
+		return nil, fmt.Errorf("loading redirects: %w", err)
+	}
+
+	r, loops := ResolveRedirects(targets)
+	if len(loops) > 0 {
+		slog.Warn("ignoring redirect loops", "titles", len(loops), "example", loops[0])
+	}
+	return r, nil
+}
+
 // loadFromDatabaseAndCache loads from database and saves to cache.
 func (l *Loader) loadFromDatabaseAndCache() (*Graph, error) {
 	g, err := l.loadFromDatabase()
@@ -104,27 +250,176 @@ func (l *Loader) loadFromDatabaseAndCache() (*Graph, error) {
 		return nil, err
 	}
 
-	// Skip caching for very large graphs where gob serialization becomes a bottleneck.
-	// Threshold: 10M edges (~500MB cache file)
-	const maxCacheableEdges = 10_000_000
-
-	if l.config.CachePath != "" {
-		if g.EdgeCount() > maxCacheableEdges {
-			slog.Info("skipping cache save - graph exceeds cacheable size",
-				"edges", g.EdgeCount(),
-				"threshold", maxCacheableEdges)
-		} else {
-			start := time.Now()
-			if err := g.Save(l.config.CachePath); err != nil {
-				slog.Warn("failed to save graph cache", "error", err)
-				// Non-fatal - graph is still valid
-			} else {
-				slog.Info("graph cache saved", "duration", time.Since(start).Round(time.Millisecond))
-			}
+	l.saveCache(g.Save)
+	return g, nil
+}
+
+// saveCache writes the cache with save unless caching is disabled.
+func (l *Loader) saveCache(save func(path string) error) {
+	if l.config.CachePath == "" {
+		return
+	}
+
+	l.newProgress().stage(StageSavingCache, 0)
+	start := time.Now()
+	if err := save(l.config.CachePath); err != nil {
+		slog.Warn("failed to save graph cache", "error", err)
+		// Non-fatal - graph is still valid
+	} else {
+		slog.Info("graph cache saved", "duration", time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// LoadView loads the graph in the configured backend representation. A CSR
+// loaded from the cache comes with the changes saved beside it by
+// Overlay.Persist, if they were saved over the same snapshot.
+func (l *Loader) LoadView() (View, error) {
+	if l.config.Backend != BackendCSR {
+		g, err := l.Load()
+		if err != nil {
+			return nil, err
 		}
+		return g, nil
 	}
 
-	return g, nil
+	if l.config.CachePath == "" || l.config.ForceRebuild {
+		return l.loadCSRFromDatabase()
+	}
+
+	l.newProgress().stage(StageReadingCache, 0)
+	c, age, err := LoadCSRFromCache(l.config.CachePath)
+	if err == nil {
+		if l.config.MaxCacheAge > 0 && age > l.config.MaxCacheAge {
+			slog.Warn("cache is stale, will rebuild in background",
+				"age", age.Round(time.Second),
+				"max_age", l.config.MaxCacheAge,
+			)
+		}
+
+		redirects, err := l.loadRedirects()
+		if err != nil {
+			c.Close()
+			return nil, err
+		}
+		c.redirects = redirects
+
+		o, err := LoadDelta(DeltaPath(l.config.CachePath), c)
+		if err == nil {
+			slog.Info("graph delta applied", "pages_changed", o.Changed())
+			return o, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("ignoring graph delta", "reason", err)
+		}
+		return c, nil
+	}
+
+	slog.Info("cache unavailable, loading from database", "reason", err)
+	return l.loadCSRFromDatabaseAndCache()
+}
+
+// RebuildView forces a rebuild from the database in the configured backend
+// representation and updates the cache.
+func (l *Loader) RebuildView() (View, error) {
+	if l.config.Backend != BackendCSR {
+		g, err := l.Rebuild()
+		if err != nil {
+			return nil, err
+		}
+		return g, nil
+	}
+
+	slog.Info("forcing graph rebuild from database", "backend", BackendCSR)
+	return l.loadCSRFromDatabaseAndCache()
+}
+
+// loadCSRFromDatabase builds a CSR straight from the database rows,
+// never materializing the pointer-based graph.
+func (l *Loader) loadCSRFromDatabase() (*CSR, error) {
+	start := time.Now()
+	slog.Info("loading graph from database...", "backend", BackendCSR)
+
+	c, err := l.streamCSR(l.cache.ScanGraphData)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("graph loaded from database",
+		"backend", BackendCSR,
+		"nodes", c.NodeCount(),
+		"edges", c.EdgeCount(),
+		"redirects", c.redirects.Len(),
+		"memory", c.MemoryUsage(),
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+
+	return c, nil
+}
+
+// LoadAt builds the graph as it was at time t from the database's link
+// history, as a CSR with today's redirects. It neither reads nor writes the
+// cache.
+func (l *Loader) LoadAt(t time.Time) (*CSR, error) {
+	start := time.Now()
+	slog.Info("loading graph from database...", "at", t.UTC().Format(time.RFC3339))
+
+	c, err := l.streamCSR(func(edge func(source, target string) error, node func(title string) error) error {
+		return l.cache.ScanGraphDataAt(t, edge, node)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("graph loaded from database",
+		"at", t.UTC().Format(time.RFC3339),
+		"nodes", c.NodeCount(),
+		"edges", c.EdgeCount(),
+		"duration", time.Since(start).Round(time.Millisecond),
+	)
+
+	return c, nil
+}
+
+// streamCSR builds a CSR from the rows scan reads, storing links to
+// redirect titles under their canonical page. Only the builder's ID pairs
+// are held while reading, never the rows.
+func (l *Loader) streamCSR(scan graphScan) (*CSR, error) {
+	progress := l.newProgress()
+	redirects, estimatedLinks, err := l.prepareLoad(progress)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build drops the duplicates that links through redirects create.
+	b := NewCSRBuilder(int(estimatedLinks/5), int(estimatedLinks))
+	err = streamGraph(progress, estimatedLinks, redirects, scan,
+		func(source, target string, viaRedirect bool) {
+			if viaRedirect && target == source {
+				return
+			}
+			b.AddEdge(source, target)
+		},
+		func(title string) { b.AddNode(title) },
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	progress.stage(StageBuilding, 0)
+	c := b.Build()
+	c.redirects = redirects
+	return c, nil
+}
+
+// loadCSRFromDatabaseAndCache builds a CSR from the database and saves it to cache.
+func (l *Loader) loadCSRFromDatabaseAndCache() (*CSR, error) {
+	c, err := l.loadCSRFromDatabase()
+	if err != nil {
+		return nil, err
+	}
+
+	l.saveCache(c.Save)
+	return c, nil
 }
 
 // Rebuild forces a complete rebuild from the database and updates the cache.
@@ -148,3 +443,37 @@ func (l *Loader) InvalidateCache() error {
 	}
 	return DeleteCache(l.config.CachePath)
 }
+
+// progressInterval is the number of rows read between progress reports.
+const progressInterval = 1 << 16
+
+// progressReporter tracks the stage of one load and passes it on to
+// LoaderConfig.Progress.
+type progressReporter struct {
+	fn func(Progress)
+	p  Progress
+}
+
+func (l *Loader) newProgress() *progressReporter {
+	return &progressReporter{fn: l.config.Progress}
+}
+
+// stage starts a new stage.
+func (r *progressReporter) stage(name string, estimatedRows int64) {
+	r.p = Progress{Stage: name, EstimatedRows: estimatedRows}
+	r.report()
+}
+
+// row counts one row read.
+func (r *progressReporter) row() {
+	r.p.Rows++
+	if r.p.Rows%progressInterval == 0 {
+		r.report()
+	}
+}
+
+func (r *progressReporter) report() {
+	if r.fn != nil {
+		r.fn(r.p)
+	}
+}
@@ -2,6 +2,7 @@ package graph
 
 import (
 	"os"
+	"slices"
 	"testing"
 
 	"github.com/Thinh-nguyen-03/wikigraph/internal/cache"
@@ -139,3 +140,126 @@ func TestLoader_EmptyCache(t *testing.T) {
 		t.Errorf("expected 0 edges, got %d", g.EdgeCount())
 	}
 }
+
+func TestLoader_CollapsesRedirects(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	c := cache.New(db)
+
+	// "USA" redirects to "United States" through "U.S.".
+	pageA, _ := c.CreatePage("A")
+	pageUS, _ := c.CreatePage("United States")
+	c.CreatePage("USA")
+	c.CreatePage("U.S.")
+	c.UpdatePageStatus("A", cache.StatusSuccess, "", "")
+	c.UpdatePageStatus("United States", cache.StatusSuccess, "", "")
+	c.UpdatePageStatus("USA", cache.StatusRedirect, "", "U.S.")
+	c.UpdatePageStatus("U.S.", cache.StatusRedirect, "", "United States")
+	c.AddLinks(pageA.ID, []cache.Link{{TargetTitle: "USA"}, {TargetTitle: "United States"}})
+	c.AddLinks(pageUS.ID, []cache.Link{{TargetTitle: "A"}, {TargetTitle: "U.S."}})
+
+	for _, backend := range []Backend{BackendPointer, BackendCSR} {
+		t.Run(string(backend), func(t *testing.T) {
+			loader := NewLoaderWithConfig(c, LoaderConfig{Backend: backend})
+			g, err := loader.LoadView()
+			if err != nil {
+				t.Fatalf("LoadView failed: %v", err)
+			}
+
+			// The two links from A merge, and the link from United States
+			// to itself through a redirect is dropped.
+			if g.NodeCount() != 2 || g.EdgeCount() != 2 {
+				t.Errorf("got %d nodes and %d edges, want 2 and 2", g.NodeCount(), g.EdgeCount())
+			}
+			if g.HasNode("USA") || g.HasNode("U.S.") {
+				t.Error("redirect titles should not be nodes")
+			}
+			if got := g.Canonical("USA"); got != "United States" {
+				t.Errorf("Canonical(USA) = %q, want United States", got)
+			}
+			if got := g.Canonical("A"); got != "A" {
+				t.Errorf("Canonical(A) = %q, want A", got)
+			}
+
+			result := g.FindPath("A", g.Canonical("USA"))
+			if !result.Found || result.Hops != 1 {
+				t.Errorf("FindPath(A, USA) = %+v, want one hop", result)
+			}
+		})
+	}
+}
+
+func TestLoader_RedirectsFromCache(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	c := cache.New(db)
+	pageA, _ := c.CreatePage("A")
+	c.CreatePage("USA")
+	c.UpdatePageStatus("A", cache.StatusSuccess, "", "")
+	c.UpdatePageStatus("USA", cache.StatusRedirect, "", "United States")
+	c.AddLinks(pageA.ID, []cache.Link{{TargetTitle: "USA"}})
+
+	cachePath := t.TempDir() + "/graph.cache"
+	loader := NewLoaderWithConfig(c, LoaderConfig{CachePath: cachePath})
+	if _, err := loader.Load(); err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	g, err := loader.Load()
+	if err != nil {
+		t.Fatalf("Load from cache failed: %v", err)
+	}
+	if node := g.GetNode("USA"); node == nil || node.Title != "United States" {
+		t.Errorf("GetNode(USA) = %+v, want United States", node)
+	}
+}
+
+func TestLoader_ReportsProgress(t *testing.T) {
+	db, cleanup := setupTestDB(t)
+	defer cleanup()
+
+	c := cache.New(db)
+	pageA, _ := c.CreatePage("A")
+	c.CreatePage("Lonely")
+	c.UpdatePageStatus("A", cache.StatusSuccess, "", "")
+	c.UpdatePageStatus("Lonely", cache.StatusSuccess, "", "")
+	c.AddLinks(pageA.ID, []cache.Link{{TargetTitle: "B"}, {TargetTitle: "C"}})
+
+	for _, backend := range []Backend{BackendPointer, BackendCSR} {
+		t.Run(string(backend), func(t *testing.T) {
+			var reports []Progress
+			loader := NewLoaderWithConfig(c, LoaderConfig{
+				CachePath: t.TempDir() + "/graph.cache",
+				Backend:   backend,
+				Progress:  func(p Progress) { reports = append(reports, p) },
+			})
+			g, err := loader.LoadView()
+			if err != nil {
+				t.Fatalf("LoadView failed: %v", err)
+			}
+			if g.NodeCount() != 4 || g.EdgeCount() != 2 {
+				t.Errorf("got %d nodes and %d edges, want 4 and 2", g.NodeCount(), g.EdgeCount())
+			}
+
+			// Every stage starts with a report of no rows; the stages that
+			// read rows end with one of all of them.
+			want := []Progress{
+				{Stage: StageReadingCache},
+				{Stage: StageRedirects},
+				{Stage: StageLinks, EstimatedRows: 2},
+				{Stage: StageLinks, Rows: 2, EstimatedRows: 2},
+				{Stage: StagePages},
+				{Stage: StagePages, Rows: 1},
+			}
+			if backend == BackendCSR {
+				want = append(want, Progress{Stage: StageBuilding})
+			}
+			want = append(want, Progress{Stage: StageSavingCache})
+			if !slices.Equal(reports, want) {
+				t.Errorf("progress = %+v, want %+v", reports, want)
+			}
+		})
+	}
+}
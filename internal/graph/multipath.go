@@ -0,0 +1,402 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"slices"
+)
+
+// MultiPathResult holds several paths between the same pair of pages,
+// shortest first.
+type MultiPathResult struct {
+	Found    bool
+	Paths    [][]string
+	Hops     int // length of the shortest path
+	Explored int
+
+	// Truncated reports that more paths exist than were returned, or that
+	// the search stopped early and the list may be incomplete.
+	Truncated bool
+	// BudgetExhausted reports that the search hit its explored-node cap.
+	BudgetExhausted bool
+
+	// DAG is the union of every shortest path, set by ShortestPathDAG in
+	// place of Paths.
+	DAG *Subgraph
+}
+
+// pathDAG is the union of all shortest paths between two nodes.
+type pathDAG struct {
+	from, to uint32
+	hops     int
+	dist     map[uint32]int      // distance from the source, for every node on the DAG
+	succ     map[uint32][]uint32 // DAG edges, targets in ascending ID order
+}
+
+// shortestPathDAG runs a BFS from fromID that stops after the level
+// containing toID, then walks back from toID along in-links to keep only
+// the edges that lie on some shortest path. Returns nil if toID is not
+// reachable within maxDepth hops or the budget ran out first. If the budget
+// ran out while finishing the last level, the DAG may lack some paths.
+func shortestPathDAG(a Adjacency, fromID, toID uint32, maxDepth int, b *budget) *pathDAG {
+	dist := map[uint32]int{fromID: 0}
+	frontier := []uint32{fromID}
+	depth := 0
+	found := fromID == toID
+
+	for len(frontier) > 0 && !found && !b.stopped() {
+		if maxDepth >= 0 && depth >= maxDepth {
+			break
+		}
+
+		var next []uint32
+		for _, u := range frontier {
+			if !b.spend() {
+				break
+			}
+			for _, v := range a.Out(u) {
+				if _, seen := dist[v]; seen {
+					continue
+				}
+				dist[v] = depth + 1
+				if v == toID {
+					found = true
+				}
+				next = append(next, v)
+			}
+		}
+		frontier = next
+		depth++
+	}
+
+	if !found {
+		return nil
+	}
+
+	hops := dist[toID]
+	dag := &pathDAG{
+		from: fromID,
+		to:   toID,
+		hops: hops,
+		dist: map[uint32]int{toID: hops},
+		succ: make(map[uint32][]uint32),
+	}
+
+	level := []uint32{toID}
+	for d := hops; d > 0; d-- {
+		var prev []uint32
+		for _, v := range level {
+			for _, u := range a.In(v) {
+				if du, ok := dist[u]; !ok || du != d-1 {
+					continue
+				}
+				dag.succ[u] = append(dag.succ[u], v)
+				if _, onPath := dag.dist[u]; !onPath {
+					dag.dist[u] = d - 1
+					prev = append(prev, u)
+				}
+			}
+		}
+		level = prev
+	}
+	for _, targets := range dag.succ {
+		slices.Sort(targets)
+	}
+
+	return dag
+}
+
+// paths enumerates the DAG's source-to-target paths in ascending ID order,
+// stopping after maxPaths if maxPaths > 0.
+func (d *pathDAG) paths(maxPaths int) ([][]uint32, bool) {
+	var result [][]uint32
+	truncated := false
+	stack := []uint32{d.from}
+
+	var walk func(u uint32) bool
+	walk = func(u uint32) bool {
+		if u == d.to {
+			if maxPaths > 0 && len(result) == maxPaths {
+				truncated = true
+				return false
+			}
+			result = append(result, slices.Clone(stack))
+			return true
+		}
+		for _, v := range d.succ[u] {
+			stack = append(stack, v)
+			ok := walk(v)
+			stack = stack[:len(stack)-1]
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	walk(d.from)
+
+	return result, truncated
+}
+
+// ShortestPathDAG returns the subgraph made of every shortest path from one
+// page to another, which stays small where listing the paths would not.
+// Node hops are distances from the source. DAG is nil unless a path was
+// found within maxDepth hops (negative for no limit) before ctx was done or
+// maxExplored nodes (zero for no cap) were expanded.
+func ShortestPathDAG(ctx context.Context, a Adjacency, from, to string, maxDepth, maxExplored int) MultiPathResult {
+	fromID, okFrom := a.Lookup(from)
+	toID, okTo := a.Lookup(to)
+	if !okFrom || !okTo {
+		return MultiPathResult{}
+	}
+
+	b := newBudget(ctx, maxExplored)
+	dag := shortestPathDAG(a, fromID, toID, maxDepth, b)
+	if dag == nil {
+		return MultiPathResult{
+			Explored:        b.explored,
+			Truncated:       b.stopped(),
+			BudgetExhausted: b.exhausted,
+		}
+	}
+
+	ids := make([]uint32, 0, len(dag.dist))
+	for id := range dag.dist {
+		ids = append(ids, id)
+	}
+	slices.SortFunc(ids, func(x, y uint32) int {
+		return cmp.Or(cmp.Compare(dag.dist[x], dag.dist[y]), cmp.Compare(x, y))
+	})
+
+	result := &Subgraph{
+		Nodes:           make([]SubgraphNode, len(ids)),
+		Edges:           make([]SubgraphEdge, 0, len(ids)),
+		Truncated:       b.stopped(),
+		BudgetExhausted: b.exhausted,
+	}
+	for i, id := range ids {
+		title := a.Title(id)
+		result.Nodes[i] = SubgraphNode{
+			Title:     title,
+			Hops:      dag.dist[id],
+			InDegree:  len(a.In(id)),
+			OutDegree: len(a.Out(id)),
+		}
+		for _, target := range dag.succ[id] {
+			result.Edges = append(result.Edges, SubgraphEdge{Source: title, Target: a.Title(target)})
+		}
+	}
+
+	return MultiPathResult{
+		Found:           true,
+		Hops:            dag.hops,
+		Explored:        max(b.explored, 1),
+		Truncated:       b.stopped(),
+		BudgetExhausted: b.exhausted,
+		DAG:             result,
+	}
+}
+
+// AllShortestPaths returns every shortest path from one page to another,
+// up to maxPaths of them (0 for no cap). The number of shortest paths can
+// grow exponentially with distance, so callers serving requests should cap it.
+// The search stops when ctx is done or after expanding maxExplored nodes
+// (zero for no cap).
+func AllShortestPaths(ctx context.Context, a Adjacency, from, to string, maxDepth, maxPaths, maxExplored int) MultiPathResult {
+	fromID, okFrom := a.Lookup(from)
+	toID, okTo := a.Lookup(to)
+	if !okFrom || !okTo {
+		return MultiPathResult{}
+	}
+
+	b := newBudget(ctx, maxExplored)
+	dag := shortestPathDAG(a, fromID, toID, maxDepth, b)
+	if dag == nil {
+		return MultiPathResult{
+			Explored:        b.explored,
+			Truncated:       b.stopped(),
+			BudgetExhausted: b.exhausted,
+		}
+	}
+
+	ids, truncated := dag.paths(maxPaths)
+	return MultiPathResult{
+		Found:           true,
+		Paths:           idPathsToTitles(a, ids),
+		Hops:            dag.hops,
+		Explored:        max(b.explored, 1),
+		Truncated:       truncated || b.stopped(),
+		BudgetExhausted: b.exhausted,
+	}
+}
+
+// KShortestPaths returns up to k loopless paths from one page to another in
+// order of length, using Yen's algorithm with BFS as the inner search.
+// Paths of equal length are ordered by node ID, which follows title order
+// on a CSR. No path is longer than maxDepth hops (negative for no limit).
+// All inner searches share one budget: they stop when ctx is done or after
+// expanding maxExplored nodes in total (zero for no cap).
+func KShortestPaths(ctx context.Context, a Adjacency, from, to string, k, maxDepth, maxExplored int) MultiPathResult {
+	fromID, okFrom := a.Lookup(from)
+	toID, okTo := a.Lookup(to)
+	if !okFrom || !okTo || k < 1 {
+		return MultiPathResult{}
+	}
+
+	b := newBudget(ctx, maxExplored)
+	first := shortestPathIDs(a, fromID, toID, maxDepth, nil, nil, b)
+	if first == nil {
+		return MultiPathResult{
+			Explored:        b.explored,
+			Truncated:       b.stopped(),
+			BudgetExhausted: b.exhausted,
+		}
+	}
+
+	accepted := [][]uint32{first}
+	var candidates [][]uint32
+
+	for {
+		last := accepted[len(accepted)-1]
+
+		for i := 0; i < len(last)-1; i++ {
+			spurDepth := -1
+			if maxDepth >= 0 {
+				spurDepth = maxDepth - i
+			}
+			if spurDepth == 0 {
+				continue
+			}
+
+			// Block the next edge of every accepted path sharing this root,
+			// and the root itself, so the spur search finds a new path.
+			root := last[:i+1]
+			blockedEdges := make(map[[2]uint32]bool)
+			for _, p := range accepted {
+				if len(p) > i+1 && slices.Equal(p[:i+1], root) {
+					blockedEdges[[2]uint32{p[i], p[i+1]}] = true
+				}
+			}
+			blockedNodes := make(map[uint32]bool, i)
+			for _, n := range root[:i] {
+				blockedNodes[n] = true
+			}
+
+			spur := shortestPathIDs(a, last[i], toID, spurDepth,
+				func(n uint32) bool { return blockedNodes[n] },
+				func(u, v uint32) bool { return blockedEdges[[2]uint32{u, v}] },
+				b)
+			if spur == nil {
+				continue
+			}
+
+			candidate := append(slices.Clone(root[:i]), spur...)
+			if !containsIDPath(accepted, candidate) && !containsIDPath(candidates, candidate) {
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		// Spurs are generated once more after the k-th path so that
+		// Truncated is exact: by Yen's invariant, any further path is
+		// among the candidates. After an incomplete round that invariant
+		// no longer holds, so nothing more is accepted.
+		if len(accepted) >= k || len(candidates) == 0 || b.stopped() {
+			break
+		}
+
+		best := 0
+		for i := 1; i < len(candidates); i++ {
+			if compareIDPaths(candidates[i], candidates[best]) < 0 {
+				best = i
+			}
+		}
+		accepted = append(accepted, candidates[best])
+		candidates = slices.Delete(candidates, best, best+1)
+	}
+
+	return MultiPathResult{
+		Found:           true,
+		Paths:           idPathsToTitles(a, accepted),
+		Hops:            len(first) - 1,
+		Explored:        max(b.explored, 1),
+		Truncated:       len(candidates) > 0 || b.stopped(),
+		BudgetExhausted: b.exhausted,
+	}
+}
+
+// shortestPathIDs is a BFS from one node ID to another that never enters a
+// node for which skipNode returns true or follows an edge for which skipEdge
+// returns true. Either filter may be nil. The source itself is never skipped.
+// Returns nil if no path exists within maxDepth hops or the budget stops the search.
+func shortestPathIDs(a Adjacency, fromID, toID uint32, maxDepth int, skipNode func(uint32) bool, skipEdge func(u, v uint32) bool, b *budget) []uint32 {
+	if fromID == toID {
+		return []uint32{fromID}
+	}
+
+	parent := map[uint32]uint32{fromID: fromID}
+	frontier := []uint32{fromID}
+	depth := 0
+
+	for len(frontier) > 0 {
+		if maxDepth >= 0 && depth >= maxDepth {
+			break
+		}
+
+		var next []uint32
+		for _, u := range frontier {
+			if !b.spend() {
+				return nil
+			}
+			for _, v := range a.Out(u) {
+				if _, seen := parent[v]; seen {
+					continue
+				}
+				if (skipNode != nil && skipNode(v)) || (skipEdge != nil && skipEdge(u, v)) {
+					continue
+				}
+				parent[v] = u
+				if v == toID {
+					return indexedPathIDs(parent, toID)
+				}
+				next = append(next, v)
+			}
+		}
+		frontier = next
+		depth++
+	}
+
+	return nil
+}
+
+// indexedPathIDs walks parent links back from to; the root is its own parent.
+func indexedPathIDs(parent map[uint32]uint32, to uint32) []uint32 {
+	var ids []uint32
+	for n := to; ; n = parent[n] {
+		ids = append(ids, n)
+		if parent[n] == n {
+			break
+		}
+	}
+	slices.Reverse(ids)
+	return ids
+}
+
+func compareIDPaths(x, y []uint32) int {
+	return cmp.Or(cmp.Compare(len(x), len(y)), slices.Compare(x, y))
+}
+
+func containsIDPath(paths [][]uint32, p []uint32) bool {
+	return slices.ContainsFunc(paths, func(q []uint32) bool { return slices.Equal(p, q) })
+}
+
+func idPathsToTitles(a Adjacency, paths [][]uint32) [][]string {
+	result := make([][]string, len(paths))
+	for i, ids := range paths {
+		titles := make([]string, len(ids))
+		for j, id := range ids {
+			titles[j] = a.Title(id)
+		}
+		result[i] = titles
+	}
+	return result
+}
@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+// twoRouteGraph has two shortest A→E paths of 3 hops and one detour of 4.
+func twoRouteGraph() *CSR {
+	return buildCSR([][2]string{
+		{"A", "B"}, {"A", "C"}, {"B", "D"}, {"C", "D"}, {"D", "E"},
+		{"A", "X"}, {"X", "Y"}, {"Y", "Z"}, {"Z", "E"},
+	})
+}
+
+func TestAllShortestPaths(t *testing.T) {
+	c := twoRouteGraph()
+
+	r := AllShortestPaths(context.Background(), c, "A", "E", -1, 0, 0)
+	if !r.Found || r.Hops != 3 || r.Truncated {
+		t.Fatalf("AllShortestPaths = %+v", r)
+	}
+	want := [][]string{{"A", "B", "D", "E"}, {"A", "C", "D", "E"}}
+	if len(r.Paths) != len(want) {
+		t.Fatalf("got %d paths, want %d: %v", len(r.Paths), len(want), r.Paths)
+	}
+	for i := range want {
+		if !equalSlices(r.Paths[i], want[i]) {
+			t.Errorf("path %d = %v, want %v", i, r.Paths[i], want[i])
+		}
+	}
+
+	capped := AllShortestPaths(context.Background(), c, "A", "E", -1, 1, 0)
+	if len(capped.Paths) != 1 || !capped.Truncated {
+		t.Errorf("capped result = %+v, want 1 truncated path", capped)
+	}
+
+	if r := AllShortestPaths(context.Background(), c, "A", "E", 2, 0, 0); r.Found {
+		t.Errorf("expected no path within 2 hops, got %+v", r)
+	}
+	if r := AllShortestPaths(context.Background(), c, "A", "A", -1, 0, 0); !r.Found || r.Hops != 0 || len(r.Paths) != 1 {
+		t.Errorf("AllShortestPaths(A, A) = %+v", r)
+	}
+	if r := AllShortestPaths(context.Background(), c, "E", "A", -1, 0, 0); r.Found {
+		t.Errorf("expected no path from E to A, got %+v", r)
+	}
+}
+
+func TestShortestPathDAG(t *testing.T) {
+	r := ShortestPathDAG(context.Background(), twoRouteGraph(), "A", "E", -1, 0)
+	dag := r.DAG
+	if !r.Found || r.Hops != 3 || dag == nil {
+		t.Fatalf("ShortestPathDAG(A, E) = %+v, want a DAG of 3 hops", r)
+	}
+
+	hops := make(map[string]int)
+	for _, n := range dag.Nodes {
+		hops[n.Title] = n.Hops
+	}
+	wantHops := map[string]int{"A": 0, "B": 1, "C": 1, "D": 2, "E": 3}
+	if len(hops) != len(wantHops) {
+		t.Errorf("DAG nodes = %v, want %v", hops, wantHops)
+	}
+	for title, h := range wantHops {
+		if hops[title] != h {
+			t.Errorf("hops[%s] = %d, want %d", title, hops[title], h)
+		}
+	}
+	if len(dag.Edges) != 5 {
+		t.Errorf("expected 5 DAG edges, got %v", dag.Edges)
+	}
+
+	if r := ShortestPathDAG(context.Background(), twoRouteGraph(), "E", "A", -1, 0); r.Found || r.DAG != nil {
+		t.Errorf("expected no DAG when no path exists, got %+v", r)
+	}
+	if r := ShortestPathDAG(context.Background(), twoRouteGraph(), "A", "E", -1, 2); r.Found || !r.BudgetExhausted || r.Explored != 2 {
+		t.Errorf("budget of 2: %+v, want exhausted without a DAG", r)
+	}
+}
+
+func TestKShortestPaths(t *testing.T) {
+	c := twoRouteGraph()
+
+	r := KShortestPaths(context.Background(), c, "A", "E", 3, -1, 0)
+	want := [][]string{
+		{"A", "B", "D", "E"},
+		{"A", "C", "D", "E"},
+		{"A", "X", "Y", "Z", "E"},
+	}
+	if !r.Found || r.Hops != 3 || len(r.Paths) != len(want) {
+		t.Fatalf("KShortestPaths = %+v", r)
+	}
+	for i := range want {
+		if !equalSlices(r.Paths[i], want[i]) {
+			t.Errorf("path %d = %v, want %v", i, r.Paths[i], want[i])
+		}
+	}
+
+	// Asking for more paths than exist returns all of them.
+	if r := KShortestPaths(context.Background(), c, "A", "E", 10, -1, 0); len(r.Paths) != 3 || r.Truncated {
+		t.Errorf("KShortestPaths(k=10) = %+v", r)
+	}
+
+	// The depth limit applies to every alternative.
+	if r := KShortestPaths(context.Background(), c, "A", "E", 3, 3, 0); len(r.Paths) != 2 {
+		t.Errorf("KShortestPaths(maxDepth=3) returned %v", r.Paths)
+	}
+
+	if r := KShortestPaths(context.Background(), c, "A", "E", 2, -1, 0); len(r.Paths) != 2 || !r.Truncated {
+		t.Errorf("KShortestPaths(k=2) = %+v", r)
+	}
+}
+
+func TestKShortestPathsLoopless(t *testing.T) {
+	g := New()
+	for _, e := range [][2]string{{"A", "B"}, {"B", "A"}, {"B", "C"}, {"A", "C"}} {
+		g.AddEdge(e[0], e[1])
+	}
+
+	r := KShortestPaths(context.Background(), g.Adjacency(), "A", "C", 5, -1, 0)
+	if len(r.Paths) != 2 {
+		t.Fatalf("expected 2 simple paths, got %v", r.Paths)
+	}
+	for _, p := range r.Paths {
+		seen := make(map[string]bool)
+		for _, title := range p {
+			if seen[title] {
+				t.Errorf("path %v repeats %s", p, title)
+			}
+			seen[title] = true
+		}
+	}
+}
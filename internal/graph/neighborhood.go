@@ -0,0 +1,179 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Direction selects which links a neighborhood traversal follows.
+type Direction string
+
+const (
+	// DirectionOut follows links from a page to the pages it links to.
+	DirectionOut Direction = "out"
+	// DirectionIn follows links back to the pages linking to a page.
+	DirectionIn Direction = "in"
+	// DirectionBoth follows links either way.
+	DirectionBoth Direction = "both"
+)
+
+// ParseDirection validates a direction name. The empty string selects
+// DirectionOut.
+func ParseDirection(name string) (Direction, error) {
+	switch Direction(name) {
+	case "", DirectionOut:
+		return DirectionOut, nil
+	case DirectionIn, DirectionBoth:
+		return Direction(name), nil
+	default:
+		return "", fmt.Errorf("unknown direction %q (want %q, %q or %q)",
+			name, DirectionOut, DirectionIn, DirectionBoth)
+	}
+}
+
+// NeighborhoodOptions configures a neighborhood traversal.
+type NeighborhoodOptions struct {
+	// MaxDepth is how many hops the traversal goes out from the center.
+	MaxDepth int
+	// MaxNodes caps the pages returned, center included.
+	MaxNodes int
+	// MaxExplored stops the traversal after expanding this many pages
+	// (zero for no cap).
+	MaxExplored int
+
+	// Direction selects the links followed. Default DirectionOut.
+	Direction Direction
+
+	// FanOut[d] caps how many new pages are taken from each page d hops
+	// from the center, preferring the pages with the most in-links. The
+	// last entry applies to every deeper hop; zero means no cap.
+	FanOut []int
+
+	// MinInDegree leaves out pages, other than the center, with fewer
+	// in-links than this.
+	MinInDegree int
+}
+
+// fanOut returns the cap on new pages taken from a page at depth.
+func (o NeighborhoodOptions) fanOut(depth int) int {
+	if len(o.FanOut) == 0 {
+		return 0
+	}
+	return o.FanOut[min(depth, len(o.FanOut)-1)]
+}
+
+// neighborhood runs a breadth-first traversal from center over either
+// backend. Edges are only reported between pages that made it into the
+// result, so pages cut off by MaxNodes, FanOut or MinInDegree leave no
+// dangling edges behind.
+func neighborhood[N comparable](ctx context.Context, center N, opts NeighborhoodOptions, out, in func(N) []N, title func(N) string) *Subgraph {
+	if opts.Direction == "" {
+		opts.Direction = DirectionOut
+	}
+	followOut := opts.Direction != DirectionIn
+	followIn := opts.Direction != DirectionOut
+
+	result := &Subgraph{
+		Nodes: make([]SubgraphNode, 0, max(opts.MaxNodes, 1)),
+		Edges: make([]SubgraphEdge, 0),
+	}
+	visited := make(map[N]bool)
+	add := func(n N, hops int) {
+		visited[n] = true
+		result.Nodes = append(result.Nodes, SubgraphNode{
+			Title:     title(n),
+			Hops:      hops,
+			InDegree:  len(in(n)),
+			OutDegree: len(out(n)),
+		})
+	}
+	add(center, 0)
+
+	// Links seen while expanding, kept once both ends are known to be in
+	// the result. Following both directions sees a link from each end.
+	var links [][2]N
+	var seenLinks map[[2]N]bool
+	if followOut && followIn {
+		seenLinks = make(map[[2]N]bool)
+	}
+	addLink := func(source, target N) {
+		if seenLinks != nil {
+			if seenLinks[[2]N{source, target}] {
+				return
+			}
+			seenLinks[[2]N{source, target}] = true
+		}
+		links = append(links, [2]N{source, target})
+	}
+
+	type queueItem struct {
+		node  N
+		depth int
+	}
+	queue := []queueItem{{center, 0}}
+	b := newBudget(ctx, opts.MaxExplored)
+	var fresh []N
+	considered := make(map[N]bool)
+
+	for len(queue) > 0 && len(result.Nodes) < opts.MaxNodes {
+		item := queue[0]
+		queue = queue[1:]
+
+		if item.depth >= opts.MaxDepth {
+			continue
+		}
+		if !b.spend() {
+			break
+		}
+
+		fresh = fresh[:0]
+		clear(considered)
+		consider := func(neighbor N) {
+			if visited[neighbor] || considered[neighbor] {
+				return
+			}
+			considered[neighbor] = true
+			if len(in(neighbor)) >= opts.MinInDegree {
+				fresh = append(fresh, neighbor)
+			}
+		}
+		if followOut {
+			for _, neighbor := range out(item.node) {
+				addLink(item.node, neighbor)
+				consider(neighbor)
+			}
+		}
+		if followIn {
+			for _, neighbor := range in(item.node) {
+				addLink(neighbor, item.node)
+				consider(neighbor)
+			}
+		}
+
+		if limit := opts.fanOut(item.depth); limit > 0 && len(fresh) > limit {
+			slices.SortStableFunc(fresh, func(x, y N) int {
+				return cmp.Compare(len(in(y)), len(in(x)))
+			})
+			fresh = fresh[:limit]
+		}
+		for _, neighbor := range fresh {
+			if len(result.Nodes) >= opts.MaxNodes {
+				break
+			}
+			add(neighbor, item.depth+1)
+			queue = append(queue, queueItem{neighbor, item.depth + 1})
+		}
+	}
+
+	for _, l := range links {
+		if visited[l[0]] && visited[l[1]] {
+			result.Edges = append(result.Edges, SubgraphEdge{Source: title(l[0]), Target: title(l[1])})
+		}
+	}
+
+	result.Truncated = b.stopped()
+	result.BudgetExhausted = b.exhausted
+	return result
+}
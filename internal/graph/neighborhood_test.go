@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+// neighborhoodGraph has A at the center: A links to B and C, D and E link
+// to A, B links on to F, and G links to D.
+func neighborhoodGraph() *Graph {
+	g := New()
+	for _, e := range [][2]string{
+		{"A", "B"}, {"A", "C"}, {"D", "A"}, {"E", "A"}, {"B", "F"}, {"G", "D"},
+		{"C", "B"}, {"E", "B"},
+	} {
+		g.AddEdge(e[0], e[1])
+	}
+	return g
+}
+
+func subgraphTitles(s *Subgraph) map[string]SubgraphNode {
+	nodes := make(map[string]SubgraphNode, len(s.Nodes))
+	for _, n := range s.Nodes {
+		nodes[n.Title] = n
+	}
+	return nodes
+}
+
+func TestNeighborhoodDirections(t *testing.T) {
+	g := neighborhoodGraph()
+	ctx := context.Background()
+
+	tests := []struct {
+		direction Direction
+		want      []string
+	}{
+		{DirectionOut, []string{"A", "B", "C", "F"}},
+		{DirectionIn, []string{"A", "D", "E", "G"}},
+		{DirectionBoth, []string{"A", "B", "C", "D", "E", "F", "G"}},
+	}
+
+	for _, view := range []View{g, g.Compact()} {
+		for _, tt := range tests {
+			s := view.GetNeighborhoodWithOptions(ctx, "A", NeighborhoodOptions{
+				MaxDepth:  2,
+				MaxNodes:  100,
+				Direction: tt.direction,
+			})
+			nodes := subgraphTitles(s)
+			if len(nodes) != len(tt.want) {
+				t.Errorf("%T %s: nodes = %v, want %v", view, tt.direction, s.Nodes, tt.want)
+				continue
+			}
+			for _, title := range tt.want {
+				if _, ok := nodes[title]; !ok {
+					t.Errorf("%T %s: missing %s", view, tt.direction, title)
+				}
+			}
+			for _, e := range s.Edges {
+				if _, ok := nodes[e.Source]; !ok {
+					t.Errorf("%T %s: edge %v leaves the subgraph", view, tt.direction, e)
+				}
+				if _, ok := nodes[e.Target]; !ok {
+					t.Errorf("%T %s: edge %v leaves the subgraph", view, tt.direction, e)
+				}
+			}
+		}
+	}
+
+	s := g.GetNeighborhoodWithOptions(ctx, "A", NeighborhoodOptions{MaxDepth: 2, MaxNodes: 100, Direction: DirectionBoth})
+	if n := subgraphTitles(s)["A"]; n.InDegree != 2 || n.OutDegree != 2 {
+		t.Errorf("A degrees = %d in, %d out, want 2, 2", n.InDegree, n.OutDegree)
+	}
+	// Every link is seen from both ends but listed once.
+	if len(s.Edges) != g.EdgeCount() {
+		t.Errorf("edges = %v, want %d", s.Edges, g.EdgeCount())
+	}
+}
+
+func TestNeighborhoodDropsEdgesToCutOffNodes(t *testing.T) {
+	g := neighborhoodGraph()
+
+	for _, view := range []View{g, g.Compact()} {
+		s := view.GetNeighborhood("A", 1, 2)
+		if len(s.Nodes) != 2 {
+			t.Fatalf("%T: nodes = %v, want 2", view, s.Nodes)
+		}
+		if len(s.Edges) != 1 || s.Edges[0].Source != "A" || s.Edges[0].Target != s.Nodes[1].Title {
+			t.Errorf("%T: edges = %v, want only the edge to %s", view, s.Edges, s.Nodes[1].Title)
+		}
+	}
+}
+
+func TestNeighborhoodFanOutAndMinInDegree(t *testing.T) {
+	c := neighborhoodGraph().Compact()
+	ctx := context.Background()
+
+	// B has three in-links, C one: a fan-out of one keeps B.
+	s := c.GetNeighborhoodWithOptions(ctx, "A", NeighborhoodOptions{MaxDepth: 1, MaxNodes: 100, FanOut: []int{1}})
+	if nodes := subgraphTitles(s); len(nodes) != 2 || nodes["B"].Hops != 1 {
+		t.Errorf("fan-out 1: nodes = %v, want A and B", s.Nodes)
+	}
+
+	// The last fan-out entry applies to deeper hops, and zero is no cap.
+	s = c.GetNeighborhoodWithOptions(ctx, "A", NeighborhoodOptions{MaxDepth: 2, MaxNodes: 100, FanOut: []int{0, 0}})
+	if len(s.Nodes) != 4 {
+		t.Errorf("fan-out 0: nodes = %v, want 4", s.Nodes)
+	}
+
+	s = c.GetNeighborhoodWithOptions(ctx, "A", NeighborhoodOptions{MaxDepth: 2, MaxNodes: 100, Direction: DirectionBoth, MinInDegree: 1})
+	nodes := subgraphTitles(s)
+	if _, ok := nodes["E"]; ok {
+		t.Errorf("min in-degree 1: E has no in-links but was kept: %v", s.Nodes)
+	}
+	if _, ok := nodes["D"]; !ok {
+		t.Errorf("min in-degree 1: D was dropped: %v", s.Nodes)
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	if d, err := ParseDirection(""); err != nil || d != DirectionOut {
+		t.Errorf("empty: %q, %v", d, err)
+	}
+	if _, err := ParseDirection("sideways"); err == nil {
+		t.Error("expected an error for an unknown direction")
+	}
+}
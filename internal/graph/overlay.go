@@ -0,0 +1,304 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sync/atomic"
+)
+
+// Overlay is an immutable graph made of a base CSR and the rows changed
+// since it was built. Updating an Overlay returns a new one that shares the
+// base arrays and every untouched row with its predecessor, so an update
+// costs time in proportion to the links it changes and readers of the old
+// version are never disturbed.
+//
+// Pages added after the base get IDs from base.NodeCount() upwards; rows
+// stay sorted by ID as in the CSR.
+type Overlay struct {
+	base  *CSR
+	out   map[uint32][]uint32 // replaced out-link rows
+	in    map[uint32][]uint32 // replaced in-link rows
+	added []string            // titles of pages not in base, by ID - base.NodeCount()
+	ids   map[string]uint32   // IDs of the pages in added
+	edges int
+
+	// compact caches the CSR form built by Compact.
+	compact atomic.Pointer[CSR]
+}
+
+// WithOutLinks folds the changed rows into a fresh base once more than one
+// page in overlayCompactDivisor, and at least overlayCompactMin pages, have
+// replaced out-links.
+const (
+	overlayCompactDivisor = 16
+	overlayCompactMin     = 1024
+)
+
+// NewOverlay returns an Overlay with no changes over base.
+func NewOverlay(base *CSR) *Overlay {
+	return &Overlay{base: base, edges: base.EdgeCount()}
+}
+
+// Base returns the CSR the changes of o are layered over.
+func (o *Overlay) Base() *CSR {
+	return o.base
+}
+
+// OverlayOf returns v as an Overlay ready for updates: an Overlay is
+// returned as is, a CSR becomes the base of a new one and a Graph is
+// compacted first.
+func OverlayOf(v View) (*Overlay, error) {
+	switch v := v.(type) {
+	case *Overlay:
+		return v, nil
+	case *CSR:
+		return NewOverlay(v), nil
+	case *Graph:
+		return NewOverlay(v.Compact()), nil
+	default:
+		return nil, fmt.Errorf("cannot overlay graph of type %T", v)
+	}
+}
+
+// WithOutLinks returns a new Overlay in which the out-links of each page in
+// updates are replaced by the given targets. A nil target list removes all
+// of a page's out-links. Pages and targets not yet in the graph are added.
+// The receiver is not modified.
+func (o *Overlay) WithOutLinks(updates map[string][]string) *Overlay {
+	next := &Overlay{
+		base:  o.base,
+		out:   maps.Clone(o.out),
+		in:    maps.Clone(o.in),
+		added: slices.Clip(o.added),
+		ids:   maps.Clone(o.ids),
+		edges: o.edges,
+	}
+	if next.out == nil {
+		next.out = make(map[uint32][]uint32, len(updates))
+		next.in = make(map[uint32][]uint32)
+	}
+
+	// Sorting the titles makes the IDs of new pages deterministic.
+	for _, title := range slices.Sorted(maps.Keys(updates)) {
+		u := next.node(title)
+
+		row := make([]uint32, 0, len(updates[title]))
+		for _, target := range updates[title] {
+			row = append(row, next.node(target))
+		}
+		slices.Sort(row)
+		next.setOut(u, slices.Compact(row))
+	}
+
+	if len(next.out) > max(next.base.NodeCount()/overlayCompactDivisor, overlayCompactMin) {
+		return NewOverlay(next.Compact())
+	}
+	return next
+}
+
+// node returns the ID of a page, adding it if it is not in the graph.
+// Only used while building a new Overlay.
+func (o *Overlay) node(title string) uint32 {
+	if id, ok := o.Lookup(title); ok {
+		return id
+	}
+	if o.ids == nil {
+		o.ids = make(map[string]uint32)
+	}
+	id := uint32(o.NodeCount())
+	o.ids[title] = id
+	o.added = append(o.added, title)
+	return id
+}
+
+// setOut replaces the out-links of u by row, which must be sorted and free
+// of duplicates, and updates the in-links of the pages gained or lost.
+// Only used while building a new Overlay.
+func (o *Overlay) setOut(u uint32, row []uint32) {
+	old := o.Out(u)
+	for _, v := range old {
+		if _, kept := slices.BinarySearch(row, v); !kept {
+			in := slices.Clone(o.In(v))
+			i, _ := slices.BinarySearch(in, u)
+			o.in[v] = slices.Delete(in, i, i+1)
+		}
+	}
+	for _, v := range row {
+		if _, had := slices.BinarySearch(old, v); !had {
+			in := slices.Clone(o.In(v))
+			i, _ := slices.BinarySearch(in, u)
+			o.in[v] = slices.Insert(in, i, u)
+		}
+	}
+
+	o.out[u] = row
+	o.edges += len(row) - len(old)
+}
+
+// Changed returns the number of pages whose out-links differ from the base.
+func (o *Overlay) Changed() int {
+	return len(o.out)
+}
+
+// Compact returns the graph as a single CSR. The result is cached.
+func (o *Overlay) Compact() *CSR {
+	if c := o.compact.Load(); c != nil {
+		return c
+	}
+	if len(o.out) == 0 && len(o.added) == 0 {
+		return o.base
+	}
+
+	b := NewCSRBuilder(o.NodeCount(), o.edges)
+	for id := uint32(0); int(id) < o.NodeCount(); id++ {
+		title := o.Title(id)
+		b.AddNode(title)
+		for _, target := range o.Out(id) {
+			b.AddEdge(title, o.Title(target))
+		}
+	}
+	c := b.Build()
+	c.redirects = o.base.redirects
+
+	o.compact.Store(c)
+	return c
+}
+
+// Save persists the graph to disk in snapshot format.
+func (o *Overlay) Save(path string) error {
+	return saveSnapshot(path, o.Compact())
+}
+
+func (o *Overlay) NodeCount() int {
+	return o.base.NodeCount() + len(o.added)
+}
+
+func (o *Overlay) EdgeCount() int {
+	return o.edges
+}
+
+// Lookup returns the ID of the node with the given title.
+func (o *Overlay) Lookup(title string) (uint32, bool) {
+	if id, ok := o.base.Lookup(title); ok {
+		return id, true
+	}
+	id, ok := o.ids[title]
+	return id, ok
+}
+
+// Title returns the title of the node with the given ID.
+func (o *Overlay) Title(id uint32) string {
+	if n := uint32(o.base.NodeCount()); id >= n {
+		return o.added[id-n]
+	}
+	return o.base.Title(id)
+}
+
+// Out returns the IDs of the nodes id links to, in ascending order.
+func (o *Overlay) Out(id uint32) []uint32 {
+	if row, ok := o.out[id]; ok {
+		return row
+	}
+	if int(id) < o.base.NodeCount() {
+		return o.base.Out(id)
+	}
+	return nil
+}
+
+// In returns the IDs of the nodes linking to id, in ascending order.
+func (o *Overlay) In(id uint32) []uint32 {
+	if row, ok := o.in[id]; ok {
+		return row
+	}
+	if int(id) < o.base.NodeCount() {
+		return o.base.In(id)
+	}
+	return nil
+}
+
+// Canonical returns the page a title redirects to, or the title itself if
+// it is not a redirect. A title that is neither a page nor a redirect is
+// looked up again in normalized form.
+func (o *Overlay) Canonical(title string) string {
+	return o.base.redirects.resolve(title, o.HasNode)
+}
+
+func (o *Overlay) HasNode(title string) bool {
+	_, ok := o.Lookup(title)
+	return ok
+}
+
+func (o *Overlay) OutLinkTitles(title string) []string {
+	id, ok := o.Lookup(title)
+	if !ok {
+		return nil
+	}
+	return o.titles(o.Out(id))
+}
+
+func (o *Overlay) InLinkTitles(title string) []string {
+	id, ok := o.Lookup(title)
+	if !ok {
+		return nil
+	}
+	return o.titles(o.In(id))
+}
+
+func (o *Overlay) titles(ids []uint32) []string {
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[i] = o.Title(id)
+	}
+	return result
+}
+
+// Adjacency returns o itself; Overlay is already integer-indexed.
+func (o *Overlay) Adjacency() Adjacency {
+	return o
+}
+
+func (o *Overlay) FindPath(from, to string) PathResult {
+	return o.FindPathWithLimit(from, to, -1)
+}
+
+func (o *Overlay) FindPathWithLimit(from, to string, maxDepth int) PathResult {
+	return o.FindPathContext(context.Background(), from, to, maxDepth, 0)
+}
+
+func (o *Overlay) FindPathContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult {
+	return findPathIndexed(ctx, o, from, to, maxDepth, maxExplored)
+}
+
+func (o *Overlay) FindPathBidirectional(from, to string) PathResult {
+	return o.FindPathBidirectionalWithLimit(from, to, -1)
+}
+
+func (o *Overlay) FindPathBidirectionalWithLimit(from, to string, maxDepth int) PathResult {
+	return o.FindPathBidirectionalContext(context.Background(), from, to, maxDepth, 0)
+}
+
+func (o *Overlay) FindPathBidirectionalContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult {
+	return findPathBidirectionalIndexed(ctx, o, from, to, maxDepth, maxExplored)
+}
+
+func (o *Overlay) GetNeighborhood(title string, maxDepth, maxNodes int) *Subgraph {
+	return o.GetNeighborhoodContext(context.Background(), title, maxDepth, maxNodes, 0)
+}
+
+func (o *Overlay) GetNeighborhoodContext(ctx context.Context, title string, maxDepth, maxNodes, maxExplored int) *Subgraph {
+	return o.GetNeighborhoodWithOptions(ctx, title, NeighborhoodOptions{
+		MaxDepth:    maxDepth,
+		MaxNodes:    maxNodes,
+		MaxExplored: maxExplored,
+	})
+}
+
+func (o *Overlay) GetNeighborhoodWithOptions(ctx context.Context, title string, opts NeighborhoodOptions) *Subgraph {
+	center, ok := o.Lookup(title)
+	if !ok {
+		return nil
+	}
+	return neighborhood(ctx, center, opts, o.Out, o.In, o.Title)
+}
@@ -0,0 +1,294 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestOverlayWithOutLinks(t *testing.T) {
+	c := buildCSR([][2]string{{"A", "B"}, {"B", "C"}, {"C", "A"}})
+	base := NewOverlay(c)
+
+	updated := base.WithOutLinks(map[string][]string{
+		"A": {"C", "D", "C"},
+		"B": nil,
+		"E": {"A"},
+	})
+
+	tests := []struct {
+		title    string
+		out, in  []string
+		inBefore []string
+	}{
+		{"A", []string{"C", "D"}, []string{"C", "E"}, []string{"C"}},
+		{"B", []string{}, []string{}, []string{"A"}},
+		{"C", []string{"A"}, []string{"A"}, []string{"B"}},
+		{"D", []string{}, []string{"A"}, nil},
+		{"E", []string{"A"}, []string{}, nil},
+	}
+	for _, tt := range tests {
+		if got := updated.OutLinkTitles(tt.title); !equalSlices(got, tt.out) {
+			t.Errorf("OutLinkTitles(%s) = %v, want %v", tt.title, got, tt.out)
+		}
+		if got := updated.InLinkTitles(tt.title); !equalSlices(got, tt.in) {
+			t.Errorf("InLinkTitles(%s) = %v, want %v", tt.title, got, tt.in)
+		}
+		// The previous version is untouched.
+		if got := base.InLinkTitles(tt.title); !equalSlices(got, tt.inBefore) {
+			t.Errorf("old InLinkTitles(%s) = %v, want %v", tt.title, got, tt.inBefore)
+		}
+	}
+	if updated.NodeCount() != 5 || updated.EdgeCount() != 4 {
+		t.Errorf("updated has %d nodes, %d edges, want 5, 4", updated.NodeCount(), updated.EdgeCount())
+	}
+	if base.NodeCount() != 3 || base.EdgeCount() != 3 {
+		t.Errorf("base has %d nodes, %d edges, want 3, 3", base.NodeCount(), base.EdgeCount())
+	}
+	if updated.Changed() != 3 {
+		t.Errorf("Changed = %d, want 3", updated.Changed())
+	}
+
+	if r := updated.FindPath("E", "D"); !r.Found || r.Hops != 2 {
+		t.Errorf("FindPath(E, D) = %+v, want 2 hops", r)
+	}
+	if r := base.FindPath("A", "C"); !r.Found || r.Hops != 2 {
+		t.Errorf("old FindPath(A, C) = %+v, want 2 hops", r)
+	}
+}
+
+func TestOverlayMatchesRebuild(t *testing.T) {
+	rng := rand.New(rand.NewPCG(5, 5))
+	c := randomCSR(200, 600, 5)
+	o := NewOverlay(c)
+	want := c
+
+	for round := range 20 {
+		updates := make(map[string][]string)
+		for range 5 {
+			var targets []string
+			for range rng.IntN(6) {
+				targets = append(targets, nodeName(rng.IntN(220)))
+			}
+			updates[nodeName(rng.IntN(220))] = targets
+		}
+		o = o.WithOutLinks(updates)
+		want = want.WithOutLinks(updates)
+
+		if o.NodeCount() != want.NodeCount() || o.EdgeCount() != want.EdgeCount() {
+			t.Fatalf("round %d: %d nodes, %d edges, want %d, %d",
+				round, o.NodeCount(), o.EdgeCount(), want.NodeCount(), want.EdgeCount())
+		}
+		for id := range uint32(want.NodeCount()) {
+			title := want.Title(id)
+			got, ok := o.Lookup(title)
+			if !ok {
+				t.Fatalf("round %d: %s missing", round, title)
+			}
+			if !slices.Equal(sortedTitles(o, o.Out(got)), want.OutLinkTitles(title)) ||
+				!slices.Equal(sortedTitles(o, o.In(got)), want.InLinkTitles(title)) {
+				t.Fatalf("round %d: %s links differ", round, title)
+			}
+		}
+	}
+
+	compact := o.Compact()
+	if compact.NodeCount() != want.NodeCount() || compact.EdgeCount() != want.EdgeCount() {
+		t.Errorf("Compact has %d nodes, %d edges, want %d, %d",
+			compact.NodeCount(), compact.EdgeCount(), want.NodeCount(), want.EdgeCount())
+	}
+	if o.Compact() != compact {
+		t.Error("Compact should be cached")
+	}
+}
+
+func TestOverlaySave(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.cache")
+	o := NewOverlay(buildCSR([][2]string{{"A", "B"}})).WithOutLinks(map[string][]string{"B": {"C"}})
+
+	if err := o.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _, err := LoadCSRFromCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.NodeCount() != 3 || loaded.EdgeCount() != 2 {
+		t.Errorf("loaded %d nodes, %d edges, want 3, 2", loaded.NodeCount(), loaded.EdgeCount())
+	}
+	if r := loaded.FindPath("A", "C"); !r.Found || r.Hops != 2 {
+		t.Errorf("FindPath(A, C) = %+v, want 2 hops", r)
+	}
+}
+
+func TestOverlayPersist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.cache")
+
+	// A base built in memory is saved whole and replaced by its snapshot.
+	o, err := NewOverlay(buildCSR([][2]string{{"A", "C"}, {"C", "E"}})).
+		WithOutLinks(map[string][]string{"E": {"A"}}).Persist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o.Base().snapshot == nil || o.Changed() != 0 || o.EdgeCount() != 3 {
+		t.Fatalf("Persist returned %d changes and %d edges, want an overlay of 3 edges over the saved snapshot",
+			o.Changed(), o.EdgeCount())
+	}
+	header, err := ReadSnapshotHeader(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Over that snapshot, only the changes are written.
+	for _, updates := range []map[string][]string{
+		{"A": {"B", "C"}, "B": {"D"}},
+		{"D": {"A", "F"}, "C": nil},
+	} {
+		next := o.WithOutLinks(updates)
+		if o, err = next.Persist(path); err != nil {
+			t.Fatal(err)
+		}
+		if o != next {
+			t.Fatal("Persist replaced an overlay over the saved snapshot")
+		}
+	}
+	if h, err := ReadSnapshotHeader(path); err != nil || h.Checksum != header.Checksum {
+		t.Errorf("snapshot rewritten for a delta: %+v, %v", h, err)
+	}
+
+	base, _, err := LoadCSRFromCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadDelta(DeltaPath(path), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.NodeCount() != o.NodeCount() || loaded.EdgeCount() != o.EdgeCount() || loaded.Changed() != o.Changed() {
+		t.Errorf("loaded %d nodes, %d edges, %d changes, want %d, %d, %d",
+			loaded.NodeCount(), loaded.EdgeCount(), loaded.Changed(), o.NodeCount(), o.EdgeCount(), o.Changed())
+	}
+	if fingerprint(loaded) != fingerprint(o) {
+		t.Error("loaded delta numbers the pages differently")
+	}
+	for id := range uint32(o.NodeCount()) {
+		if !slices.Equal(loaded.In(id), o.In(id)) {
+			t.Errorf("In(%s) = %v, want %v", o.Title(id), loaded.In(id), o.In(id))
+		}
+	}
+
+	// Saving the graph whole leaves no delta, and an old one no longer fits.
+	if err := buildCSR([][2]string{{"A", "B"}}).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	fresh, _, err := LoadCSRFromCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDelta(DeltaPath(path), fresh); !errors.Is(err, ErrStaleDelta) {
+		t.Errorf("LoadDelta over another snapshot: %v, want ErrStaleDelta", err)
+	}
+	if _, err := NewOverlay(buildCSR([][2]string{{"A", "B"}})).Persist(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(DeltaPath(path)); !os.IsNotExist(err) {
+		t.Errorf("delta left after saving a snapshot: %v", err)
+	}
+}
+
+func TestOverlayDerivedCachesAfterReload(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "graph.cache")
+
+	// B, D and F sort between the base pages, so the saved snapshot numbers
+	// the pages differently from the overlay while keeping its counts.
+	o := NewOverlay(buildCSR([][2]string{{"A", "C"}, {"C", "E"}, {"E", "A"}, {"E", "G"}})).
+		WithOutLinks(map[string][]string{"A": {"B", "C"}, "B": {"D"}, "D": {"F", "A"}, "F": {"G"}})
+	if err := o.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	lmOpts := LandmarkOptions{Count: 3}
+	ranks, err := CachedPageRank(ctx, o, path, PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CachedLandmarks(ctx, o, path, lmOpts); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, _, err := LoadCSRFromCache(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded, err := CachedPageRank(ctx, loaded, path, PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	landmarks, err := CachedLandmarks(ctx, loaded, path, lmOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fresh, err := ComputeLandmarks(ctx, loaded, lmOpts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for from := range uint32(loaded.NodeCount()) {
+		title := loaded.Title(from)
+		got, _ := reloaded.Score(title)
+		want, _ := ranks.Score(title)
+		if !approx(got, want) {
+			t.Errorf("Score(%s) after reload = %g, want %g", title, got, want)
+		}
+		for to := range uint32(loaded.NodeCount()) {
+			got, _ := landmarks.Estimate(title, loaded.Title(to))
+			want, _ := fresh.Estimate(title, loaded.Title(to))
+			if got != want {
+				t.Errorf("Estimate(%s, %s) after reload = %+v, want %+v", title, loaded.Title(to), got, want)
+			}
+		}
+	}
+}
+
+func TestOverlayOf(t *testing.T) {
+	g := New()
+	g.AddEdge("A", "B")
+
+	o, err := OverlayOf(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !equalSlices(o.OutLinkTitles("A"), []string{"B"}) {
+		t.Errorf("OutLinkTitles(A) = %v", o.OutLinkTitles("A"))
+	}
+	if same, _ := OverlayOf(o); same != o {
+		t.Error("an Overlay should be returned as is")
+	}
+}
+
+func TestOverlayNeighborhood(t *testing.T) {
+	o := NewOverlay(neighborhoodGraph().Compact()).WithOutLinks(map[string][]string{"F": {"A"}})
+
+	s := o.GetNeighborhoodWithOptions(context.Background(), "A", NeighborhoodOptions{
+		MaxDepth:  1,
+		MaxNodes:  100,
+		Direction: DirectionIn,
+	})
+	if nodes := subgraphTitles(s); len(nodes) != 4 || nodes["F"].Hops != 1 {
+		t.Errorf("nodes = %v, want A, D, E and F", s.Nodes)
+	}
+}
+
+// sortedTitles returns the titles of ids in title order, as a CSR lists them.
+func sortedTitles(a Adjacency, ids []uint32) []string {
+	titles := make([]string, len(ids))
+	for i, id := range ids {
+		titles[i] = a.Title(id)
+	}
+	slices.Sort(titles)
+	return titles
+}
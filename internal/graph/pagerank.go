@@ -0,0 +1,428 @@
+package graph
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"log/slog"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+)
+
+// ErrPageNotFound is returned when a query names a page that is not in the graph.
+var ErrPageNotFound = errors.New("page not found")
+
+// DanglingMode selects where PageRank sends the score held by pages
+// without out-links.
+type DanglingMode string
+
+const (
+	// DanglingUniform spreads dangling score evenly over every page.
+	DanglingUniform DanglingMode = "uniform"
+	// DanglingTeleport spreads dangling score like a random jump, so a
+	// personalized ranking keeps it near the seeds.
+	DanglingTeleport DanglingMode = "teleport"
+	// DanglingDrop discards dangling score and renormalizes the rest.
+	DanglingDrop DanglingMode = "drop"
+)
+
+// ParseDanglingMode validates a dangling-node mode name. The empty string
+// selects DanglingUniform.
+func ParseDanglingMode(name string) (DanglingMode, error) {
+	switch DanglingMode(name) {
+	case "", DanglingUniform:
+		return DanglingUniform, nil
+	case DanglingTeleport, DanglingDrop:
+		return DanglingMode(name), nil
+	default:
+		return "", fmt.Errorf("unknown dangling mode %q (want %q, %q or %q)",
+			name, DanglingUniform, DanglingTeleport, DanglingDrop)
+	}
+}
+
+// PageRankOptions configures PageRank. Zero fields take the defaults.
+type PageRankOptions struct {
+	// Damping is the probability of following a link rather than jumping
+	// to a random page. Default 0.85.
+	Damping float64
+
+	// Tolerance stops the iteration once the L1 change between two
+	// iterations falls below it. Default 1e-6; a negative tolerance never
+	// stops it early, so exactly MaxIterations are run.
+	Tolerance float64
+
+	// MaxIterations bounds the power iteration. Default 100.
+	MaxIterations int
+
+	// Dangling selects how pages without out-links are handled.
+	// Default DanglingUniform.
+	Dangling DanglingMode
+}
+
+// DefaultPageRankOptions returns the options used when none are given.
+func DefaultPageRankOptions() PageRankOptions {
+	return PageRankOptions{
+		Damping:       0.85,
+		Tolerance:     1e-6,
+		MaxIterations: 100,
+		Dangling:      DanglingUniform,
+	}
+}
+
+func (o PageRankOptions) withDefaults() PageRankOptions {
+	def := DefaultPageRankOptions()
+	if o.Damping == 0 {
+		o.Damping = def.Damping
+	}
+	if o.Tolerance == 0 {
+		o.Tolerance = def.Tolerance
+	}
+	if o.MaxIterations == 0 {
+		o.MaxIterations = def.MaxIterations
+	}
+	if o.Dangling == "" {
+		o.Dangling = def.Dangling
+	}
+	return o
+}
+
+func (o PageRankOptions) validate() error {
+	if o.Damping <= 0 || o.Damping >= 1 {
+		return fmt.Errorf("damping must be between 0 and 1, got %g", o.Damping)
+	}
+	if math.IsNaN(o.Tolerance) {
+		return errors.New("tolerance must be a number")
+	}
+	if o.MaxIterations < 1 {
+		return fmt.Errorf("max iterations must be positive, got %d", o.MaxIterations)
+	}
+	_, err := ParseDanglingMode(string(o.Dangling))
+	return err
+}
+
+// Ranks is a PageRank vector.
+type Ranks struct {
+	NodeScores
+
+	// Seeds lists the pages a personalized ranking was seeded from.
+	Seeds []string
+
+	Options    PageRankOptions
+	Iterations int
+	Converged  bool
+}
+
+// PageRank computes the PageRank of every page in a by power iteration.
+// Returns ctx.Err() if ctx is done before the iteration finishes.
+func PageRank(ctx context.Context, a Adjacency, opts PageRankOptions) (*Ranks, error) {
+	return pageRank(ctx, a, nil, opts)
+}
+
+// PersonalizedPageRank computes PageRank with random jumps landing only on
+// the seed pages, which ranks pages by their importance relative to the seeds.
+// Returns an error wrapping ErrPageNotFound if a seed is not in the graph.
+func PersonalizedPageRank(ctx context.Context, a Adjacency, seeds []string, opts PageRankOptions) (*Ranks, error) {
+	if len(seeds) == 0 {
+		return nil, errors.New("personalized PageRank needs at least one seed")
+	}
+
+	ids := make([]uint32, 0, len(seeds))
+	seen := make(map[uint32]bool, len(seeds))
+	for _, title := range seeds {
+		id, ok := a.Lookup(title)
+		if !ok {
+			return nil, fmt.Errorf("seed %q: %w", title, ErrPageNotFound)
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	r, err := pageRank(ctx, a, ids, opts)
+	if err != nil {
+		return nil, err
+	}
+	r.Seeds = seeds
+	return r, nil
+}
+
+// pageRank runs the power iteration. Random jumps land uniformly on every
+// page, or only on the seeds when seeds is non-empty.
+func pageRank(ctx context.Context, a Adjacency, seeds []uint32, opts PageRankOptions) (*Ranks, error) {
+	opts = opts.withDefaults()
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
+	n := a.NodeCount()
+	r := &Ranks{NodeScores: NodeScores{a: a}, Options: opts, Converged: true}
+	if n == 0 {
+		return r, nil
+	}
+
+	// teleport is the jump distribution; nil means uniform.
+	var teleport []float64
+	if len(seeds) > 0 {
+		teleport = make([]float64, n)
+		for _, id := range seeds {
+			teleport[id] = 1 / float64(len(seeds))
+		}
+	}
+	jump := func(v int) float64 {
+		if teleport == nil {
+			return 1 / float64(n)
+		}
+		return teleport[v]
+	}
+
+	rank := make([]float64, n)
+	for v := range rank {
+		rank[v] = jump(v)
+	}
+	next := make([]float64, n)
+	contrib := make([]float64, n)
+
+	d := opts.Damping
+	workers := min(runtime.GOMAXPROCS(0), max(1, n/4096))
+	r.Converged = false
+
+	for r.Iterations < opts.MaxIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		r.Iterations++
+
+		// Each page passes its score evenly along its out-links.
+		dangling := 0.0
+		for u := range contrib {
+			if deg := len(a.Out(uint32(u))); deg > 0 {
+				contrib[u] = rank[u] / float64(deg)
+			} else {
+				contrib[u] = 0
+				dangling += rank[u]
+			}
+		}
+
+		var danglingShare func(v int) float64
+		switch opts.Dangling {
+		case DanglingUniform:
+			share := d * dangling / float64(n)
+			danglingShare = func(int) float64 { return share }
+		case DanglingTeleport:
+			danglingShare = func(v int) float64 { return d * dangling * jump(v) }
+		default:
+			danglingShare = func(int) float64 { return 0 }
+		}
+
+		// Pull the contributions of in-links, one slice of pages per worker.
+		sums := make([]float64, workers)
+		var wg sync.WaitGroup
+		for w := range workers {
+			lo, hi := w*n/workers, (w+1)*n/workers
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				total := 0.0
+				for v := lo; v < hi; v++ {
+					s := 0.0
+					for _, u := range a.In(uint32(v)) {
+						s += contrib[u]
+					}
+					next[v] = d*s + (1-d)*jump(v) + danglingShare(v)
+					total += next[v]
+				}
+				sums[w] = total
+			}()
+		}
+		wg.Wait()
+
+		if opts.Dangling == DanglingDrop {
+			total := 0.0
+			for _, s := range sums {
+				total += s
+			}
+			for v := range next {
+				next[v] /= total
+			}
+		}
+
+		delta := 0.0
+		for v := range next {
+			delta += math.Abs(next[v] - rank[v])
+		}
+		rank, next = next, rank
+
+		if delta < opts.Tolerance {
+			r.Converged = true
+			break
+		}
+	}
+
+	r.Scores = rank
+	return r, nil
+}
+
+// PageRank cache file layout (all integers little-endian):
+//
+//	magic          8 bytes
+//	version        uint32
+//	graphKey       uint32, the fingerprint of the graph the scores belong to
+//	checksum       uint32, CRC-32C of the scores
+//	iterations     uint32
+//	nodeCount      uint64
+//	damping        float64
+//	tolerance      float64
+//	maxIterations  uint32
+//	dangling       uint8
+//	converged      uint8
+//	padding        to 64 bytes
+//	scores         float64 × nodeCount
+const (
+	ranksMagic      = "WGRANK\x00\x00"
+	ranksVersion    = 2
+	ranksHeaderSize = 64
+)
+
+// ErrStaleRanks is returned when a PageRank cache belongs to a different
+// graph, or to the same pages numbered differently.
+var ErrStaleRanks = errors.New("pagerank cache does not match the graph")
+
+var danglingCodes = []DanglingMode{DanglingUniform, DanglingTeleport, DanglingDrop}
+
+// PageRankPath returns where the PageRank vector for the graph cache at
+// cachePath is stored.
+func PageRankPath(cachePath string) string {
+	return cachePath + ".pagerank"
+}
+
+// SaveRanks writes a global ranking to path, tagged with graphKey, the
+// fingerprint of the graph it was computed from.
+// Uses atomic write (temp file + rename) to prevent corruption.
+func SaveRanks(path string, r *Ranks, graphKey uint32) error {
+	if len(r.Seeds) > 0 {
+		return errors.New("personalized rankings are not cached")
+	}
+
+	buf := make([]byte, ranksHeaderSize+8*len(r.Scores))
+	for i, s := range r.Scores {
+		binary.LittleEndian.PutUint64(buf[ranksHeaderSize+8*i:], math.Float64bits(s))
+	}
+
+	copy(buf, ranksMagic)
+	binary.LittleEndian.PutUint32(buf[8:], ranksVersion)
+	binary.LittleEndian.PutUint32(buf[12:], graphKey)
+	binary.LittleEndian.PutUint32(buf[16:], crc32.Checksum(buf[ranksHeaderSize:], crcTable))
+	binary.LittleEndian.PutUint32(buf[20:], uint32(r.Iterations))
+	binary.LittleEndian.PutUint64(buf[24:], uint64(len(r.Scores)))
+	binary.LittleEndian.PutUint64(buf[32:], math.Float64bits(r.Options.Damping))
+	binary.LittleEndian.PutUint64(buf[40:], math.Float64bits(r.Options.Tolerance))
+	binary.LittleEndian.PutUint32(buf[48:], uint32(r.Options.MaxIterations))
+	for code, mode := range danglingCodes {
+		if mode == r.Options.Dangling {
+			buf[52] = byte(code)
+		}
+	}
+	if r.Converged {
+		buf[53] = 1
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, buf, 0644); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("writing pagerank cache: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming pagerank cache: %w", err)
+	}
+	return nil
+}
+
+// LoadRanks reads a ranking saved by SaveRanks and attaches it to a.
+// Returns ErrStaleRanks if the file was computed from another graph.
+func LoadRanks(path string, a Adjacency, graphKey uint32) (*Ranks, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(buf) < ranksHeaderSize || string(buf[:8]) != ranksMagic {
+		return nil, errors.New("not a pagerank cache")
+	}
+	if v := binary.LittleEndian.Uint32(buf[8:]); v != ranksVersion {
+		return nil, fmt.Errorf("pagerank cache version mismatch: got %d, want %d", v, ranksVersion)
+	}
+
+	n := binary.LittleEndian.Uint64(buf[24:])
+	if binary.LittleEndian.Uint32(buf[12:]) != graphKey || n != uint64(a.NodeCount()) {
+		return nil, ErrStaleRanks
+	}
+	if uint64(len(buf)-ranksHeaderSize) != 8*n {
+		return nil, fmt.Errorf("pagerank cache truncated: %d bytes, want %d", len(buf), ranksHeaderSize+8*n)
+	}
+	if crc32.Checksum(buf[ranksHeaderSize:], crcTable) != binary.LittleEndian.Uint32(buf[16:]) {
+		return nil, errors.New("pagerank cache checksum mismatch")
+	}
+	code := int(buf[52])
+	if code >= len(danglingCodes) {
+		return nil, fmt.Errorf("pagerank cache has unknown dangling mode %d", code)
+	}
+
+	r := &Ranks{
+		NodeScores: NodeScores{Scores: make([]float64, n), a: a},
+		Options: PageRankOptions{
+			Damping:       math.Float64frombits(binary.LittleEndian.Uint64(buf[32:])),
+			Tolerance:     math.Float64frombits(binary.LittleEndian.Uint64(buf[40:])),
+			MaxIterations: int(binary.LittleEndian.Uint32(buf[48:])),
+			Dangling:      danglingCodes[code],
+		},
+		Iterations: int(binary.LittleEndian.Uint32(buf[20:])),
+		Converged:  buf[53] == 1,
+	}
+	for i := range r.Scores {
+		r.Scores[i] = math.Float64frombits(binary.LittleEndian.Uint64(buf[ranksHeaderSize+8*i:]))
+	}
+	return r, nil
+}
+
+// CachedPageRank returns the PageRank of a, reusing the vector stored next
+// to the graph cache at cachePath when it was computed from a graph with
+// the same fingerprint and the same options, and storing a freshly computed
+// one otherwise. Nothing is cached when there is no graph cache.
+func CachedPageRank(ctx context.Context, a Adjacency, cachePath string, opts PageRankOptions) (*Ranks, error) {
+	opts = opts.withDefaults()
+
+	var key uint32
+	cacheable := CacheExists(cachePath)
+	if cacheable {
+		key = fingerprint(a)
+		r, err := LoadRanks(PageRankPath(cachePath), a, key)
+		switch {
+		case err == nil && r.Options == opts:
+			slog.Debug("pagerank loaded from cache", "path", PageRankPath(cachePath))
+			return r, nil
+		case err != nil && !errors.Is(err, os.ErrNotExist) && !errors.Is(err, ErrStaleRanks):
+			slog.Warn("ignoring unreadable pagerank cache", "error", err)
+		}
+	}
+
+	r, err := PageRank(ctx, a, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if cacheable {
+		if err := SaveRanks(PageRankPath(cachePath), r, key); err != nil {
+			slog.Warn("failed to save pagerank cache", "error", err)
+		}
+	}
+	return r, nil
+}
@@ -0,0 +1,198 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func sumScores(scores []float64) float64 {
+	total := 0.0
+	for _, s := range scores {
+		total += s
+	}
+	return total
+}
+
+func TestPageRankCycleIsUniform(t *testing.T) {
+	c := buildCSR([][2]string{{"A", "B"}, {"B", "C"}, {"C", "D"}, {"D", "A"}})
+
+	r, err := PageRank(context.Background(), c, PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Converged {
+		t.Errorf("did not converge in %d iterations", r.Iterations)
+	}
+	for id, s := range r.Scores {
+		if math.Abs(s-0.25) > 1e-9 {
+			t.Errorf("score of %s = %g, want 0.25", c.Title(uint32(id)), s)
+		}
+	}
+}
+
+func TestTopBreaksTiesByTitle(t *testing.T) {
+	// A is added after the base, so its ID comes after those of B, C and D.
+	o := NewOverlay(buildCSR([][2]string{{"B", "C"}, {"C", "D"}, {"D", "B"}})).
+		WithOutLinks(map[string][]string{"D": {"A"}, "A": {"B"}})
+
+	r, err := PageRank(context.Background(), o, PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, p := range r.Top(4) {
+		titles = append(titles, p.Title)
+	}
+	if !equalSlices(titles, []string{"A", "B", "C", "D"}) {
+		t.Errorf("Top(4) = %v, want A, B, C, D", titles)
+	}
+}
+
+func TestPageRankDanglingModes(t *testing.T) {
+	// Hub has no out-links and everyone links to it.
+	c := buildCSR([][2]string{{"A", "Hub"}, {"B", "Hub"}, {"C", "Hub"}, {"C", "A"}})
+
+	for _, mode := range []DanglingMode{DanglingUniform, DanglingTeleport, DanglingDrop} {
+		t.Run(string(mode), func(t *testing.T) {
+			r, err := PageRank(context.Background(), c, PageRankOptions{Dangling: mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total := sumScores(r.Scores); math.Abs(total-1) > 1e-9 {
+				t.Errorf("scores sum to %g, want 1", total)
+			}
+			top := r.Top(2)
+			if len(top) != 2 || top[0].Title != "Hub" || top[1].Title != "A" {
+				t.Errorf("Top(2) = %v, want Hub then A", top)
+			}
+		})
+	}
+}
+
+func TestPageRankNegativeToleranceRunsEveryIteration(t *testing.T) {
+	c := buildCSR([][2]string{{"A", "B"}, {"B", "C"}, {"C", "D"}, {"D", "A"}})
+
+	r, err := PageRank(context.Background(), c, PageRankOptions{Tolerance: -1, MaxIterations: 7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Converged || r.Iterations != 7 {
+		t.Errorf("converged %v after %d iterations, want all 7 run", r.Converged, r.Iterations)
+	}
+}
+
+func TestPersonalizedPageRank(t *testing.T) {
+	// Two cycles joined by a single link from the first to the second.
+	c := buildCSR([][2]string{
+		{"A1", "A2"}, {"A2", "A3"}, {"A3", "A1"},
+		{"B1", "B2"}, {"B2", "B3"}, {"B3", "B1"},
+		{"A3", "B1"},
+	})
+	ctx := context.Background()
+
+	r, err := PersonalizedPageRank(ctx, c, []string{"B2"}, PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"A1", "A2", "A3"} {
+		if s, _ := r.Score(title); s > 1e-12 {
+			t.Errorf("%s is unreachable from the seed but scored %g", title, s)
+		}
+	}
+	if top := r.Top(1); top[0].Title != "B2" {
+		t.Errorf("top page = %s, want the seed", top[0].Title)
+	}
+
+	if _, err := PersonalizedPageRank(ctx, c, []string{"Nowhere"}, PageRankOptions{}); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("unknown seed: err = %v, want ErrPageNotFound", err)
+	}
+}
+
+func TestPageRankBackendsAgree(t *testing.T) {
+	g := New()
+	for _, e := range [][2]string{{"A", "B"}, {"A", "C"}, {"B", "C"}, {"C", "A"}, {"D", "C"}} {
+		g.AddEdge(e[0], e[1])
+	}
+
+	want, err := PageRank(context.Background(), g.Adjacency(), PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := PageRank(context.Background(), g.Compact(), PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, title := range []string{"A", "B", "C", "D"} {
+		w, _ := want.Score(title)
+		s, _ := got.Score(title)
+		if w != s {
+			t.Errorf("%s: %g != %g", title, s, w)
+		}
+	}
+}
+
+func TestPageRankErrors(t *testing.T) {
+	c := buildCSR([][2]string{{"A", "B"}})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := PageRank(ctx, c, PageRankOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled: err = %v", err)
+	}
+
+	if _, err := PageRank(context.Background(), c, PageRankOptions{Damping: 1.5}); err == nil {
+		t.Error("expected an error for damping > 1")
+	}
+	if _, err := ParseDanglingMode("sideways"); err == nil {
+		t.Error("expected an error for an unknown dangling mode")
+	}
+}
+
+func TestCachedPageRank(t *testing.T) {
+	dir := t.TempDir()
+	cachePath := filepath.Join(dir, "graph.cache")
+	ctx := context.Background()
+
+	c := buildCSR([][2]string{{"A", "B"}, {"B", "C"}, {"C", "A"}, {"C", "D"}})
+	if err := c.Save(cachePath); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := CachedPageRank(ctx, c, cachePath, PageRankOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !CacheExists(PageRankPath(cachePath)) {
+		t.Fatal("pagerank cache was not written")
+	}
+
+	key := fingerprint(c)
+	loaded, err := LoadRanks(PageRankPath(cachePath), c, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Options != first.Options || loaded.Iterations != first.Iterations {
+		t.Errorf("loaded %+v, want %+v", loaded.Options, first.Options)
+	}
+	for i := range first.Scores {
+		if loaded.Scores[i] != first.Scores[i] {
+			t.Fatalf("score %d = %g, want %g", i, loaded.Scores[i], first.Scores[i])
+		}
+	}
+
+	if _, err := LoadRanks(PageRankPath(cachePath), c, key+1); !errors.Is(err, ErrStaleRanks) {
+		t.Errorf("other graph: err = %v, want ErrStaleRanks", err)
+	}
+
+	// Different options are recomputed, not served from the cache.
+	other, err := CachedPageRank(ctx, c, cachePath, PageRankOptions{Damping: 0.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other.Options.Damping != 0.5 {
+		t.Errorf("damping = %g, want 0.5", other.Options.Damping)
+	}
+}
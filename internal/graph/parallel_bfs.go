@@ -0,0 +1,146 @@
+package graph
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// parallelFrontierThreshold is the frontier size at which a breadth-first
+// path search moves from one goroutine to all cores. Smaller frontiers
+// finish faster than the workers can be started. A variable so tests can
+// force either mode.
+var parallelFrontierThreshold = 4096
+
+// shouldGoParallel reports whether a search whose next level holds
+// frontier nodes should continue with parallelSearch.
+func shouldGoParallel(frontier int) bool {
+	return frontier >= parallelFrontierThreshold && runtime.GOMAXPROCS(0) > 1
+}
+
+// bitmap is a fixed-size set of node IDs that goroutines may add to
+// concurrently.
+type bitmap []atomic.Uint64
+
+func newBitmap(n int) bitmap {
+	return make(bitmap, (n+63)/64)
+}
+
+// add inserts id and reports whether it was not in the set before. Of
+// several goroutines adding the same id, exactly one sees true.
+func (s bitmap) add(id uint32) bool {
+	w, mask := &s[id/64], uint64(1)<<(id%64)
+	if w.Load()&mask != 0 {
+		return false
+	}
+	return w.Or(mask)&mask == 0
+}
+
+// parallelSearch continues a breadth-first search from the root of parent
+// towards to, one level at a time. parent maps every node reached so far to
+// the node it was reached from, with the root as its own parent; frontier
+// holds the nodes depth hops from the root, still to be expanded.
+//
+// Each level is split into one contiguous slice per core. Workers claim
+// newly reached nodes in a shared bitmap, so every node gets exactly one
+// parent, and collect them into private next-level slices. The path found
+// is a shortest one, but which of several it is can vary between runs. The
+// budget is spent a level at a time.
+func parallelSearch(a Adjacency, parent map[uint32]uint32, frontier []uint32, to uint32, depth, maxDepth int, b *budget) PathResult {
+	n := a.NodeCount()
+	seen := newBitmap(n)
+	parents := make([]uint32, n)
+	for v, p := range parent {
+		seen.add(v)
+		parents[v] = p
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	next := make([][]uint32, workers)
+
+	for len(frontier) > 0 {
+		if maxDepth >= 0 && depth >= maxDepth {
+			break
+		}
+		granted := b.spendLevel(len(frontier))
+		if granted == 0 {
+			return b.finish(PathResult{})
+		}
+		frontier = frontier[:granted]
+
+		var found, canceled atomic.Bool
+		var wg sync.WaitGroup
+		for w := range workers {
+			lo, hi := w*len(frontier)/workers, (w+1)*len(frontier)/workers
+			local := next[w][:0]
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i, u := range frontier[lo:hi] {
+					if found.Load() {
+						break
+					}
+					if i%cancelCheckInterval == cancelCheckInterval-1 && b.ctx.Err() != nil {
+						canceled.Store(true)
+						break
+					}
+					for _, v := range a.Out(u) {
+						if !seen.add(v) {
+							continue
+						}
+						parents[v] = u
+						if v == to {
+							found.Store(true)
+							break
+						}
+						local = append(local, v)
+					}
+				}
+				next[w] = local
+			}()
+		}
+		wg.Wait()
+
+		if found.Load() {
+			return b.finish(PathResult{
+				Found: true,
+				Path:  titlesFromParents(a, parents, to),
+				Hops:  depth + 1,
+			})
+		}
+		if canceled.Load() {
+			b.canceled = true
+			return b.finish(PathResult{})
+		}
+
+		size := 0
+		for _, local := range next {
+			size += len(local)
+		}
+		frontier = make([]uint32, 0, size)
+		for _, local := range next {
+			frontier = append(frontier, local...)
+		}
+		depth++
+	}
+
+	return b.finish(PathResult{})
+}
+
+// titlesFromParents walks parent links back from to; the root is its own
+// parent.
+func titlesFromParents(a Adjacency, parents []uint32, to uint32) []string {
+	var ids []uint32
+	for n := to; ; n = parents[n] {
+		ids = append(ids, n)
+		if parents[n] == n {
+			break
+		}
+	}
+
+	result := make([]string, len(ids))
+	for i, id := range ids {
+		result[len(ids)-1-i] = a.Title(id)
+	}
+	return result
+}
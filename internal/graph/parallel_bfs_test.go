@@ -0,0 +1,206 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"runtime"
+	"testing"
+)
+
+// forceParallel makes every path search past its first level run in
+// parallel on four workers until the test ends.
+func forceParallel(tb testing.TB) {
+	threshold, procs := parallelFrontierThreshold, runtime.GOMAXPROCS(4)
+	parallelFrontierThreshold = 1
+	tb.Cleanup(func() {
+		parallelFrontierThreshold = threshold
+		runtime.GOMAXPROCS(procs)
+	})
+}
+
+// forceSequential keeps every path search on one goroutine until the test
+// ends.
+func forceSequential(tb testing.TB) {
+	threshold := parallelFrontierThreshold
+	parallelFrontierThreshold = math.MaxInt
+	tb.Cleanup(func() { parallelFrontierThreshold = threshold })
+}
+
+// pointerGraph copies c into the pointer-based representation.
+func pointerGraph(c *CSR) *Graph {
+	g := NewWithCapacity(c.NodeCount())
+	for u := range uint32(c.NodeCount()) {
+		g.AddNode(c.Title(u))
+		for _, v := range c.Out(u) {
+			g.AddEdgeUnchecked(c.Title(u), c.Title(v))
+		}
+	}
+	return g
+}
+
+func TestParallelSearchMatchesSequential(t *testing.T) {
+	ctx := context.Background()
+	c := randomCSR(2000, 5000, 9)
+	g := pointerGraph(c)
+	g.Compact()
+
+	type query struct{ from, to string }
+	var queries []query
+	want := make(map[query]PathResult)
+	for i := range 200 {
+		q := query{nodeName(i * 7 % 2000), nodeName((i*131 + 5) % 2000)}
+		queries = append(queries, q)
+	}
+
+	t.Run("sequential", func(t *testing.T) {
+		forceSequential(t)
+		for _, q := range queries {
+			want[q] = findPathIndexed(ctx, c, q.from, q.to, -1, 0)
+		}
+	})
+
+	forceParallel(t)
+	for _, q := range queries {
+		w := want[q]
+		for name, view := range map[string]View{"csr": c, "graph": g} {
+			got := view.FindPathContext(ctx, q.from, q.to, -1, 0)
+			if got.Found != w.Found || got.Hops != w.Hops {
+				t.Fatalf("%s: %s -> %s: found %v in %d hops, want %v in %d",
+					name, q.from, q.to, got.Found, got.Hops, w.Found, w.Hops)
+			}
+			if got.Found && (!isPath(c, got.Path) || got.Path[0] != q.from || got.Path[len(got.Path)-1] != q.to) {
+				t.Fatalf("%s: %s -> %s: %v is not a path between them", name, q.from, q.to, got.Path)
+			}
+		}
+	}
+}
+
+func TestPointerGraphParallelNeedsCachedCSR(t *testing.T) {
+	ctx := context.Background()
+	c := randomCSR(2000, 5000, 9)
+	g := pointerGraph(c)
+
+	// The first page node_0 reaches in three or more hops.
+	forceSequential(t)
+	from, to := nodeName(0), ""
+	var want PathResult
+	for i := 1; i < c.NodeCount() && to == ""; i++ {
+		if want = g.FindPathContext(ctx, from, nodeName(i), -1, 0); want.Found && want.Hops >= 3 {
+			to = nodeName(i)
+		}
+	}
+	if to == "" {
+		t.Fatal("no page three hops from node_0")
+	}
+
+	// Without a cached CSR the search stays on the pointer graph rather
+	// than building one.
+	forceParallel(t)
+	got := g.FindPathContext(ctx, from, to, -1, 0)
+	if g.compact.Load() != nil {
+		t.Error("search past the threshold built the CSR")
+	}
+	if !got.Found || got.Hops != want.Hops || !isPath(c, got.Path) {
+		t.Errorf("serial: %+v, want a path of %d hops", got, want.Hops)
+	}
+
+	g.Compact()
+	got = g.FindPathContext(ctx, from, to, -1, 0)
+	if !got.Found || got.Hops != want.Hops || !isPath(c, got.Path) {
+		t.Errorf("parallel: %+v, want a path of %d hops", got, want.Hops)
+	}
+}
+
+func TestParallelSearchLimits(t *testing.T) {
+	forceParallel(t)
+	ctx := context.Background()
+
+	// A complete binary tree: node i links to 2i+1 and 2i+2.
+	b := NewCSRBuilder(1023, 1022)
+	for i := 1; i < 1023; i++ {
+		b.AddEdge(nodeName((i-1)/2), nodeName(i))
+	}
+	c := b.Build()
+
+	if r := c.FindPathContext(ctx, nodeName(0), nodeName(1022), -1, 0); !r.Found || r.Hops != 9 {
+		t.Fatalf("found %v in %d hops, want 9", r.Found, r.Hops)
+	}
+	if r := c.FindPathContext(ctx, nodeName(0), nodeName(1022), 8, 0); r.Found || r.Truncated {
+		t.Errorf("max depth 8: %+v, want a plain miss", r)
+	}
+
+	r := c.FindPathContext(ctx, nodeName(0), nodeName(1022), -1, 100)
+	if r.Found || !r.BudgetExhausted || r.Explored != 100 {
+		t.Errorf("budget: %+v, want exhausted after 100 nodes", r)
+	}
+
+	// The budget covers the first four levels and half of the fifth, so
+	// node_30, the parent of node_62, is never expanded.
+	r = c.FindPathContext(ctx, nodeName(0), nodeName(62), 5, 23)
+	if r.Found || !r.BudgetExhausted || r.Explored != 23 {
+		t.Errorf("budget cutting a level: %+v, want exhausted after 23 nodes", r)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if r := c.FindPathContext(canceled, nodeName(0), nodeName(1022), -1, 0); r.Found || !r.Truncated {
+		t.Errorf("canceled: %+v, want truncated", r)
+	}
+}
+
+func TestBitmapAdd(t *testing.T) {
+	s := newBitmap(130)
+	for _, id := range []uint32{0, 63, 64, 129} {
+		if !s.add(id) {
+			t.Errorf("first add(%d) = false", id)
+		}
+		if s.add(id) {
+			t.Errorf("second add(%d) = true", id)
+		}
+	}
+	if !s.add(1) {
+		t.Error("add(1) = false next to a set bit")
+	}
+}
+
+// benchmarkGraph is a random graph with about ten links per page, wide
+// enough that BFS frontiers reach tens of thousands of pages.
+func benchmarkGraph(b *testing.B, n int) *CSR {
+	b.Helper()
+	return randomCSR(n, 10*n, 42)
+}
+
+func BenchmarkFindPath_RandomGraph(b *testing.B) {
+	for _, n := range []int{100_000, 500_000} {
+		c := benchmarkGraph(b, n)
+		g := pointerGraph(c)
+		g.Compact()
+		// The pages are random, so the search covers much of the graph
+		// before it reaches the target.
+		from, to := nodeName(0), nodeName(n-1)
+
+		b.Run(fmt.Sprintf("nodes=%d/pointer", n), func(b *testing.B) {
+			forceSequential(b)
+			for b.Loop() {
+				g.FindPath(from, to)
+			}
+		})
+		b.Run(fmt.Sprintf("nodes=%d/csr", n), func(b *testing.B) {
+			forceSequential(b)
+			for b.Loop() {
+				c.FindPath(from, to)
+			}
+		})
+		b.Run(fmt.Sprintf("nodes=%d/csr-parallel", n), func(b *testing.B) {
+			for b.Loop() {
+				c.FindPath(from, to)
+			}
+		})
+		b.Run(fmt.Sprintf("nodes=%d/pointer-parallel", n), func(b *testing.B) {
+			for b.Loop() {
+				g.FindPath(from, to)
+			}
+		})
+	}
+}
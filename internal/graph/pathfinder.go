@@ -1,29 +1,37 @@
 package graph
 
+import "context"
+
 type PathResult struct {
 	Found    bool
 	Path     []string
 	Hops     int
 	Explored int
+
+	// Truncated reports that the search stopped early because its context
+	// was done or its node budget ran out, so a path may still exist.
+	Truncated bool
+	// BudgetExhausted reports that the search hit its explored-node cap.
+	BudgetExhausted bool
 }
 
-// nodeQueue implements a simple queue using head/tail indices to avoid
+// ringQueue implements a simple queue using head/tail indices to avoid
 // repeated memory allocations during BFS traversal.
-type nodeQueue struct {
-	items []*Node
+type ringQueue[T any] struct {
+	items []T
 	head  int
 	tail  int
 }
 
-func newNodeQueue(capacity int) *nodeQueue {
-	return &nodeQueue{
-		items: make([]*Node, capacity),
+func newRingQueue[T any](capacity int) *ringQueue[T] {
+	return &ringQueue[T]{
+		items: make([]T, capacity),
 	}
 }
 
-func (q *nodeQueue) push(n *Node) {
+func (q *ringQueue[T]) push(n T) {
 	if q.tail >= len(q.items) {
-		newItems := make([]*Node, len(q.items)*2)
+		newItems := make([]T, len(q.items)*2)
 		copy(newItems, q.items[q.head:q.tail])
 		q.items = newItems
 		q.tail -= q.head
@@ -33,21 +41,28 @@ func (q *nodeQueue) push(n *Node) {
 	q.tail++
 }
 
-func (q *nodeQueue) pop() *Node {
+func (q *ringQueue[T]) pop() (T, bool) {
+	var zero T
 	if q.head >= q.tail {
-		return nil
+		return zero, false
 	}
 	n := q.items[q.head]
-	q.items[q.head] = nil
+	q.items[q.head] = zero
 	q.head++
-	return n
+	return n, true
 }
 
-func (q *nodeQueue) len() int {
+func (q *ringQueue[T]) len() int {
 	return q.tail - q.head
 }
 
-func (q *nodeQueue) reset() {
+// pending returns the queued items, oldest first. The slice is only valid
+// until the next push.
+func (q *ringQueue[T]) pending() []T {
+	return q.items[q.head:q.tail]
+}
+
+func (q *ringQueue[T]) reset() {
 	q.head = 0
 	q.tail = 0
 }
@@ -57,6 +72,16 @@ func (g *Graph) FindPath(from, to string) PathResult {
 }
 
 func (g *Graph) FindPathWithLimit(from, to string, maxDepth int) PathResult {
+	return g.FindPathContext(context.Background(), from, to, maxDepth, 0)
+}
+
+// FindPathContext is FindPathWithLimit that gives up when ctx is done or
+// after expanding maxExplored nodes (zero for no cap). Once a level grows
+// past parallelFrontierThreshold nodes and the CSR form of the graph is
+// cached, the search continues on it with parallelSearch. Building the CSR
+// here would stall writers for a full pass over the graph, so it is left
+// to Compact, which GraphService calls once per loaded graph.
+func (g *Graph) FindPathContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -74,10 +99,10 @@ func (g *Graph) FindPathWithLimit(from, to string, maxDepth int) PathResult {
 	visited := make(map[*Node]bool)
 	parent := make(map[*Node]*Node)
 
-	queue := newNodeQueue(64)
+	queue := newRingQueue[*Node](64)
 	queue.push(fromNode)
 	visited[fromNode] = true
-	explored := 0
+	b := newBudget(ctx, maxExplored)
 	depth := 0
 
 	currentLevelCount := 1
@@ -89,11 +114,13 @@ func (g *Graph) FindPathWithLimit(from, to string, maxDepth int) PathResult {
 		}
 
 		for i := 0; i < currentLevelCount; i++ {
-			current := queue.pop()
-			if current == nil {
+			current, ok := queue.pop()
+			if !ok {
 				break
 			}
-			explored++
+			if !b.spend() {
+				return b.finish(PathResult{})
+			}
 
 			for _, neighbor := range current.OutLinks {
 				if visited[neighbor] {
@@ -103,12 +130,11 @@ func (g *Graph) FindPathWithLimit(from, to string, maxDepth int) PathResult {
 				visited[neighbor] = true
 				parent[neighbor] = current
 				if neighbor == toNode {
-					return PathResult{
-						Found:    true,
-						Path:     reconstructPath(parent, fromNode, toNode),
-						Hops:     depth + 1,
-						Explored: explored,
-					}
+					return b.finish(PathResult{
+						Found: true,
+						Path:  reconstructPath(parent, fromNode, toNode),
+						Hops:  depth + 1,
+					})
 				}
 
 				queue.push(neighbor)
@@ -118,9 +144,36 @@ func (g *Graph) FindPathWithLimit(from, to string, maxDepth int) PathResult {
 		currentLevelCount = nextLevelCount
 		nextLevelCount = 0
 		depth++
+
+		if shouldGoParallel(currentLevelCount) {
+			if c := g.compact.Load(); c != nil {
+				return parallelSearchNodes(c, parent, fromNode, queue.pending(), toNode, depth, maxDepth, b)
+			}
+		}
 	}
 
-	return PathResult{Explored: explored}
+	return b.finish(PathResult{})
+}
+
+// parallelSearchNodes hands a search over the pointer-based graph on to
+// parallelSearch over c, its CSR form.
+func parallelSearchNodes(c *CSR, parent map[*Node]*Node, root *Node, frontier []*Node, to *Node, depth, maxDepth int, b *budget) PathResult {
+	id := func(n *Node) uint32 {
+		id, _ := c.Lookup(n.Title)
+		return id
+	}
+
+	rootID := id(root)
+	ids := make(map[uint32]uint32, len(parent)+1)
+	ids[rootID] = rootID
+	for n, p := range parent {
+		ids[id(n)] = id(p)
+	}
+	next := make([]uint32, len(frontier))
+	for i, n := range frontier {
+		next[i] = id(n)
+	}
+	return parallelSearch(c, ids, next, id(to), depth, maxDepth, b)
 }
 
 func (g *Graph) FindPathBidirectional(from, to string) PathResult {
@@ -128,6 +181,12 @@ func (g *Graph) FindPathBidirectional(from, to string) PathResult {
 }
 
 func (g *Graph) FindPathBidirectionalWithLimit(from, to string, maxDepth int) PathResult {
+	return g.FindPathBidirectionalContext(context.Background(), from, to, maxDepth, 0)
+}
+
+// FindPathBidirectionalContext is FindPathBidirectionalWithLimit that gives
+// up when ctx is done or after expanding maxExplored nodes (zero for no cap).
+func (g *Graph) FindPathBidirectionalContext(ctx context.Context, from, to string, maxDepth, maxExplored int) PathResult {
 	g.mu.RLock()
 	defer g.mu.RUnlock()
 
@@ -150,7 +209,7 @@ func (g *Graph) FindPathBidirectionalWithLimit(from, to string, maxDepth int) Pa
 	parentB := map[*Node]*Node{}
 	queueB := []*Node{toNode}
 
-	explored := 0
+	b := newBudget(ctx, maxExplored)
 	depth := 0
 
 	for len(queueF) > 0 && len(queueB) > 0 {
@@ -160,27 +219,32 @@ func (g *Graph) FindPathBidirectionalWithLimit(from, to string, maxDepth int) Pa
 
 		if len(queueF) <= len(queueB) {
 			var meeting *Node
-			queueF, meeting = expandForward(queueF, visitedF, parentF, visitedB, &explored)
+			queueF, meeting = expandForward(queueF, visitedF, parentF, visitedB, b)
 			if meeting != nil {
-				return buildBidiPath(parentF, parentB, fromNode, toNode, meeting, explored)
+				return b.finish(buildBidiPath(parentF, parentB, fromNode, toNode, meeting))
 			}
 		} else {
 			var meeting *Node
-			queueB, meeting = expandBackward(queueB, visitedB, parentB, visitedF, &explored)
+			queueB, meeting = expandBackward(queueB, visitedB, parentB, visitedF, b)
 			if meeting != nil {
-				return buildBidiPath(parentF, parentB, fromNode, toNode, meeting, explored)
+				return b.finish(buildBidiPath(parentF, parentB, fromNode, toNode, meeting))
 			}
 		}
 		depth++
 	}
 
-	return PathResult{Explored: explored}
+	return b.finish(PathResult{})
 }
 
-func expandForward(queue []*Node, visited map[*Node]bool, parent map[*Node]*Node, other map[*Node]bool, explored *int) ([]*Node, *Node) {
+// expandForward grows the frontier one level along out-links. It returns
+// the meeting node if the searches touch, or an empty frontier once the
+// budget stops the search.
+func expandForward(queue []*Node, visited map[*Node]bool, parent map[*Node]*Node, other map[*Node]bool, b *budget) ([]*Node, *Node) {
 	var nextFrontier []*Node
 	for _, node := range queue {
-		(*explored)++
+		if !b.spend() {
+			return nil, nil
+		}
 		for _, neighbor := range node.OutLinks {
 			if visited[neighbor] {
 				continue
@@ -196,10 +260,13 @@ func expandForward(queue []*Node, visited map[*Node]bool, parent map[*Node]*Node
 	return nextFrontier, nil
 }
 
-func expandBackward(queue []*Node, visited map[*Node]bool, parent map[*Node]*Node, other map[*Node]bool, explored *int) ([]*Node, *Node) {
+// expandBackward is expandForward along in-links.
+func expandBackward(queue []*Node, visited map[*Node]bool, parent map[*Node]*Node, other map[*Node]bool, b *budget) ([]*Node, *Node) {
 	var nextFrontier []*Node
 	for _, node := range queue {
-		(*explored)++
+		if !b.spend() {
+			return nil, nil
+		}
 		for _, neighbor := range node.InLinks {
 			if visited[neighbor] {
 				continue
@@ -215,7 +282,7 @@ func expandBackward(queue []*Node, visited map[*Node]bool, parent map[*Node]*Nod
 	return nextFrontier, nil
 }
 
-func buildBidiPath(parentF, parentB map[*Node]*Node, from, to, meeting *Node, explored int) PathResult {
+func buildBidiPath(parentF, parentB map[*Node]*Node, from, to, meeting *Node) PathResult {
 	var pathF []*Node
 	for n := meeting; n != nil; n = parentF[n] {
 		pathF = append(pathF, n)
@@ -234,10 +301,9 @@ func buildBidiPath(parentF, parentB map[*Node]*Node, from, to, meeting *Node, ex
 	}
 
 	return PathResult{
-		Found:    true,
-		Path:     path,
-		Hops:     len(path) - 1,
-		Explored: explored,
+		Found: true,
+		Path:  path,
+		Hops:  len(path) - 1,
 	}
 }
 
@@ -256,3 +322,190 @@ func reconstructPath(parent map[*Node]*Node, from, to *Node) []string {
 
 	return result
 }
+
+// findPathIndexed is FindPathContext over integer node IDs. Once a level
+// grows past parallelFrontierThreshold nodes it continues with
+// parallelSearch.
+func findPathIndexed(ctx context.Context, a Adjacency, from, to string, maxDepth, maxExplored int) PathResult {
+	fromID, okFrom := a.Lookup(from)
+	toID, okTo := a.Lookup(to)
+
+	if !okFrom || !okTo {
+		return PathResult{}
+	}
+
+	if fromID == toID {
+		return PathResult{Found: true, Path: []string{a.Title(fromID)}, Hops: 0, Explored: 1}
+	}
+
+	parent := map[uint32]uint32{fromID: fromID}
+
+	queue := newRingQueue[uint32](64)
+	queue.push(fromID)
+	b := newBudget(ctx, maxExplored)
+	depth := 0
+
+	currentLevelCount := 1
+	nextLevelCount := 0
+
+	for queue.len() > 0 {
+		if maxDepth >= 0 && depth >= maxDepth {
+			break
+		}
+
+		for i := 0; i < currentLevelCount; i++ {
+			current, ok := queue.pop()
+			if !ok {
+				break
+			}
+			if !b.spend() {
+				return b.finish(PathResult{})
+			}
+
+			for _, neighbor := range a.Out(current) {
+				if _, seen := parent[neighbor]; seen {
+					continue
+				}
+
+				parent[neighbor] = current
+				if neighbor == toID {
+					return b.finish(PathResult{
+						Found: true,
+						Path:  reconstructIndexedPath(a, parent, toID),
+						Hops:  depth + 1,
+					})
+				}
+
+				queue.push(neighbor)
+				nextLevelCount++
+			}
+		}
+		currentLevelCount = nextLevelCount
+		nextLevelCount = 0
+		depth++
+
+		if shouldGoParallel(currentLevelCount) {
+			return parallelSearch(a, parent, queue.pending(), toID, depth, maxDepth, b)
+		}
+	}
+
+	return b.finish(PathResult{})
+}
+
+// findPathBidirectionalIndexed is FindPathBidirectionalContext over integer node IDs.
+func findPathBidirectionalIndexed(ctx context.Context, a Adjacency, from, to string, maxDepth, maxExplored int) PathResult {
+	fromID, okFrom := a.Lookup(from)
+	toID, okTo := a.Lookup(to)
+
+	if !okFrom || !okTo {
+		return PathResult{}
+	}
+
+	if fromID == toID {
+		return PathResult{Found: true, Path: []string{a.Title(fromID)}, Hops: 0, Explored: 1}
+	}
+
+	b := newBudget(ctx, maxExplored)
+	ids := bidirectionalPathIDs(a, fromID, toID, maxDepth, nil, b)
+	if ids == nil {
+		return b.finish(PathResult{})
+	}
+
+	return b.finish(PathResult{
+		Found: true,
+		Path:  idPathsToTitles(a, [][]uint32{ids})[0],
+		Hops:  len(ids) - 1,
+	})
+}
+
+// bidirectionalPathIDs alternates BFS levels from both ends, always growing
+// the smaller frontier, and never enters a node for which skipNode returns
+// true. skipNode may be nil. Returns nil if no path exists within maxDepth
+// hops or the budget stops the search.
+func bidirectionalPathIDs(a Adjacency, fromID, toID uint32, maxDepth int, skipNode func(uint32) bool, b *budget) []uint32 {
+	if fromID == toID {
+		return []uint32{fromID}
+	}
+
+	// A node is its own parent at the root of each search tree.
+	parentF := map[uint32]uint32{fromID: fromID}
+	queueF := []uint32{fromID}
+
+	parentB := map[uint32]uint32{toID: toID}
+	queueB := []uint32{toID}
+
+	depth := 0
+
+	for len(queueF) > 0 && len(queueB) > 0 {
+		if maxDepth >= 0 && depth >= maxDepth {
+			break
+		}
+
+		var meeting uint32
+		var met bool
+		if len(queueF) <= len(queueB) {
+			queueF, meeting, met = expandIndexed(queueF, a.Out, parentF, parentB, skipNode, b)
+		} else {
+			queueB, meeting, met = expandIndexed(queueB, a.In, parentB, parentF, skipNode, b)
+		}
+		if met {
+			return joinBidiPath(parentF, parentB, meeting)
+		}
+		depth++
+	}
+
+	return nil
+}
+
+func expandIndexed(queue []uint32, next func(uint32) []uint32, parent, other map[uint32]uint32, skipNode func(uint32) bool, b *budget) ([]uint32, uint32, bool) {
+	var nextFrontier []uint32
+	for _, node := range queue {
+		if !b.spend() {
+			return nil, 0, false
+		}
+		for _, neighbor := range next(node) {
+			if _, seen := parent[neighbor]; seen {
+				continue
+			}
+			if skipNode != nil && skipNode(neighbor) {
+				continue
+			}
+			parent[neighbor] = node
+			if _, hit := other[neighbor]; hit {
+				return nil, neighbor, true
+			}
+			nextFrontier = append(nextFrontier, neighbor)
+		}
+	}
+	return nextFrontier, 0, false
+}
+
+// joinBidiPath stitches the forward and backward search trees at meeting.
+func joinBidiPath(parentF, parentB map[uint32]uint32, meeting uint32) []uint32 {
+	ids := indexedPathIDs(parentF, meeting)
+	for n := meeting; parentB[n] != n; {
+		n = parentB[n]
+		ids = append(ids, n)
+	}
+	return ids
+}
+
+// reconstructIndexedPath walks parent links back from to; the root is its own parent.
+func reconstructIndexedPath(a Adjacency, parent map[uint32]uint32, to uint32) []string {
+	length := 1
+	for n := to; parent[n] != n; n = parent[n] {
+		length++
+	}
+
+	result := make([]string, length)
+	i := length - 1
+	for n := to; ; n = parent[n] {
+		result[i] = a.Title(n)
+		i--
+		if parent[n] == n {
+			break
+		}
+	}
+
+	return result
+}
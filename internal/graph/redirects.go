@@ -0,0 +1,123 @@
+package graph
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/wikititle"
+)
+
+// Redirects maps redirect titles to the canonical pages they lead to, so a
+// link to "USA" and a link to "United States" meet at the same node.
+// A nil *Redirects resolves nothing.
+type Redirects struct {
+	canonical map[string]string
+}
+
+// ResolveRedirects follows every chain in targets, which maps each redirect
+// title to the title it redirects to, through to the final page. Titles on
+// a redirect loop, or whose chain runs into one, cannot be resolved; they
+// are left out of the result and returned sorted in loops.
+func ResolveRedirects(targets map[string]string) (r *Redirects, loops []string) {
+	r = &Redirects{canonical: make(map[string]string, len(targets))}
+
+	const (
+		visiting = iota + 1
+		unresolvable
+	)
+	state := make(map[string]int)
+	var chain []string
+
+	for start := range targets {
+		if _, done := r.canonical[start]; done || state[start] != 0 {
+			continue
+		}
+
+		// Walk until a page that is not a redirect, a title resolved
+		// earlier, or a title seen before.
+		chain = chain[:0]
+		title := start
+		final, ok := "", true
+		for {
+			if c, done := r.canonical[title]; done {
+				final = c
+				break
+			}
+			if state[title] != 0 {
+				ok = false
+				break
+			}
+			next, isRedirect := targets[title]
+			if !isRedirect {
+				final = title
+				break
+			}
+			state[title] = visiting
+			chain = append(chain, title)
+			title = next
+		}
+
+		for _, t := range chain {
+			if ok {
+				r.canonical[t] = final
+				delete(state, t)
+			} else {
+				state[t] = unresolvable
+				loops = append(loops, t)
+			}
+		}
+	}
+
+	slices.Sort(loops)
+	return r, loops
+}
+
+// Canonical returns the page a title redirects to, or the title itself if
+// it is not a redirect.
+func (r *Redirects) Canonical(title string) string {
+	if r == nil {
+		return title
+	}
+	if c, ok := r.canonical[title]; ok {
+		return c
+	}
+	return title
+}
+
+// resolve returns the page a title leads to, as Canonical does. A title
+// that is neither a redirect nor a page, by isPage, is tried again in
+// normalized form, so "albert_einstein" finds "Albert einstein" and any
+// redirect of that name.
+func (r *Redirects) resolve(title string, isPage func(string) bool) string {
+	if c := r.Canonical(title); c != title || isPage(title) {
+		return c
+	}
+	if normalized := wikititle.Normalize(title); normalized != title {
+		if c := r.Canonical(normalized); c != normalized || isPage(normalized) {
+			return c
+		}
+	}
+	return title
+}
+
+// Len returns the number of resolved redirect titles.
+func (r *Redirects) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.canonical)
+}
+
+// All yields each redirect title with the page it leads to.
+func (r *Redirects) All() iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		if r == nil {
+			return
+		}
+		for from, to := range r.canonical {
+			if !yield(from, to) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestResolveRedirects(t *testing.T) {
+	r, loops := ResolveRedirects(map[string]string{
+		"USA":    "U.S.",
+		"U.S.":   "United States",
+		"UK":     "United Kingdom",
+		"Loop A": "Loop B",
+		"Loop B": "Loop A",
+		"Into":   "Loop A",
+		"Self":   "Self",
+	})
+
+	tests := map[string]string{
+		"USA":           "United States",
+		"U.S.":          "United States",
+		"UK":            "United Kingdom",
+		"United States": "United States",
+		"Loop A":        "Loop A",
+		"Into":          "Into",
+		"Unknown":       "Unknown",
+	}
+	for title, want := range tests {
+		if got := r.Canonical(title); got != want {
+			t.Errorf("Canonical(%q) = %q, want %q", title, got, want)
+		}
+	}
+
+	if want := []string{"Into", "Loop A", "Loop B", "Self"}; !slices.Equal(loops, want) {
+		t.Errorf("loops = %v, want %v", loops, want)
+	}
+	if r.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", r.Len())
+	}
+}
+
+func TestRedirectsNil(t *testing.T) {
+	var r *Redirects
+	if got := r.Canonical("USA"); got != "USA" || r.Len() != 0 {
+		t.Errorf("nil Redirects resolved %q to %q", "USA", got)
+	}
+}
+
+func TestCanonicalNormalizes(t *testing.T) {
+	c := buildCSR([][2]string{{"Albert Einstein", "Physics"}, {"iPod", "Physics"}})
+	c.redirects, _ = ResolveRedirects(map[string]string{"Einstein": "Albert Einstein"})
+	g := pointerGraph(c)
+	g.SetRedirects(c.redirects)
+
+	tests := map[string]string{
+		"Albert Einstein": "Albert Einstein",
+		"albert Einstein": "Albert Einstein",
+		"Albert_Einstein": "Albert Einstein",
+		"einstein":        "Albert Einstein",
+		"iPod":            "iPod", // stored titles are found as given
+		"albert einstein": "albert einstein",
+		"Unknown_page":    "Unknown_page",
+	}
+	for _, v := range []View{c, g, NewOverlay(c)} {
+		for title, want := range tests {
+			if got := v.Canonical(title); got != want {
+				t.Errorf("%T.Canonical(%q) = %q, want %q", v, title, got, want)
+			}
+		}
+	}
+	if n := g.GetNode("albert_Einstein"); n == nil || n.Title != "Albert Einstein" {
+		t.Errorf("GetNode(albert_Einstein) = %v, want Albert Einstein", n)
+	}
+}
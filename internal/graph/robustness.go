@@ -0,0 +1,587 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// The robustness measures below take links as undirected: two pages are
+// neighbours if either links to the other, and self-links are ignored.
+
+// Cores is the k-core decomposition of a graph. The k-core is the largest
+// set of pages in which every page has at least k neighbours within the
+// set; a page's core number is the largest k whose k-core contains it.
+// Hubs that only link to the periphery have a low core number even with
+// many neighbours.
+type Cores struct {
+	core   []int32
+	degree []int32
+	sizes  []int // sizes[k] is the number of pages in the k-core
+
+	a Adjacency
+}
+
+// KCores computes the core number of every page with the Batagelj-Zaversnik
+// bucket algorithm, in time linear in the number of links. Returns
+// ctx.Err() if ctx is done first.
+func KCores(ctx context.Context, a Adjacency) (*Cores, error) {
+	nb := undirectedGraph(a, 1).sortedNeighbors()
+	core, err := nb.coreNumbers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Cores{core: core, degree: make([]int32, len(core)), a: a}
+	for v, k := range core {
+		c.degree[v] = int32(nb.degree(uint32(v)))
+		for len(c.sizes) <= int(k) {
+			c.sizes = append(c.sizes, 0)
+		}
+		c.sizes[k]++
+	}
+	// Every page in the (k+1)-core is in the k-core too.
+	for k := len(c.sizes) - 2; k >= 0; k-- {
+		c.sizes[k] += c.sizes[k+1]
+	}
+	return c, nil
+}
+
+// coreNumbers peels pages off in order of their remaining degree. vert
+// holds the pages sorted by remaining degree, bin[d] the position in vert
+// of the first page of degree d and pos the position of each page.
+func (nb *neighborLists) coreNumbers(ctx context.Context) ([]int32, error) {
+	n := len(nb.offs) - 1
+	deg := make([]int32, n)
+	maxDeg := 0
+	for v := range n {
+		deg[v] = int32(nb.degree(uint32(v)))
+		maxDeg = max(maxDeg, int(deg[v]))
+	}
+
+	bin := make([]int, maxDeg+2)
+	for _, d := range deg {
+		bin[d+1]++
+	}
+	for d := 1; d < len(bin); d++ {
+		bin[d] += bin[d-1]
+	}
+	vert := make([]uint32, n)
+	pos := make([]int, n)
+	next := slices.Clone(bin)
+	for v, d := range deg {
+		pos[v] = next[d]
+		vert[pos[v]] = uint32(v)
+		next[d]++
+	}
+
+	for i, v := range vert {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		for _, u := range nb.neighbors(v) {
+			du := deg[u]
+			if du <= deg[v] {
+				continue
+			}
+			// Swap u to the front of its bin and shrink the bin past it,
+			// which moves u into the bin one degree lower.
+			pu, pw := pos[u], bin[du]
+			w := vert[pw]
+			vert[pu], vert[pw] = w, u
+			pos[u], pos[w] = pw, pu
+			bin[du]++
+			deg[u]--
+		}
+	}
+	return deg, nil
+}
+
+// Core returns the core number of a page.
+func (c *Cores) Core(title string) (int, bool) {
+	v, ok := c.a.Lookup(title)
+	if !ok {
+		return 0, false
+	}
+	return int(c.core[v]), true
+}
+
+// Degeneracy returns the largest core number of any page, 0 for a graph
+// without links.
+func (c *Cores) Degeneracy() int {
+	return max(len(c.sizes)-1, 0)
+}
+
+// Size returns the number of pages in the k-core.
+func (c *Cores) Size(k int) int {
+	if k < 0 {
+		k = 0
+	}
+	if k >= len(c.sizes) {
+		return 0
+	}
+	return c.sizes[k]
+}
+
+// Innermost returns up to n pages of the innermost core, most neighbours
+// first. The Score of each is its number of neighbours.
+func (c *Cores) Innermost(n int) []RankedPage {
+	k := int32(c.Degeneracy())
+	var pages []uint32
+	for v, core := range c.core {
+		if core == k {
+			pages = append(pages, uint32(v))
+		}
+	}
+	slices.SortFunc(pages, func(u, v uint32) int {
+		if d := cmp.Compare(c.degree[v], c.degree[u]); d != 0 {
+			return d
+		}
+		return cmp.Compare(u, v)
+	})
+
+	out := make([]RankedPage, 0, min(n, len(pages)))
+	for _, v := range pages[:min(n, len(pages))] {
+		out = append(out, RankedPage{Title: c.a.Title(v), Score: float64(c.degree[v])})
+	}
+	return out
+}
+
+// Cuts lists the single points of failure of a graph: articulation points,
+// pages whose removal splits their connected component, and bridges, links
+// whose removal does.
+type Cuts struct {
+	ArticulationPoints int
+	Bridges            int
+
+	// TopArticulationPoints and TopBridges are the cuts that separate the
+	// most pages, most first.
+	TopArticulationPoints []CutPage
+	TopBridges            []CutLink
+}
+
+// CutPage is an articulation point. Separated is the number of pages cut
+// off from the largest part of the component left once the page is gone.
+type CutPage struct {
+	Title     string
+	Separated int
+}
+
+// CutLink is a bridge between two pages, taken as undirected. Removing it
+// cuts To, and the Separated pages on its side, off from From.
+type CutLink struct {
+	From      string
+	To        string
+	Separated int
+}
+
+// FindCuts finds every articulation point and bridge of a with an
+// iterative depth-first search (Hopcroft-Tarjan), so long chains of pages
+// cannot overflow the stack, and reports the top that separate the most
+// pages. Returns ctx.Err() if ctx is done first.
+func FindCuts(ctx context.Context, a Adjacency, top int) (*Cuts, error) {
+	nb := undirectedGraph(a, 1).sortedNeighbors()
+	n := len(nb.offs) - 1
+
+	// disc is the discovery time of each page, from 1 so that 0 marks
+	// pages not yet visited, and low the earliest discovery time reachable
+	// from its DFS subtree through one back edge. size is the number of
+	// pages in the subtree; cutSum and cutMax total and bound the subtrees
+	// of the children that only reach the rest of the component through
+	// the page.
+	disc := make([]uint32, n)
+	low := make([]uint32, n)
+	size := make([]uint32, n)
+	cutSum := make([]uint32, n)
+	cutMax := make([]uint32, n)
+
+	type frame struct {
+		v, parent uint32
+		next      int // position in neighbors(v) of the next one to visit
+	}
+	type bridge struct{ parent, child uint32 }
+	var (
+		calls   []frame
+		visited []uint32 // pages of the current component
+		bridges []bridge // bridges of the current component
+		points  []cutPoint
+		links   []cutBridge
+		counter uint32
+	)
+
+	for root := range uint32(n) {
+		if disc[root] != 0 {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		visited, bridges = visited[:0], bridges[:0]
+		counter++
+		disc[root], low[root], size[root] = counter, counter, 1
+		visited = append(visited, root)
+		calls = append(calls, frame{v: root, parent: root})
+		rootChildren := 0
+
+		for len(calls) > 0 {
+			f := &calls[len(calls)-1]
+			v := f.v
+
+			if neighbors := nb.neighbors(v); f.next < len(neighbors) {
+				w := neighbors[f.next]
+				f.next++
+				switch {
+				case w == f.parent:
+					// The tree edge back up; the graph has no parallel edges.
+				case disc[w] == 0:
+					counter++
+					disc[w], low[w], size[w] = counter, counter, 1
+					visited = append(visited, w)
+					calls = append(calls, frame{v: w, parent: v})
+					if v == root {
+						rootChildren++
+					}
+				default:
+					low[v] = min(low[v], disc[w])
+				}
+				continue
+			}
+
+			p := f.parent
+			calls = calls[:len(calls)-1]
+			if v == root {
+				continue
+			}
+			low[p] = min(low[p], low[v])
+			size[p] += size[v]
+			if low[v] >= disc[p] {
+				cutSum[p] += size[v]
+				cutMax[p] = max(cutMax[p], size[v])
+			}
+			if low[v] > disc[p] {
+				bridges = append(bridges, bridge{parent: p, child: v})
+			}
+		}
+
+		// Component sizes are only known once its search is done.
+		compSize := size[root]
+		for _, v := range visited {
+			if cutSum[v] == 0 || (v == root && rootChildren < 2) {
+				continue
+			}
+			rest := compSize - 1 - cutSum[v]
+			points = append(points, cutPoint{page: v, separated: compSize - 1 - max(rest, cutMax[v])})
+		}
+		for _, b := range bridges {
+			from, to := b.parent, b.child
+			below, above := size[b.child], compSize-size[b.child]
+			if below > above {
+				from, to = to, from
+			}
+			links = append(links, cutBridge{from: from, to: to, separated: min(below, above)})
+		}
+	}
+
+	cuts := &Cuts{ArticulationPoints: len(points), Bridges: len(links)}
+
+	slices.SortFunc(points, func(p, q cutPoint) int {
+		if c := cmp.Compare(q.separated, p.separated); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Title(p.page), a.Title(q.page))
+	})
+	for _, p := range points[:min(top, len(points))] {
+		cuts.TopArticulationPoints = append(cuts.TopArticulationPoints, CutPage{
+			Title:     a.Title(p.page),
+			Separated: int(p.separated),
+		})
+	}
+
+	slices.SortFunc(links, func(p, q cutBridge) int {
+		if c := cmp.Compare(q.separated, p.separated); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Title(p.from), a.Title(q.from)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Title(p.to), a.Title(q.to))
+	})
+	for _, l := range links[:min(top, len(links))] {
+		cuts.TopBridges = append(cuts.TopBridges, CutLink{
+			From:      a.Title(l.from),
+			To:        a.Title(l.to),
+			Separated: int(l.separated),
+		})
+	}
+	return cuts, nil
+}
+
+type cutPoint struct {
+	page      uint32
+	separated uint32
+}
+
+type cutBridge struct {
+	from, to  uint32
+	separated uint32
+}
+
+// HubOrder is the order in which SimulateHubRemoval removes pages.
+type HubOrder string
+
+const (
+	// ByDegree removes the pages with the most neighbours first.
+	ByDegree HubOrder = "degree"
+	// ByInLinks removes the most linked-to pages first.
+	ByInLinks HubOrder = "in_links"
+	// ByCore removes the pages with the highest core number first, most
+	// neighbours first within a core.
+	ByCore HubOrder = "core"
+)
+
+// ParseHubOrder parses a hub order name.
+func ParseHubOrder(s string) (HubOrder, error) {
+	switch o := HubOrder(s); o {
+	case ByDegree, ByInLinks, ByCore:
+		return o, nil
+	default:
+		return "", fmt.Errorf("unknown hub order %q (want degree, in_links or core)", s)
+	}
+}
+
+// RemovalOptions configures SimulateHubRemoval. Zero fields take the
+// defaults.
+type RemovalOptions struct {
+	// Remove is the number of hubs removed. Default 100.
+	Remove int
+
+	// Steps is the number of times the graph is measured along the way,
+	// after removing evenly spaced numbers of hubs. The intact graph is
+	// always measured first. Default 10.
+	Steps int
+
+	// Order picks the hubs. Default ByDegree.
+	Order HubOrder
+
+	// PathSamples is the number of source pages a BFS is run from at each
+	// step to estimate the average path length. The sources are never
+	// hubs, so every step samples the same pages. Default 100.
+	PathSamples int
+
+	// Seed drives the choice of sources.
+	Seed uint64
+
+	// Workers is the number of goroutines used. Defaults to GOMAXPROCS.
+	Workers int
+}
+
+func (o RemovalOptions) withDefaults() RemovalOptions {
+	if o.Remove == 0 {
+		o.Remove = 100
+	}
+	if o.Steps == 0 {
+		o.Steps = 10
+	}
+	if o.Order == "" {
+		o.Order = ByDegree
+	}
+	if o.PathSamples == 0 {
+		o.PathSamples = 100
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+	return o
+}
+
+// RemovalReport describes how a graph falls apart as its hubs are removed.
+type RemovalReport struct {
+	Order HubOrder
+
+	// Hubs are the pages removed, in order. Score is the measure they were
+	// picked by: neighbours, in-links or core number.
+	Hubs []RankedPage
+
+	// Steps measures the graph before any removal and after each batch.
+	Steps []RemovalStep
+
+	PathSamples int
+}
+
+// RemovalStep measures the graph left after removing the first Removed
+// hubs.
+//
+// Components counts the connected components of the remaining pages, with
+// links taken as undirected, and GiantComponent is the size of the
+// largest; GiantFraction is its share of every page of the intact graph.
+// AveragePathLength and Reachability follow links in their direction, as
+// Statistics does: the mean length of the shortest paths from the sampled
+// sources, and the fraction of the other remaining pages they reach.
+type RemovalStep struct {
+	Removed           int
+	Components        int
+	GiantComponent    int
+	GiantFraction     float64
+	AveragePathLength float64
+	Reachability      float64
+}
+
+// SimulateHubRemoval removes the top hubs of a one batch at a time and
+// measures how the giant component shrinks and paths lengthen. The graph
+// itself is left unchanged. Returns ctx.Err() if ctx is done first.
+func SimulateHubRemoval(ctx context.Context, a Adjacency, opts RemovalOptions) (*RemovalReport, error) {
+	opts = opts.withDefaults()
+	if _, err := ParseHubOrder(string(opts.Order)); err != nil {
+		return nil, err
+	}
+	n := a.NodeCount()
+	nb := undirectedGraph(a, 1).sortedNeighbors()
+
+	hubs, scores, err := rankHubs(ctx, a, nb, opts.Order, min(opts.Remove, n))
+	if err != nil {
+		return nil, err
+	}
+	report := &RemovalReport{Order: opts.Order}
+	for i, v := range hubs {
+		report.Hubs = append(report.Hubs, RankedPage{Title: a.Title(v), Score: scores[i]})
+	}
+
+	removed := make([]bool, n)
+	for _, v := range hubs {
+		removed[v] = true
+	}
+	var candidates []uint32
+	for v := range uint32(n) {
+		if !removed[v] {
+			candidates = append(candidates, v)
+		}
+	}
+	sources := candidates
+	if opts.PathSamples < len(candidates) {
+		sources = make([]uint32, opts.PathSamples)
+		for i, j := range samplePivots(len(candidates), opts.PathSamples, opts.Seed) {
+			sources[i] = candidates[j]
+		}
+	}
+	report.PathSamples = len(sources)
+
+	clear(removed)
+	prev := -1
+	for step := range opts.Steps + 1 {
+		count := step * len(hubs) / opts.Steps
+		if count == prev {
+			continue
+		}
+		for _, v := range hubs[max(prev, 0):count] {
+			removed[v] = true
+		}
+		prev = count
+
+		s, err := measureRemoval(ctx, a, nb, removed, sources, opts.Workers)
+		if err != nil {
+			return nil, err
+		}
+		s.Removed = count
+		if n > 0 {
+			s.GiantFraction = float64(s.GiantComponent) / float64(n)
+		}
+		report.Steps = append(report.Steps, s)
+	}
+	return report, nil
+}
+
+// rankHubs returns the top pages by order with their scores.
+func rankHubs(ctx context.Context, a Adjacency, nb *neighborLists, order HubOrder, top int) ([]uint32, []float64, error) {
+	n := a.NodeCount()
+	primary := make([]int32, n)
+	switch order {
+	case ByDegree:
+		for v := range n {
+			primary[v] = int32(nb.degree(uint32(v)))
+		}
+	case ByInLinks:
+		for v := range n {
+			primary[v] = int32(len(a.In(uint32(v))))
+		}
+	case ByCore:
+		core, err := nb.coreNumbers(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		primary = core
+	}
+
+	pages := make([]uint32, n)
+	for v := range pages {
+		pages[v] = uint32(v)
+	}
+	slices.SortFunc(pages, func(u, v uint32) int {
+		if c := cmp.Compare(primary[v], primary[u]); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(nb.degree(v), nb.degree(u)); c != 0 {
+			return c
+		}
+		return cmp.Compare(u, v)
+	})
+
+	pages = pages[:top]
+	scores := make([]float64, top)
+	for i, v := range pages {
+		scores[i] = float64(primary[v])
+	}
+	return pages, scores, nil
+}
+
+// measureRemoval measures the graph left without the removed pages.
+func measureRemoval(ctx context.Context, a Adjacency, nb *neighborLists, removed []bool, sources []uint32, workers int) (RemovalStep, error) {
+	var s RemovalStep
+	n := len(removed)
+	remaining := 0
+
+	seen := make([]bool, n)
+	var queue []uint32
+	for root := range uint32(n) {
+		if removed[root] || seen[root] {
+			continue
+		}
+		if root%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return s, err
+			}
+		}
+
+		seen[root] = true
+		queue = append(queue[:0], root)
+		for i := 0; i < len(queue); i++ {
+			for _, w := range nb.neighbors(queue[i]) {
+				if !removed[w] && !seen[w] {
+					seen[w] = true
+					queue = append(queue, w)
+				}
+			}
+		}
+		s.Components++
+		s.GiantComponent = max(s.GiantComponent, len(queue))
+		remaining += len(queue)
+	}
+
+	if remaining < 2 || len(sources) == 0 {
+		return s, nil
+	}
+	_, pairs, sum, err := pathTotals(ctx, a, sources, removed, workers)
+	if err != nil {
+		return s, err
+	}
+	if pairs > 0 {
+		s.AveragePathLength = float64(sum) / float64(pairs)
+	}
+	s.Reachability = float64(pairs) / (float64(len(sources)) * float64(remaining-1))
+	return s, nil
+}
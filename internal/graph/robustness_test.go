@@ -0,0 +1,261 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestKCores(t *testing.T) {
+	// A 4-clique, E tied to two of it, F hanging off E and G linking only
+	// to itself.
+	c := buildCSR([][2]string{
+		{"A", "B"}, {"A", "C"}, {"A", "D"}, {"B", "C"}, {"B", "D"}, {"D", "C"}, {"C", "D"},
+		{"E", "A"}, {"B", "E"}, {"F", "E"}, {"G", "G"},
+	})
+	cores, err := KCores(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]int{"A": 3, "B": 3, "C": 3, "D": 3, "E": 2, "F": 1, "G": 0}
+	for title, k := range want {
+		if got, ok := cores.Core(title); !ok || got != k {
+			t.Errorf("Core(%s) = %d, %v, want %d", title, got, ok, k)
+		}
+	}
+	if _, ok := cores.Core("Z"); ok {
+		t.Error("Core(Z): want not found")
+	}
+	if cores.Degeneracy() != 3 {
+		t.Errorf("Degeneracy() = %d, want 3", cores.Degeneracy())
+	}
+	for k, size := range []int{7, 6, 5, 4, 0} {
+		if got := cores.Size(k); got != size {
+			t.Errorf("Size(%d) = %d, want %d", k, got, size)
+		}
+	}
+
+	// A and B have E as a fourth neighbour.
+	inner := cores.Innermost(3)
+	if len(inner) != 3 || inner[0] != (RankedPage{"A", 4}) || inner[1] != (RankedPage{"B", 4}) {
+		t.Errorf("Innermost(3) = %+v, want A and B first", inner)
+	}
+}
+
+func TestKCoresMatchesPeeling(t *testing.T) {
+	c := randomCSR(60, 150, 4)
+	cores, err := KCores(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nb := undirectedGraph(c, 1).sortedNeighbors()
+
+	// The k-core is what is left after repeatedly dropping pages with
+	// fewer than k neighbours left.
+	for k := range cores.Degeneracy() + 2 {
+		alive := make([]bool, c.NodeCount())
+		for v := range alive {
+			alive[v] = true
+		}
+		for changed := true; changed; {
+			changed = false
+			for v := range alive {
+				if !alive[v] {
+					continue
+				}
+				deg := 0
+				for _, w := range nb.neighbors(uint32(v)) {
+					if alive[w] {
+						deg++
+					}
+				}
+				if deg < k {
+					alive[v], changed = false, true
+				}
+			}
+		}
+		for v, in := range alive {
+			got, _ := cores.Core(c.Title(uint32(v)))
+			if in != (got >= k) {
+				t.Errorf("%s: core %d, in %d-core by peeling = %v", c.Title(uint32(v)), got, k, in)
+			}
+		}
+	}
+}
+
+func TestFindCuts(t *testing.T) {
+	// A chain A-B-C into a triangle C-D-E, with F hanging off E, and a
+	// separate pair X-Y.
+	c := buildCSR([][2]string{
+		{"A", "B"}, {"C", "B"}, {"C", "D"}, {"D", "E"}, {"E", "C"}, {"E", "F"}, {"F", "E"},
+		{"X", "Y"},
+	})
+	cuts, err := FindCuts(context.Background(), c, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cuts.ArticulationPoints != 3 || cuts.Bridges != 4 {
+		t.Errorf("ArticulationPoints, Bridges = %d, %d, want 3, 4", cuts.ArticulationPoints, cuts.Bridges)
+	}
+	wantPoints := []CutPage{{"C", 2}, {"B", 1}, {"E", 1}}
+	if !slices.Equal(cuts.TopArticulationPoints, wantPoints) {
+		t.Errorf("TopArticulationPoints = %+v, want %+v", cuts.TopArticulationPoints, wantPoints)
+	}
+	wantBridges := []CutLink{{"C", "B", 2}, {"B", "A", 1}, {"E", "F", 1}, {"X", "Y", 1}}
+	if !slices.Equal(cuts.TopBridges, wantBridges) {
+		t.Errorf("TopBridges = %+v, want %+v", cuts.TopBridges, wantBridges)
+	}
+
+	cuts, err = FindCuts(context.Background(), c, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cuts.TopArticulationPoints) != 1 || len(cuts.TopBridges) != 1 || cuts.Bridges != 4 {
+		t.Errorf("top 1: %+v", cuts)
+	}
+}
+
+func TestFindCutsMatchesBruteForce(t *testing.T) {
+	for seed := range uint64(20) {
+		c := randomCSR(30, 35, seed)
+		n := c.NodeCount()
+		nb := undirectedGraph(c, 1).sortedNeighbors()
+		cuts, err := FindCuts(context.Background(), c, n*n)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// pieces returns the sizes of the components of the pages reached
+		// from start, skipping page skip and the edge skipFrom-skipTo.
+		pieces := func(start []uint32, skip uint32, skipFrom, skipTo uint32) []int {
+			seen := make([]bool, n)
+			var sizes []int
+			for _, s := range start {
+				if s == skip || seen[s] {
+					continue
+				}
+				seen[s] = true
+				queue := []uint32{s}
+				for i := 0; i < len(queue); i++ {
+					v := queue[i]
+					for _, w := range nb.neighbors(v) {
+						if w == skip || seen[w] || (v == skipFrom && w == skipTo) || (v == skipTo && w == skipFrom) {
+							continue
+						}
+						seen[w] = true
+						queue = append(queue, w)
+					}
+				}
+				sizes = append(sizes, len(queue))
+			}
+			return sizes
+		}
+
+		var wantPoints []CutPage
+		for v := range uint32(n) {
+			parts := pieces(nb.neighbors(v), v, v, v)
+			if len(parts) > 1 {
+				total := 0
+				for _, p := range parts {
+					total += p
+				}
+				wantPoints = append(wantPoints, CutPage{c.Title(v), total - slices.Max(parts)})
+			}
+		}
+		gotPoints := slices.Clone(cuts.TopArticulationPoints)
+		slices.SortFunc(gotPoints, func(p, q CutPage) int { return strings.Compare(p.Title, q.Title) })
+		if !slices.Equal(gotPoints, wantPoints) {
+			t.Errorf("articulation points = %+v, want %+v", gotPoints, wantPoints)
+		}
+
+		wantBridges := 0
+		for u := range uint32(n) {
+			for _, v := range nb.neighbors(u) {
+				if u < v && len(pieces([]uint32{u, v}, noComponent, u, v)) > 1 {
+					wantBridges++
+				}
+			}
+		}
+		if cuts.Bridges != wantBridges || len(cuts.TopBridges) != wantBridges {
+			t.Errorf("Bridges = %d, want %d", cuts.Bridges, wantBridges)
+		}
+		for _, b := range cuts.TopBridges {
+			u, _ := c.Lookup(b.From)
+			v, _ := c.Lookup(b.To)
+			parts := pieces([]uint32{u, v}, noComponent, u, v)
+			if len(parts) != 2 || parts[1] != b.Separated || parts[0] < parts[1] {
+				t.Errorf("bridge %+v: pieces %v", b, parts)
+			}
+		}
+	}
+}
+
+func TestSimulateHubRemoval(t *testing.T) {
+	// A star around H, with L1 also linking to L2.
+	c := buildCSR([][2]string{
+		{"H", "L1"}, {"H", "L2"}, {"H", "L3"}, {"L4", "H"}, {"L1", "L2"},
+	})
+	r, err := SimulateHubRemoval(context.Background(), c, RemovalOptions{Remove: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(r.Hubs) != 1 || r.Hubs[0] != (RankedPage{"H", 4}) {
+		t.Fatalf("Hubs = %+v, want H with 4 neighbours", r.Hubs)
+	}
+	if r.Order != ByDegree || r.PathSamples != 4 {
+		t.Errorf("Order, PathSamples = %s, %d, want degree, 4", r.Order, r.PathSamples)
+	}
+	if len(r.Steps) != 2 {
+		t.Fatalf("Steps = %+v, want before and after", r.Steps)
+	}
+
+	// L4 reaches H at 1 and the other leaves at 2, L1 reaches L2; without
+	// H only L1 reaches anything.
+	before, after := r.Steps[0], r.Steps[1]
+	if before.Removed != 0 || before.Components != 1 || before.GiantComponent != 5 || !approx(before.GiantFraction, 1) {
+		t.Errorf("before = %+v", before)
+	}
+	if !approx(before.AveragePathLength, 8.0/5) || !approx(before.Reachability, 5.0/16) {
+		t.Errorf("before = %+v, want path length 8/5 and reachability 5/16", before)
+	}
+	if after.Removed != 1 || after.Components != 3 || after.GiantComponent != 2 || !approx(after.GiantFraction, 0.4) {
+		t.Errorf("after = %+v", after)
+	}
+	if !approx(after.AveragePathLength, 1) || !approx(after.Reachability, 1.0/12) {
+		t.Errorf("after = %+v, want path length 1 and reachability 1/12", after)
+	}
+
+	r, err = SimulateHubRemoval(context.Background(), c, RemovalOptions{Remove: 2, Order: ByInLinks})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// L2 is linked from H and L1; H and L1 by one page each, but H has
+	// more neighbours.
+	if len(r.Hubs) != 2 || r.Hubs[0].Title != "L2" || r.Hubs[1].Title != "H" || len(r.Steps) != 3 {
+		t.Errorf("in-links: Hubs = %+v, Steps = %+v", r.Hubs, r.Steps)
+	}
+
+	if _, err := SimulateHubRemoval(context.Background(), c, RemovalOptions{Order: "bogus"}); err == nil {
+		t.Error("unknown order: want error")
+	}
+}
+
+func TestRobustnessCanceled(t *testing.T) {
+	c := buildCSR([][2]string{{"A", "B"}, {"B", "C"}})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := KCores(ctx, c); err == nil {
+		t.Error("KCores: want error")
+	}
+	if _, err := FindCuts(ctx, c, 10); err == nil {
+		t.Error("FindCuts: want error")
+	}
+	if _, err := SimulateHubRemoval(ctx, c, RemovalOptions{}); err == nil {
+		t.Error("SimulateHubRemoval: want error")
+	}
+}
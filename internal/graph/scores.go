@@ -0,0 +1,78 @@
+package graph
+
+import "container/heap"
+
+// NodeScores holds a score for every page of the graph it was computed on.
+type NodeScores struct {
+	// Scores is indexed by node ID.
+	Scores []float64
+
+	a Adjacency
+}
+
+// RankedPage is a page and its score.
+type RankedPage struct {
+	Title string
+	Score float64
+}
+
+// Score returns the score of a page.
+func (s *NodeScores) Score(title string) (float64, bool) {
+	id, ok := s.a.Lookup(title)
+	if !ok {
+		return 0, false
+	}
+	return s.Scores[id], true
+}
+
+// Top returns the n highest-scoring pages in descending order. Ties are
+// broken by title.
+func (s *NodeScores) Top(n int) []RankedPage {
+	n = min(n, len(s.Scores))
+	if n <= 0 {
+		return nil
+	}
+
+	h := &rankHeap{scores: s.Scores, a: s.a}
+	for id := range s.Scores {
+		if h.Len() < n {
+			heap.Push(h, uint32(id))
+		} else if h.less(h.ids[0], uint32(id)) {
+			h.ids[0] = uint32(id)
+			heap.Fix(h, 0)
+		}
+	}
+
+	top := make([]RankedPage, h.Len())
+	for i := len(top) - 1; i >= 0; i-- {
+		id := heap.Pop(h).(uint32)
+		top[i] = RankedPage{Title: s.a.Title(id), Score: s.Scores[id]}
+	}
+	return top
+}
+
+// rankHeap is a min-heap of node IDs, ordered so the lowest-ranked page is on top.
+type rankHeap struct {
+	ids    []uint32
+	scores []float64
+	a      Adjacency
+}
+
+// less reports whether a ranks below b. Of a tie, the later title ranks
+// lower; IDs need not follow title order, as in an Overlay with added pages.
+func (h *rankHeap) less(a, b uint32) bool {
+	if h.scores[a] != h.scores[b] {
+		return h.scores[a] < h.scores[b]
+	}
+	return h.a.Title(a) > h.a.Title(b)
+}
+
+func (h *rankHeap) Len() int           { return len(h.ids) }
+func (h *rankHeap) Less(i, j int) bool { return h.less(h.ids[i], h.ids[j]) }
+func (h *rankHeap) Swap(i, j int)      { h.ids[i], h.ids[j] = h.ids[j], h.ids[i] }
+func (h *rankHeap) Push(x any)         { h.ids = append(h.ids, x.(uint32)) }
+func (h *rankHeap) Pop() any {
+	last := h.ids[len(h.ids)-1]
+	h.ids = h.ids[:len(h.ids)-1]
+	return last
+}
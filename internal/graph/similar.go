@@ -0,0 +1,215 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// SimilarityMethod selects how Similar scores a pair of pages from the
+// links they share.
+type SimilarityMethod string
+
+const (
+	// SimilarityJaccard divides the number of pages linked to or from both
+	// pages by the number linked to or from either.
+	SimilarityJaccard SimilarityMethod = "jaccard"
+	// SimilarityCosine divides the number of shared neighbours by the
+	// geometric mean of the two pages' neighbour counts.
+	SimilarityCosine SimilarityMethod = "cosine"
+	// SimilarityCoCitation counts the pages that link to both pages.
+	SimilarityCoCitation SimilarityMethod = "cocitation"
+	// SimilarityCoupling counts the pages both pages link to
+	// (bibliographic coupling).
+	SimilarityCoupling SimilarityMethod = "coupling"
+	// SimilarityAdamicAdar sums 1/log(degree) over the shared neighbours,
+	// so a rarely linked page in common counts for more than a hub.
+	SimilarityAdamicAdar SimilarityMethod = "adamic_adar"
+)
+
+// ParseSimilarityMethod validates a similarity method name. The empty
+// string selects SimilarityJaccard.
+func ParseSimilarityMethod(name string) (SimilarityMethod, error) {
+	switch SimilarityMethod(name) {
+	case "":
+		return SimilarityJaccard, nil
+	case SimilarityJaccard, SimilarityCosine, SimilarityCoCitation, SimilarityCoupling, SimilarityAdamicAdar:
+		return SimilarityMethod(name), nil
+	default:
+		return "", fmt.Errorf("unknown similarity method %q (want %q, %q, %q, %q or %q)", name,
+			SimilarityJaccard, SimilarityCosine, SimilarityCoCitation, SimilarityCoupling, SimilarityAdamicAdar)
+	}
+}
+
+// SimilarityOptions configures Similar. Zero fields take the defaults.
+type SimilarityOptions struct {
+	// Method selects the score. Default SimilarityJaccard.
+	Method SimilarityMethod
+
+	// Limit is the most pages returned. Default 10.
+	Limit int
+
+	// Threshold leaves out pages scoring below it. Jaccard and cosine
+	// scores lie in (0, 1]; co-citation and coupling scores are counts.
+	Threshold float64
+}
+
+func (o SimilarityOptions) withDefaults() SimilarityOptions {
+	if o.Method == "" {
+		o.Method = SimilarityJaccard
+	}
+	if o.Limit <= 0 {
+		o.Limit = 10
+	}
+	return o
+}
+
+// linkSide selects which links of a page make up its neighbours.
+type linkSide int
+
+const (
+	sideIn linkSide = iota
+	sideOut
+	sideBoth
+)
+
+// side returns the neighbours a method compares: pages linking in for
+// co-citation, pages linked to for coupling and both otherwise.
+func (m SimilarityMethod) side() linkSide {
+	switch m {
+	case SimilarityCoCitation:
+		return sideIn
+	case SimilarityCoupling:
+		return sideOut
+	default:
+		return sideBoth
+	}
+}
+
+// Similar returns the pages that share the most links with title under
+// opts.Method, best first, ties broken by title. Only pages sharing at least
+// one neighbour are scored, found by walking two hops out and back through
+// the page's neighbours. Returns ErrPageNotFound if title is not in the
+// graph and ctx.Err() if ctx is done first.
+func Similar(ctx context.Context, a Adjacency, title string, opts SimilarityOptions) ([]RankedPage, error) {
+	opts = opts.withDefaults()
+	if _, err := ParseSimilarityMethod(string(opts.Method)); err != nil {
+		return nil, err
+	}
+
+	src, ok := a.Lookup(title)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrPageNotFound, title)
+	}
+
+	side := opts.Method.side()
+	var weight func(degree int) float64
+	if opts.Method == SimilarityAdamicAdar {
+		weight = adamicAdarWeight
+	}
+	own, shared, err := sharedNeighbors(ctx, a, src, side, weight)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf []uint32
+	similar := make([]RankedPage, 0, len(shared))
+	k := 0
+	for y, n := range shared {
+		if k%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		k++
+
+		score := n
+		switch opts.Method {
+		case SimilarityJaccard:
+			buf = neighbors(a, y, side, buf)
+			score = n / float64(len(own)+len(buf)-int(n))
+		case SimilarityCosine:
+			buf = neighbors(a, y, side, buf)
+			score = n / math.Sqrt(float64(len(own))*float64(len(buf)))
+		}
+		if score >= opts.Threshold {
+			similar = append(similar, RankedPage{Title: a.Title(y), Score: score})
+		}
+	}
+
+	slices.SortFunc(similar, func(x, y RankedPage) int {
+		if c := cmp.Compare(y.Score, x.Score); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Title, y.Title)
+	})
+	return similar[:min(opts.Limit, len(similar))], nil
+}
+
+// sharedNeighbors scores every page other than src that has a neighbour
+// in common with it, by walking two hops out and back through src's
+// neighbours on side. Each shared neighbour adds weight(its degree), or 1
+// when weight is nil. It also returns src's neighbours.
+func sharedNeighbors(ctx context.Context, a Adjacency, src uint32, side linkSide, weight func(degree int) float64) ([]uint32, map[uint32]float64, error) {
+	// y is a neighbour of z under side exactly when z is a neighbour of y
+	// under the reverse side, so a page shares z with src when it is
+	// reached from z the other way.
+	reverse := side
+	switch side {
+	case sideIn:
+		reverse = sideOut
+	case sideOut:
+		reverse = sideIn
+	}
+
+	own := neighbors(a, src, side, nil)
+	shared := make(map[uint32]float64)
+	var buf []uint32
+	for i, z := range own {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, nil, err
+			}
+		}
+		buf = neighbors(a, z, reverse, buf)
+		w := 1.0
+		if weight != nil {
+			// buf holds src and at least one other page whenever
+			// the loop below adds anything, so its length is at
+			// least 2.
+			w = weight(len(buf))
+		}
+		for _, y := range buf {
+			if y != src {
+				shared[y] += w
+			}
+		}
+	}
+	return own, shared, nil
+}
+
+// adamicAdarWeight is the weight of a shared neighbour with the given
+// degree under Adamic-Adar.
+func adamicAdarWeight(degree int) float64 {
+	return 1 / math.Log(float64(degree))
+}
+
+// neighbors writes the distinct pages linked with id on the given side,
+// other than id itself, into buf and returns it sorted.
+func neighbors(a Adjacency, id uint32, side linkSide, buf []uint32) []uint32 {
+	buf = buf[:0]
+	if side != sideIn {
+		buf = append(buf, a.Out(id)...)
+	}
+	if side != sideOut {
+		buf = append(buf, a.In(id)...)
+	}
+	slices.Sort(buf)
+	buf = slices.Compact(buf)
+	if i, found := slices.BinarySearch(buf, id); found {
+		buf = slices.Delete(buf, i, i+1)
+	}
+	return buf
+}
@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestSimilar(t *testing.T) {
+	// Cat and Dog are both linked from Pets and Zoo and both link to
+	// Mammal; Lion is linked from Zoo only.
+	c := buildCSR([][2]string{
+		{"Pets", "Cat"}, {"Pets", "Dog"},
+		{"Zoo", "Cat"}, {"Zoo", "Dog"}, {"Zoo", "Lion"},
+		{"Cat", "Mammal"}, {"Dog", "Mammal"}, {"Lion", "Mammal"}, {"Lion", "Africa"},
+	})
+	ctx := context.Background()
+
+	tests := []struct {
+		method SimilarityMethod
+		want   []RankedPage
+	}{
+		{SimilarityCoCitation, []RankedPage{{"Dog", 2}, {"Lion", 1}}},
+		{SimilarityCoupling, []RankedPage{{"Dog", 1}, {"Lion", 1}}},
+		{SimilarityJaccard, []RankedPage{{"Dog", 1}, {"Lion", 2.0 / 4}}},
+		{SimilarityCosine, []RankedPage{{"Dog", 1}, {"Lion", 2 / math.Sqrt(9)}}},
+		{SimilarityAdamicAdar, []RankedPage{{"Dog", 1/math.Log(2) + 1/math.Log(3) + 1/math.Log(3)}, {"Lion", 1/math.Log(3) + 1/math.Log(3)}}},
+	}
+	for _, tt := range tests {
+		got, err := Similar(ctx, c, "Cat", SimilarityOptions{Method: tt.method})
+		if err != nil {
+			t.Fatalf("Similar(%s) error: %v", tt.method, err)
+		}
+		if !equalRanked(got, tt.want) {
+			t.Errorf("Similar(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+
+	got, _ := Similar(ctx, c, "Cat", SimilarityOptions{Method: SimilarityCoCitation, Threshold: 2})
+	if !equalRanked(got, []RankedPage{{"Dog", 2}}) {
+		t.Errorf("with threshold 2 = %v, want only Dog", got)
+	}
+	got, _ = Similar(ctx, c, "Cat", SimilarityOptions{Limit: 1})
+	if len(got) != 1 || got[0].Title != "Dog" {
+		t.Errorf("with limit 1 = %v, want only Dog", got)
+	}
+
+	if _, err := Similar(ctx, c, "Missing", SimilarityOptions{}); !errors.Is(err, ErrPageNotFound) {
+		t.Errorf("missing page error = %v, want ErrPageNotFound", err)
+	}
+	if _, err := Similar(ctx, c, "Cat", SimilarityOptions{Method: "nope"}); err == nil {
+		t.Error("unknown method: expected an error")
+	}
+}
+
+func TestSimilarMatchesBruteForce(t *testing.T) {
+	c := randomCSR(60, 300, 4)
+	g := pointerGraph(c)
+	ctx := context.Background()
+
+	set := func(ids []uint32, self uint32) map[uint32]bool {
+		s := make(map[uint32]bool)
+		for _, id := range ids {
+			if id != self {
+				s[id] = true
+			}
+		}
+		return s
+	}
+	sides := func(u uint32, side linkSide) map[uint32]bool {
+		switch side {
+		case sideIn:
+			return set(c.In(u), u)
+		case sideOut:
+			return set(c.Out(u), u)
+		}
+		return set(append(append([]uint32{}, c.In(u)...), c.Out(u)...), u)
+	}
+
+	for _, method := range []SimilarityMethod{SimilarityJaccard, SimilarityCosine, SimilarityCoCitation, SimilarityCoupling, SimilarityAdamicAdar} {
+		side := method.side()
+		for u := range uint32(c.NodeCount()) {
+			nu := sides(u, side)
+			want := make(map[string]float64)
+			for v := range uint32(c.NodeCount()) {
+				if v == u {
+					continue
+				}
+				nv := sides(v, side)
+				shared, weight := 0, 0.0
+				for z := range nu {
+					if nv[z] {
+						shared++
+						weight += 1 / math.Log(float64(len(sides(z, sideBoth))))
+					}
+				}
+				if shared == 0 {
+					continue
+				}
+				switch method {
+				case SimilarityJaccard:
+					want[c.Title(v)] = float64(shared) / float64(len(nu)+len(nv)-shared)
+				case SimilarityCosine:
+					want[c.Title(v)] = float64(shared) / math.Sqrt(float64(len(nu)*len(nv)))
+				case SimilarityAdamicAdar:
+					want[c.Title(v)] = weight
+				default:
+					want[c.Title(v)] = float64(shared)
+				}
+			}
+
+			for _, a := range []Adjacency{c, g.Adjacency()} {
+				got, err := Similar(ctx, a, c.Title(u), SimilarityOptions{Method: method, Limit: c.NodeCount()})
+				if err != nil {
+					t.Fatalf("Similar error: %v", err)
+				}
+				if len(got) != len(want) {
+					t.Fatalf("%s from %s: %d pages, want %d", method, c.Title(u), len(got), len(want))
+				}
+				for i, p := range got {
+					if math.Abs(p.Score-want[p.Title]) > 1e-9 {
+						t.Errorf("%s(%s, %s) = %v, want %v", method, c.Title(u), p.Title, p.Score, want[p.Title])
+					}
+					if i > 0 && p.Score > got[i-1].Score {
+						t.Errorf("%s from %s: results not in descending order", method, c.Title(u))
+					}
+				}
+			}
+		}
+	}
+}
+
+func equalRanked(got, want []RankedPage) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i].Title != want[i].Title || math.Abs(got[i].Score-want[i].Score) > 1e-9 {
+			return false
+		}
+	}
+	return true
+}
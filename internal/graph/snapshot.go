@@ -0,0 +1,392 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+	"unsafe"
+)
+
+// Snapshot file layout (all integers little-endian):
+//
+//	header   64 bytes, see SnapshotHeader
+//	nameOffs uint64 × (nodes+1)
+//	outOffs  uint64 × (nodes+1)
+//	inOffs   uint64 × (nodes+1)
+//	outAdj   uint32 × edges
+//	inAdj    uint32 × edges
+//	names    namesSize bytes of concatenated titles
+//
+// The sections are exactly the CSR arrays, so a snapshot can be memory-mapped
+// and queried in place. The 8-byte arrays come first to keep them aligned.
+const (
+	snapshotMagic      = "WGSNAP\x00\x00"
+	snapshotHeaderSize = 64
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// ErrNotSnapshot is returned when a file does not start with the snapshot magic,
+// for example a cache written by an older gob-based version.
+var ErrNotSnapshot = errors.New("not a graph snapshot")
+
+// SnapshotHeader is the fixed-size header at the start of a snapshot file.
+type SnapshotHeader struct {
+	Version   uint32
+	Checksum  uint32 // CRC-32C of everything after the header
+	Timestamp time.Time
+	NodeCount uint64
+	EdgeCount uint64
+	NamesSize uint64
+}
+
+// payloadSize returns the number of bytes that follow the header.
+func (h *SnapshotHeader) payloadSize() uint64 {
+	return 3*8*(h.NodeCount+1) + 2*4*h.EdgeCount + h.NamesSize
+}
+
+func (h *SnapshotHeader) encode() []byte {
+	buf := make([]byte, snapshotHeaderSize)
+	copy(buf, snapshotMagic)
+	binary.LittleEndian.PutUint32(buf[8:], h.Version)
+	binary.LittleEndian.PutUint32(buf[12:], h.Checksum)
+	binary.LittleEndian.PutUint64(buf[16:], uint64(h.Timestamp.UnixNano()))
+	binary.LittleEndian.PutUint64(buf[24:], h.NodeCount)
+	binary.LittleEndian.PutUint64(buf[32:], h.EdgeCount)
+	binary.LittleEndian.PutUint64(buf[40:], h.NamesSize)
+	return buf
+}
+
+func decodeSnapshotHeader(buf []byte) (*SnapshotHeader, error) {
+	if len(buf) < snapshotHeaderSize || string(buf[:8]) != snapshotMagic {
+		return nil, ErrNotSnapshot
+	}
+	return &SnapshotHeader{
+		Version:   binary.LittleEndian.Uint32(buf[8:]),
+		Checksum:  binary.LittleEndian.Uint32(buf[12:]),
+		Timestamp: time.Unix(0, int64(binary.LittleEndian.Uint64(buf[16:]))),
+		NodeCount: binary.LittleEndian.Uint64(buf[24:]),
+		EdgeCount: binary.LittleEndian.Uint64(buf[32:]),
+		NamesSize: binary.LittleEndian.Uint64(buf[40:]),
+	}, nil
+}
+
+// WriteSnapshot writes c to path in snapshot format.
+// Uses atomic write (temp file + rename) to prevent corruption.
+func WriteSnapshot(path string, c *CSR) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("creating cache directory: %w", err)
+	}
+
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return fmt.Errorf("creating temp cache file: %w", err)
+	}
+
+	header := &SnapshotHeader{
+		Version:   CacheVersion,
+		Timestamp: time.Now(),
+		NodeCount: uint64(c.NodeCount()),
+		EdgeCount: uint64(c.EdgeCount()),
+		NamesSize: uint64(len(c.names)),
+	}
+
+	if err := writeSnapshotBody(f, header, c); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("syncing cache file: %w", err)
+	}
+
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("closing cache file: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("renaming cache file: %w", err)
+	}
+
+	return nil
+}
+
+// writeSnapshotBody streams the payload, then fills in the header with its checksum.
+func writeSnapshotBody(f *os.File, header *SnapshotHeader, c *CSR) error {
+	if _, err := f.Write(make([]byte, snapshotHeaderSize)); err != nil {
+		return fmt.Errorf("writing header placeholder: %w", err)
+	}
+
+	crc := crc32.New(crcTable)
+	w := bufio.NewWriterSize(io.MultiWriter(f, crc), 1<<20)
+
+	for _, offs := range [][]uint64{c.nameOffs, c.outOffs, c.inOffs} {
+		if err := writeUint64s(w, offs); err != nil {
+			return fmt.Errorf("writing offsets: %w", err)
+		}
+	}
+	for _, adj := range [][]uint32{c.outAdj, c.inAdj} {
+		if err := writeUint32s(w, adj); err != nil {
+			return fmt.Errorf("writing adjacency: %w", err)
+		}
+	}
+	if _, err := w.WriteString(c.names); err != nil {
+		return fmt.Errorf("writing string table: %w", err)
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("flushing snapshot: %w", err)
+	}
+
+	header.Checksum = crc.Sum32()
+	if _, err := f.WriteAt(header.encode(), 0); err != nil {
+		return fmt.Errorf("writing header: %w", err)
+	}
+	return nil
+}
+
+func writeUint64s(w io.Writer, values []uint64) error {
+	var buf [8 * 4096]byte
+	for len(values) > 0 {
+		n := min(len(values), 4096)
+		for i, v := range values[:n] {
+			binary.LittleEndian.PutUint64(buf[i*8:], v)
+		}
+		if _, err := w.Write(buf[:n*8]); err != nil {
+			return err
+		}
+		values = values[n:]
+	}
+	return nil
+}
+
+func writeUint32s(w io.Writer, values []uint32) error {
+	var buf [4 * 8192]byte
+	for len(values) > 0 {
+		n := min(len(values), 8192)
+		for i, v := range values[:n] {
+			binary.LittleEndian.PutUint32(buf[i*4:], v)
+		}
+		if _, err := w.Write(buf[:n*4]); err != nil {
+			return err
+		}
+		values = values[n:]
+	}
+	return nil
+}
+
+// ReadSnapshotHeader reads only the header of a snapshot file.
+func ReadSnapshotHeader(path string) (*SnapshotHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, snapshotHeaderSize)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) {
+			return nil, ErrNotSnapshot
+		}
+		return nil, fmt.Errorf("reading header: %w", err)
+	}
+	return decodeSnapshotHeader(buf)
+}
+
+// OpenSnapshot maps a snapshot file into memory and returns a CSR that reads
+// directly from the mapping. Nothing is decoded up front, so opening is fast
+// regardless of graph size, and the pages are shared with any other process
+// mapping the same file.
+//
+// The mapping stays in place until Close is called, which the caller must
+// defer until nothing reads from the CSR anymore.
+func OpenSnapshot(path string) (*CSR, *SnapshotHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("cache not found: %w", err)
+		}
+		return nil, nil, fmt.Errorf("opening cache file: %w", err)
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, nil, fmt.Errorf("stat cache file: %w", err)
+	}
+	if stat.Size() < snapshotHeaderSize {
+		return nil, nil, ErrNotSnapshot
+	}
+
+	data, mapped, err := mapFile(f, stat.Size())
+	if err != nil {
+		return nil, nil, fmt.Errorf("mapping cache file: %w", err)
+	}
+
+	header, err := decodeSnapshotHeader(data)
+	if err == nil {
+		err = validateSnapshot(header, uint64(len(data)))
+	}
+	var c *CSR
+	if err == nil {
+		c, err = csrFromSnapshot(header, data)
+	}
+	if err != nil {
+		if mapped {
+			unmapFile(data)
+		}
+		return nil, nil, err
+	}
+
+	if mapped {
+		c.mapping = data
+	}
+	c.snapshot = header
+	return c, header, nil
+}
+
+func validateSnapshot(h *SnapshotHeader, fileSize uint64) error {
+	if h.Version != CacheVersion {
+		return fmt.Errorf("cache version mismatch: got %d, want %d", h.Version, CacheVersion)
+	}
+	if h.NodeCount >= 1<<32 || h.EdgeCount >= 1<<40 {
+		return fmt.Errorf("corrupt snapshot header: %d nodes, %d edges", h.NodeCount, h.EdgeCount)
+	}
+	if want := snapshotHeaderSize + h.payloadSize(); fileSize != want {
+		return fmt.Errorf("corrupt snapshot: size %d, header implies %d", fileSize, want)
+	}
+	return nil
+}
+
+// VerifySnapshot recomputes the checksum of a snapshot file and compares it
+// with the header. This reads the whole file.
+func VerifySnapshot(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, snapshotHeaderSize)
+	if _, err := io.ReadFull(f, buf); err != nil {
+		return ErrNotSnapshot
+	}
+	header, err := decodeSnapshotHeader(buf)
+	if err != nil {
+		return err
+	}
+
+	crc := crc32.New(crcTable)
+	if _, err := io.Copy(crc, bufio.NewReaderSize(f, 1<<20)); err != nil {
+		return fmt.Errorf("reading snapshot: %w", err)
+	}
+	if got := crc.Sum32(); got != header.Checksum {
+		return fmt.Errorf("snapshot checksum mismatch: got %08x, want %08x", got, header.Checksum)
+	}
+	return nil
+}
+
+// fingerprint returns a CRC-32C of the titles and out-links of a in ID
+// order. Vectors indexed by node ID are only valid for a graph with the same
+// fingerprint: an Overlay with added pages and the CSR it compacts to hold
+// the same links under different IDs, so their fingerprints differ.
+func fingerprint(a Adjacency) uint32 {
+	var sum uint32
+	buf := make([]byte, 0, 1<<16)
+	for id := range uint32(a.NodeCount()) {
+		title, out := a.Title(id), a.Out(id)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(title)))
+		buf = append(buf, title...)
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(out)))
+		for _, v := range out {
+			buf = binary.LittleEndian.AppendUint32(buf, v)
+		}
+		if len(buf) >= 1<<15 {
+			sum = crc32.Update(sum, crcTable, buf)
+			buf = buf[:0]
+		}
+	}
+	return crc32.Update(sum, crcTable, buf)
+}
+
+// csrFromSnapshot slices the CSR arrays out of a validated snapshot image.
+// On little-endian hosts the arrays alias data; otherwise they are decoded.
+func csrFromSnapshot(h *SnapshotHeader, data []byte) (*CSR, error) {
+	n := int(h.NodeCount) + 1
+	e := int(h.EdgeCount)
+	off := snapshotHeaderSize
+
+	nextUint64s := func() []uint64 {
+		s := viewUint64s(data[off:off+n*8], n)
+		off += n * 8
+		return s
+	}
+	nextUint32s := func() []uint32 {
+		s := viewUint32s(data[off:off+e*4], e)
+		off += e * 4
+		return s
+	}
+
+	c := &CSR{}
+	c.nameOffs = nextUint64s()
+	c.outOffs = nextUint64s()
+	c.inOffs = nextUint64s()
+	c.outAdj = nextUint32s()
+	c.inAdj = nextUint32s()
+	if h.NamesSize > 0 {
+		c.names = unsafe.String(&data[off], int(h.NamesSize))
+	}
+
+	// Cheap structural checks so a truncated or corrupt file fails here
+	// rather than with an out-of-range panic during a query.
+	last := n - 1
+	if c.nameOffs[last] != h.NamesSize || c.outOffs[last] != h.EdgeCount || c.inOffs[last] != h.EdgeCount {
+		return nil, fmt.Errorf("corrupt snapshot: offset tables do not match header")
+	}
+	return c, nil
+}
+
+var littleEndian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+func viewUint64s(b []byte, n int) []uint64 {
+	if n == 0 {
+		return []uint64{}
+	}
+	if littleEndian {
+		return unsafe.Slice((*uint64)(unsafe.Pointer(&b[0])), n)
+	}
+	s := make([]uint64, n)
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint64(b[i*8:])
+	}
+	return s
+}
+
+func viewUint32s(b []byte, n int) []uint32 {
+	if n == 0 {
+		return []uint32{}
+	}
+	if littleEndian {
+		return unsafe.Slice((*uint32)(unsafe.Pointer(&b[0])), n)
+	}
+	s := make([]uint32, n)
+	for i := range s {
+		s[i] = binary.LittleEndian.Uint32(b[i*4:])
+	}
+	return s
+}
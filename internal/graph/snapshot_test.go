@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.cache")
+	orig := buildCSR([][2]string{{"A", "B"}, {"B", "C"}, {"C", "A"}, {"A", "Ω"}})
+
+	if err := orig.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	c, _, err := LoadCSRFromCache(path)
+	if err != nil {
+		t.Fatalf("LoadCSRFromCache failed: %v", err)
+	}
+	defer c.Close()
+
+	if c.NodeCount() != orig.NodeCount() || c.EdgeCount() != orig.EdgeCount() {
+		t.Fatalf("counts = %d/%d, want %d/%d",
+			c.NodeCount(), c.EdgeCount(), orig.NodeCount(), orig.EdgeCount())
+	}
+	for id := uint32(0); int(id) < c.NodeCount(); id++ {
+		if c.Title(id) != orig.Title(id) {
+			t.Errorf("Title(%d) = %q, want %q", id, c.Title(id), orig.Title(id))
+		}
+		if !slices.Equal(c.Out(id), orig.Out(id)) || !slices.Equal(c.In(id), orig.In(id)) {
+			t.Errorf("adjacency of %q differs", c.Title(id))
+		}
+	}
+	if r := c.FindPath("B", "Ω"); !r.Found || r.Hops != 3 {
+		t.Errorf("FindPath(B, Ω) = %+v", r)
+	}
+
+	if err := VerifySnapshot(path); err != nil {
+		t.Errorf("VerifySnapshot failed: %v", err)
+	}
+}
+
+func TestSnapshotPointerRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.cache")
+	g := New()
+	g.AddEdge("A", "B")
+	g.AddEdge("B", "C")
+	g.AddNode("Isolated")
+
+	if err := g.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, _, err := LoadFromCache(path)
+	if err != nil {
+		t.Fatalf("LoadFromCache failed: %v", err)
+	}
+	if loaded.NodeCount() != 4 || loaded.EdgeCount() != 2 {
+		t.Errorf("counts = %d/%d, want 4/2", loaded.NodeCount(), loaded.EdgeCount())
+	}
+	if got := loaded.InLinkTitles("C"); !equalSlices(got, []string{"B"}) {
+		t.Errorf("InLinkTitles(C) = %v", got)
+	}
+	if !loaded.HasNode("Isolated") {
+		t.Error("isolated node was lost")
+	}
+}
+
+func TestSnapshotEmptyGraph(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.cache")
+	if err := New().Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	c, _, err := LoadCSRFromCache(path)
+	if err != nil {
+		t.Fatalf("LoadCSRFromCache failed: %v", err)
+	}
+	if c.NodeCount() != 0 || c.EdgeCount() != 0 {
+		t.Errorf("counts = %d/%d, want 0/0", c.NodeCount(), c.EdgeCount())
+	}
+}
+
+func TestGetCacheInfo(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "graph.cache")
+	if err := buildCSR([][2]string{{"A", "B"}, {"B", "C"}}).Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	info, err := GetCacheInfo(path)
+	if err != nil {
+		t.Fatalf("GetCacheInfo failed: %v", err)
+	}
+	if !info.Valid || info.Version != CacheVersion {
+		t.Errorf("info = %+v, want valid version %d", info, CacheVersion)
+	}
+	if info.NodeCount != 3 || info.EdgeCount != 2 {
+		t.Errorf("counts = %d/%d, want 3/2", info.NodeCount, info.EdgeCount)
+	}
+	if stat, _ := os.Stat(path); info.FileSize != stat.Size() {
+		t.Errorf("FileSize = %d, want %d", info.FileSize, stat.Size())
+	}
+}
+
+func TestSnapshotRejectsBadFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	legacy := filepath.Join(dir, "legacy.cache")
+	os.WriteFile(legacy, []byte("gob-encoded data from an old version, long enough for a header"), 0644)
+	if _, _, err := LoadCSRFromCache(legacy); !errors.Is(err, ErrNotSnapshot) {
+		t.Errorf("expected ErrNotSnapshot, got %v", err)
+	}
+	if _, err := GetCacheInfo(legacy); !errors.Is(err, ErrNotSnapshot) {
+		t.Errorf("expected ErrNotSnapshot from GetCacheInfo, got %v", err)
+	}
+
+	path := filepath.Join(dir, "graph.cache")
+	if err := buildCSR([][2]string{{"A", "B"}}).Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+
+	// Truncated file fails the size check
+	truncated := filepath.Join(dir, "truncated.cache")
+	os.WriteFile(truncated, data[:len(data)-1], 0644)
+	if _, _, err := LoadCSRFromCache(truncated); err == nil {
+		t.Error("expected error for truncated snapshot")
+	}
+
+	// Flipped payload byte fails the checksum
+	data[len(data)-1] ^= 0xff
+	corrupt := filepath.Join(dir, "corrupt.cache")
+	os.WriteFile(corrupt, data, 0644)
+	if err := VerifySnapshot(corrupt); err == nil {
+		t.Error("expected checksum mismatch")
+	}
+}
@@ -0,0 +1,570 @@
+package graph
+
+import (
+	"context"
+	"math"
+	"math/rand/v2"
+	"runtime"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// StatisticsOptions configures ComputeStatistics. Zero fields take the defaults.
+type StatisticsOptions struct {
+	// PathSamples is the number of source pages a BFS is run from to
+	// estimate the diameter and average path length. Default 100.
+	PathSamples int
+
+	// ClusteringSamples is the number of random wedges the clustering
+	// coefficient is estimated from. Default 100000.
+	ClusteringSamples int
+
+	// ExactClustering counts every triangle instead of sampling wedges.
+	// This can take minutes on a large graph.
+	ExactClustering bool
+
+	// Top is the number of hubs and authorities to report. Default 10.
+	Top int
+
+	// Seed drives every sample; runs with the same seed on the same graph
+	// give the same report.
+	Seed uint64
+
+	// Workers is the number of goroutines used. Defaults to GOMAXPROCS.
+	Workers int
+}
+
+func (o StatisticsOptions) withDefaults() StatisticsOptions {
+	if o.PathSamples == 0 {
+		o.PathSamples = 100
+	}
+	if o.ClusteringSamples == 0 {
+		o.ClusteringSamples = 100000
+	}
+	if o.Top == 0 {
+		o.Top = 10
+	}
+	if o.Workers <= 0 {
+		o.Workers = runtime.GOMAXPROCS(0)
+	}
+	return o
+}
+
+// Statistics describes the shape of a graph.
+type Statistics struct {
+	Nodes int
+	Edges int
+
+	InDegree  DegreeDistribution
+	OutDegree DegreeDistribution
+
+	// Hubs and Authorities are the top pages by HITS hub and authority
+	// score: hubs link to many authorities, authorities are linked from
+	// many hubs.
+	Hubs        []RankedPage
+	Authorities []RankedPage
+
+	// Reciprocity is the fraction of links whose target links back.
+	// Self-links are ignored.
+	Reciprocity float64
+
+	// Clustering is the global clustering coefficient (transitivity) of
+	// the graph with links taken as undirected: the fraction of pairs of
+	// a page's neighbours that are linked themselves.
+	Clustering      float64
+	ClusteringExact bool
+
+	// Dangling counts pages without out-links, mostly link targets that
+	// were never fetched. Orphans counts pages without in-links.
+	Dangling int
+	Orphans  int
+
+	// Diameter is the longest shortest path found from the sampled
+	// sources, a lower bound on the true diameter. AveragePathLength is
+	// the mean length of the shortest paths from the sampled sources, and
+	// Reachability the fraction of other pages they reach.
+	Diameter          int
+	AveragePathLength float64
+	Reachability      float64
+	PathSamples       int
+}
+
+// DegreeDistribution summarizes the in- or out-degrees of every page.
+type DegreeDistribution struct {
+	Mean        float64
+	Max         int
+	Percentiles []DegreePercentile
+	Histogram   []DegreeBucket
+}
+
+// DegreePercentile is the degree at or below which Percentile percent of
+// pages fall.
+type DegreePercentile struct {
+	Percentile float64
+	Degree     int
+}
+
+// DegreeBucket counts the pages with a degree in [Min, Max]. Buckets
+// double in width: 0, 1, 2-3, 4-7 and so on.
+type DegreeBucket struct {
+	Min   int
+	Max   int
+	Count int
+}
+
+// reportedPercentiles are the percentiles of each degree distribution.
+var reportedPercentiles = []float64{50, 90, 99, 99.9}
+
+// ComputeStatistics reports the degree distributions, hubs and authorities,
+// reciprocity, clustering and path lengths of a.
+//
+// Returns ctx.Err() if ctx is done first.
+func ComputeStatistics(ctx context.Context, a Adjacency, opts StatisticsOptions) (*Statistics, error) {
+	opts = opts.withDefaults()
+	n := a.NodeCount()
+
+	s := &Statistics{
+		Nodes:           n,
+		Edges:           a.EdgeCount(),
+		InDegree:        degreeDistribution(n, func(v uint32) int { return len(a.In(v)) }),
+		OutDegree:       degreeDistribution(n, func(v uint32) int { return len(a.Out(v)) }),
+		ClusteringExact: opts.ExactClustering,
+	}
+	for v := range n {
+		if len(a.Out(uint32(v))) == 0 {
+			s.Dangling++
+		}
+		if len(a.In(uint32(v))) == 0 {
+			s.Orphans++
+		}
+	}
+
+	hubs, authorities, err := hits(ctx, a, opts.Workers)
+	if err != nil {
+		return nil, err
+	}
+	s.Hubs = hubs.Top(opts.Top)
+	s.Authorities = authorities.Top(opts.Top)
+
+	// Reciprocal pairs weigh 2 in the undirected graph, one-way links 1.
+	ug := undirectedGraph(a, 2)
+	if ug.total > 0 {
+		reciprocated := 0
+		for _, w := range ug.weights {
+			if w == 2 {
+				reciprocated++
+			}
+		}
+		s.Reciprocity = float64(reciprocated) / (ug.total / 2)
+	}
+
+	nb := ug.sortedNeighbors()
+	if opts.ExactClustering {
+		s.Clustering, err = nb.transitivity(ctx, opts.Workers)
+	} else {
+		s.Clustering, err = nb.sampleTransitivity(ctx, opts.ClusteringSamples, opts.Seed)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.samplePaths(ctx, a, opts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// degreeDistribution summarizes the degrees of n pages.
+func degreeDistribution(n int, degree func(uint32) int) DegreeDistribution {
+	var d DegreeDistribution
+	if n == 0 {
+		return d
+	}
+
+	degrees := make([]int, n)
+	total := 0
+	for v := range degrees {
+		degrees[v] = degree(uint32(v))
+		total += degrees[v]
+		d.Max = max(d.Max, degrees[v])
+	}
+	d.Mean = float64(total) / float64(n)
+
+	for _, deg := range degrees {
+		b := bucketOf(deg)
+		for len(d.Histogram) <= b {
+			lo, hi := bucketRange(len(d.Histogram))
+			d.Histogram = append(d.Histogram, DegreeBucket{Min: lo, Max: hi})
+		}
+		d.Histogram[b].Count++
+	}
+
+	slices.Sort(degrees)
+	for _, p := range reportedPercentiles {
+		// Nearest rank: the smallest degree covering p percent of pages.
+		rank := int(math.Ceil(p / 100 * float64(n)))
+		d.Percentiles = append(d.Percentiles, DegreePercentile{
+			Percentile: p,
+			Degree:     degrees[max(rank, 1)-1],
+		})
+	}
+	return d
+}
+
+// bucketOf returns the histogram bucket of a degree.
+func bucketOf(deg int) int {
+	b := 0
+	for deg > 0 {
+		deg >>= 1
+		b++
+	}
+	return b
+}
+
+// bucketRange returns the degrees covered by histogram bucket b.
+func bucketRange(b int) (lo, hi int) {
+	if b == 0 {
+		return 0, 0
+	}
+	return 1 << (b - 1), 1<<b - 1
+}
+
+// hitsIterations bounds the HITS power iteration.
+const hitsIterations = 50
+
+// hits computes HITS hub and authority scores, each normalized to unit
+// length: a page's authority is the sum of the hub scores of the pages
+// linking to it, and its hub score the sum of the authorities it links to.
+func hits(ctx context.Context, a Adjacency, workers int) (hubs, authorities *NodeScores, err error) {
+	n := a.NodeCount()
+	hub := make([]float64, n)
+	auth := make([]float64, n)
+	for v := range hub {
+		hub[v] = 1
+	}
+	workers = min(workers, max(1, n/4096))
+
+	for range hitsIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, nil, err
+		}
+
+		parallelRange(n, workers, func(v int) {
+			s := 0.0
+			for _, u := range a.In(uint32(v)) {
+				s += hub[u]
+			}
+			auth[v] = s
+		})
+		normalize(auth)
+
+		prev := slices.Clone(hub)
+		parallelRange(n, workers, func(u int) {
+			s := 0.0
+			for _, v := range a.Out(uint32(u)) {
+				s += auth[v]
+			}
+			hub[u] = s
+		})
+		normalize(hub)
+
+		delta := 0.0
+		for v := range hub {
+			delta += math.Abs(hub[v] - prev[v])
+		}
+		if delta < 1e-9 {
+			break
+		}
+	}
+
+	return &NodeScores{Scores: hub, a: a}, &NodeScores{Scores: auth, a: a}, nil
+}
+
+// normalize scales x to unit length, leaving a zero vector alone.
+func normalize(x []float64) {
+	sum := 0.0
+	for _, v := range x {
+		sum += v * v
+	}
+	if sum == 0 {
+		return
+	}
+	norm := math.Sqrt(sum)
+	for i := range x {
+		x[i] /= norm
+	}
+}
+
+// parallelRange calls fn for every i in [0, n), splitting the range into
+// one contiguous slice per worker.
+func parallelRange(n, workers int, fn func(i int)) {
+	var wg sync.WaitGroup
+	for w := range workers {
+		lo, hi := w*n/workers, (w+1)*n/workers
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := lo; i < hi; i++ {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// neighborLists is a simple undirected graph with every neighbour list
+// sorted by node ID.
+type neighborLists struct {
+	offs []int
+	adj  []uint32
+}
+
+// sortedNeighbors drops the weights of g and sorts each neighbour list.
+func (g *weightedGraph) sortedNeighbors() *neighborLists {
+	nb := &neighborLists{offs: g.offs, adj: slices.Clone(g.adj)}
+	for v := range len(g.offs) - 1 {
+		slices.Sort(nb.neighbors(uint32(v)))
+	}
+	return nb
+}
+
+func (nb *neighborLists) neighbors(v uint32) []uint32 {
+	return nb.adj[nb.offs[v]:nb.offs[v+1]]
+}
+
+func (nb *neighborLists) degree(v uint32) int {
+	return nb.offs[v+1] - nb.offs[v]
+}
+
+func (nb *neighborLists) linked(u, v uint32) bool {
+	_, found := slices.BinarySearch(nb.neighbors(u), v)
+	return found
+}
+
+// wedges returns the number of paths of length two centred on v.
+func (nb *neighborLists) wedges(v uint32) float64 {
+	d := float64(nb.degree(v))
+	return d * (d - 1) / 2
+}
+
+// transitivity counts every triangle and returns three times their number
+// over the number of wedges. Each triangle is counted once, from its
+// lowest-ranked page, by orienting every edge towards the page of higher
+// degree.
+func (nb *neighborLists) transitivity(ctx context.Context, workers int) (float64, error) {
+	n := len(nb.offs) - 1
+
+	higher := func(u, v uint32) bool {
+		du, dv := nb.degree(u), nb.degree(v)
+		return dv > du || (dv == du && v > u)
+	}
+	fwdOffs := make([]int, n+1)
+	var fwd []uint32
+	totalWedges := 0.0
+	for u := range n {
+		for _, v := range nb.neighbors(uint32(u)) {
+			if higher(uint32(u), v) {
+				fwd = append(fwd, v)
+			}
+		}
+		fwdOffs[u+1] = len(fwd)
+		totalWedges += nb.wedges(uint32(u))
+	}
+	if totalWedges == 0 {
+		return 0, nil
+	}
+	forward := func(v uint32) []uint32 { return fwd[fwdOffs[v]:fwdOffs[v+1]] }
+
+	var (
+		next      atomic.Int64
+		triangles atomic.Int64
+		wg        sync.WaitGroup
+	)
+	for range min(workers, max(1, n)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			count := int64(0)
+			for ctx.Err() == nil {
+				u := next.Add(1) - 1
+				if u >= int64(n) {
+					break
+				}
+				fu := forward(uint32(u))
+				for _, v := range fu {
+					count += int64(intersectionSize(fu, forward(v)))
+				}
+			}
+			triangles.Add(count)
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return 3 * float64(triangles.Load()) / totalWedges, nil
+}
+
+// intersectionSize counts the IDs two sorted lists have in common.
+func intersectionSize(x, y []uint32) int {
+	count := 0
+	for i, j := 0, 0; i < len(x) && j < len(y); {
+		switch {
+		case x[i] < y[j]:
+			i++
+		case x[i] > y[j]:
+			j++
+		default:
+			count++
+			i++
+			j++
+		}
+	}
+	return count
+}
+
+// sampleTransitivity estimates transitivity as the fraction of closed
+// wedges among samples wedges drawn uniformly at random.
+func (nb *neighborLists) sampleTransitivity(ctx context.Context, samples int, seed uint64) (float64, error) {
+	n := len(nb.offs) - 1
+
+	// cumulative[v] is the number of wedges centred on pages up to v.
+	cumulative := make([]float64, n)
+	total := 0.0
+	for v := range n {
+		total += nb.wedges(uint32(v))
+		cumulative[v] = total
+	}
+	if total == 0 {
+		return 0, nil
+	}
+
+	rng := rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))
+	closed := 0
+	for i := range samples {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return 0, err
+			}
+		}
+
+		v := uint32(sort.SearchFloat64s(cumulative, rng.Float64()*total))
+		for nb.degree(v) < 2 {
+			// Float rounding can land on a page with no wedges.
+			v++
+		}
+		neighbors := nb.neighbors(v)
+		x := rng.IntN(len(neighbors))
+		y := rng.IntN(len(neighbors) - 1)
+		if y >= x {
+			y++
+		}
+		if nb.linked(neighbors[x], neighbors[y]) {
+			closed++
+		}
+	}
+	return float64(closed) / float64(samples), nil
+}
+
+// samplePaths fills in the path statistics from a BFS over the out-links
+// of each sampled source.
+func (s *Statistics) samplePaths(ctx context.Context, a Adjacency, opts StatisticsOptions) error {
+	n := a.NodeCount()
+	if n < 2 {
+		return nil
+	}
+
+	var sources []uint32
+	if opts.PathSamples >= n {
+		sources = make([]uint32, n)
+		for i := range sources {
+			sources[i] = uint32(i)
+		}
+	} else {
+		sources = samplePivots(n, opts.PathSamples, opts.Seed)
+	}
+	s.PathSamples = len(sources)
+
+	longest, pairs, sum, err := pathTotals(ctx, a, sources, nil, opts.Workers)
+	if err != nil {
+		return err
+	}
+
+	s.Diameter = int(longest)
+	if pairs > 0 {
+		s.AveragePathLength = float64(sum) / float64(pairs)
+	}
+	s.Reachability = float64(pairs) / (float64(len(sources)) * float64(n-1))
+	return nil
+}
+
+// pathTotals runs a BFS over the out-links of each source, skipping the
+// pages marked in removed, which may be nil. It returns the longest
+// shortest path found, the number of pages reached from each source summed
+// over the sources, and the sum of their distances.
+func pathTotals(ctx context.Context, a Adjacency, sources []uint32, removed []bool, workers int) (longest int32, pairs, sum int64, err error) {
+	n := a.NodeCount()
+	var (
+		next atomic.Int64
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+	)
+	for range min(workers, len(sources)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			dist := make([]int32, n)
+			for i := range dist {
+				dist[i] = -1
+			}
+			var queue []uint32
+			var workerLongest int32
+			var workerPairs, workerSum int64
+
+			for ctx.Err() == nil {
+				i := next.Add(1) - 1
+				if i >= int64(len(sources)) {
+					break
+				}
+
+				src := sources[i]
+				queue = append(queue[:0], src)
+				dist[src] = 0
+				for j := 0; j < len(queue); j++ {
+					v := queue[j]
+					for _, w := range a.Out(v) {
+						if dist[w] < 0 && (removed == nil || !removed[w]) {
+							dist[w] = dist[v] + 1
+							queue = append(queue, w)
+						}
+					}
+				}
+
+				for _, v := range queue[1:] {
+					workerLongest = max(workerLongest, dist[v])
+					workerSum += int64(dist[v])
+				}
+				workerPairs += int64(len(queue) - 1)
+				for _, v := range queue {
+					dist[v] = -1
+				}
+			}
+
+			mu.Lock()
+			longest = max(longest, workerLongest)
+			pairs += workerPairs
+			sum += workerSum
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return 0, 0, 0, err
+	}
+	return longest, pairs, sum, nil
+}
@@ -0,0 +1,126 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestComputeStatistics(t *testing.T) {
+	// A triangle with one reciprocal pair, and C linking out to D.
+	c := buildCSR([][2]string{
+		{"A", "B"}, {"B", "A"}, {"B", "C"}, {"C", "A"}, {"C", "D"},
+	})
+
+	s, err := ComputeStatistics(context.Background(), c, StatisticsOptions{ExactClustering: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Nodes != 4 || s.Edges != 5 {
+		t.Errorf("Nodes, Edges = %d, %d, want 4, 5", s.Nodes, s.Edges)
+	}
+	if s.Dangling != 1 || s.Orphans != 0 {
+		t.Errorf("Dangling, Orphans = %d, %d, want 1, 0", s.Dangling, s.Orphans)
+	}
+	if !approx(s.Reciprocity, 0.4) {
+		t.Errorf("Reciprocity = %g, want 2 of 5 links", s.Reciprocity)
+	}
+	// One triangle and five wedges: one each at A and B, three at C.
+	if !approx(s.Clustering, 0.6) {
+		t.Errorf("Clustering = %g, want 3/5", s.Clustering)
+	}
+
+	if s.PathSamples != 4 || s.Diameter != 3 {
+		t.Errorf("PathSamples, Diameter = %d, %d, want 4, 3", s.PathSamples, s.Diameter)
+	}
+	if !approx(s.AveragePathLength, 14.0/9) {
+		t.Errorf("AveragePathLength = %g, want 14/9", s.AveragePathLength)
+	}
+	if !approx(s.Reachability, 0.75) {
+		t.Errorf("Reachability = %g, want 9/12", s.Reachability)
+	}
+
+	out := s.OutDegree
+	if !approx(out.Mean, 1.25) || out.Max != 2 {
+		t.Errorf("out-degree mean, max = %g, %d, want 1.25, 2", out.Mean, out.Max)
+	}
+	wantBuckets := []DegreeBucket{{0, 0, 1}, {1, 1, 1}, {2, 3, 2}}
+	if len(out.Histogram) != len(wantBuckets) {
+		t.Fatalf("Histogram = %+v, want %+v", out.Histogram, wantBuckets)
+	}
+	for i, b := range wantBuckets {
+		if out.Histogram[i] != b {
+			t.Errorf("Histogram[%d] = %+v, want %+v", i, out.Histogram[i], b)
+		}
+	}
+	if p := out.Percentiles[0]; p.Percentile != 50 || p.Degree != 1 {
+		t.Errorf("median = %+v, want degree 1", p)
+	}
+	if p := out.Percentiles[1]; p.Percentile != 90 || p.Degree != 2 {
+		t.Errorf("p90 = %+v, want degree 2", p)
+	}
+}
+
+func TestComputeStatisticsHubsAndAuthorities(t *testing.T) {
+	c := buildCSR([][2]string{
+		{"H1", "X"}, {"H2", "X"}, {"H3", "X"}, {"H1", "Y"},
+	})
+
+	s, err := ComputeStatistics(context.Background(), c, StatisticsOptions{Top: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Hubs) != 2 || s.Hubs[0].Title != "H1" {
+		t.Errorf("Hubs = %+v, want H1 first", s.Hubs)
+	}
+	if len(s.Authorities) != 2 || s.Authorities[0].Title != "X" || s.Authorities[1].Title != "Y" {
+		t.Errorf("Authorities = %+v, want X then Y", s.Authorities)
+	}
+}
+
+func TestComputeStatisticsSampledClustering(t *testing.T) {
+	// Two 4-cliques joined at A1 and B1: 8 triangles over 30 wedges.
+	c := twoCliqueGraph()
+	ctx := context.Background()
+
+	exact, err := ComputeStatistics(ctx, c, StatisticsOptions{ExactClustering: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !approx(exact.Clustering, 0.8) {
+		t.Errorf("exact Clustering = %g, want 0.8", exact.Clustering)
+	}
+
+	sampled, err := ComputeStatistics(ctx, c, StatisticsOptions{Seed: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sampled.ClusteringExact || math.Abs(sampled.Clustering-0.8) > 0.02 {
+		t.Errorf("sampled Clustering = %g, want about 0.8", sampled.Clustering)
+	}
+}
+
+func TestComputeStatisticsEmpty(t *testing.T) {
+	s, err := ComputeStatistics(context.Background(), New().Adjacency(), StatisticsOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Nodes != 0 || s.Clustering != 0 || s.Diameter != 0 {
+		t.Errorf("empty graph stats = %+v", s)
+	}
+}
+
+func TestComputeStatisticsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ComputeStatistics(ctx, twoCliqueGraph(), StatisticsOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func approx(got, want float64) bool {
+	return math.Abs(got-want) < 1e-9
+}
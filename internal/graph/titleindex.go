@@ -0,0 +1,361 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/wikititle"
+)
+
+// TitleIndex finds pages by the start of their title, ignoring case, and
+// suggests titles close to a misspelled one. Redirect titles are indexed
+// too and lead to the page they redirect to.
+//
+// The index is a sorted array of folded titles, searched as an implicit
+// trie: the titles below a trie node are a contiguous range, so a typo-
+// tolerant search walks the array with one row of edit distances per
+// character and stops where every distance is too large. An index is built
+// for one graph and is safe for concurrent use.
+type TitleIndex struct {
+	a       Adjacency
+	entries []titleEntry
+}
+
+type titleEntry struct {
+	key   string // the folded title
+	alias string // the redirect title, empty for the page's own title
+	page  uint32
+	links int32 // in-links of page
+}
+
+// TitleMatch is a page found by a title search. Matched is the redirect
+// title that matched, empty if the page's own title did. Edits is the
+// number of typos (insertions, deletions, substitutions or swaps of
+// adjacent letters) between the query and the matched title, or its start
+// for completions.
+type TitleMatch struct {
+	Title   string
+	Matched string
+	Edits   int
+	InLinks int
+}
+
+// NewTitleIndex indexes the titles of v's pages and redirects. Returns
+// ctx.Err() if ctx is done first.
+func NewTitleIndex(ctx context.Context, v View) (*TitleIndex, error) {
+	a := v.Adjacency()
+	r := redirectsOf(v)
+	x := &TitleIndex{a: a, entries: make([]titleEntry, 0, a.NodeCount()+r.Len())}
+
+	for u := range uint32(a.NodeCount()) {
+		if u%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		x.entries = append(x.entries, titleEntry{key: foldTitle(a.Title(u)), page: u, links: int32(len(a.In(u)))})
+	}
+	i := 0
+	for from, to := range r.All() {
+		if i++; i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+		if u, ok := a.Lookup(to); ok {
+			x.entries = append(x.entries, titleEntry{key: foldTitle(from), alias: from, page: u, links: int32(len(a.In(u)))})
+		}
+	}
+
+	slices.SortFunc(x.entries, func(p, q titleEntry) int {
+		if c := strings.Compare(p.key, q.key); c != 0 {
+			return c
+		}
+		return strings.Compare(p.alias, q.alias)
+	})
+	return x, nil
+}
+
+// Len returns the number of titles indexed, counting redirects.
+func (x *TitleIndex) Len() int {
+	return len(x.entries)
+}
+
+// Lookup returns the page whose title, or a redirect to it, matches title
+// ignoring case. It reports false when no page matches or several do.
+func (x *TitleIndex) Lookup(title string) (string, bool) {
+	q := foldTitle(title)
+	i := sort.Search(len(x.entries), func(i int) bool { return x.entries[i].key >= q })
+	if i == len(x.entries) || x.entries[i].key != q {
+		return "", false
+	}
+	page := x.entries[i].page
+	for _, e := range x.entries[i+1:] {
+		if e.key != q {
+			break
+		}
+		if e.page != page {
+			return "", false
+		}
+	}
+	return x.a.Title(page), true
+}
+
+// Complete returns up to limit pages whose title, or a redirect to them,
+// starts with query, ignoring case. When fewer than limit do, titles
+// starting within a typo or two of query fill the rest. Matches come
+// fewest typos first, then an exact title before completions of it, then
+// most linked first.
+func (x *TitleIndex) Complete(query string, limit int) []TitleMatch {
+	q := foldTitle(query)
+	if q == "" || limit <= 0 {
+		return nil
+	}
+	top := x.newTopMatches(q, limit)
+
+	lo := sort.Search(len(x.entries), func(i int) bool { return x.entries[i].key >= q })
+	hi := lo + sort.Search(len(x.entries)-lo, func(i int) bool { return !strings.HasPrefix(x.entries[lo+i].key, q) })
+	top.addRange(lo, hi, 0)
+
+	if !top.full() {
+		x.fuzzySearch(top, q, true)
+	}
+	return top.matches()
+}
+
+// Suggest returns up to limit pages whose title, or a redirect to them, is
+// within a typo or two of title, ignoring case, fewest typos first. When
+// fewer than limit are, completions of title fill the rest. It answers
+// "did you mean" for a title that is not in the graph.
+func (x *TitleIndex) Suggest(title string, limit int) []TitleMatch {
+	q := foldTitle(title)
+	if q == "" || limit <= 0 {
+		return nil
+	}
+	top := x.newTopMatches(q, limit)
+	x.fuzzySearch(top, q, false)
+	matches := top.matches()
+	if len(matches) == limit {
+		return matches
+	}
+
+	for _, m := range x.Complete(title, limit) {
+		if len(matches) == limit {
+			break
+		}
+		if !slices.ContainsFunc(matches, func(s TitleMatch) bool { return s.Title == m.Title }) {
+			matches = append(matches, m)
+		}
+	}
+	return matches
+}
+
+// maxTypos is the number of typos tolerated in a query of n letters: none
+// for very short queries, where almost everything is a typo or two away.
+func maxTypos(n int) int {
+	switch {
+	case n < 3:
+		return 0
+	case n < 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzySearch adds the entries within maxTypos of q to top: the whole key
+// or, with prefix set, its closest start. Exact prefix matches are left to
+// Complete, which adds them first.
+func (x *TitleIndex) fuzzySearch(top *topMatches, q string, prefix bool) {
+	query := []rune(q)
+	k := maxTypos(len(query))
+	if k == 0 && prefix {
+		return
+	}
+	m := len(query)
+
+	// rows[d] holds the edit distances between the first d letters of the
+	// current trie path and each prefix of the query; path holds the letters.
+	rows := [][]int{make([]int, m+1)}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	var path []rune
+
+	// visit searches the entries in [lo, hi), which share their first off
+	// bytes, at trie depth d. best is the fewest typos between q and any
+	// start of the path so far, in prefix mode.
+	var visit func(lo, hi, off, d, best int)
+	visit = func(lo, hi, off, d, best int) {
+		row := rows[d]
+		least := slices.Min(row)
+		if prefix {
+			best = min(best, row[m])
+			if best == 0 {
+				return
+			}
+			if best <= k && least >= best {
+				// No longer path gets closer: every entry below is a match.
+				top.addRange(lo, hi, best)
+				return
+			}
+		}
+		if least > k {
+			return
+		}
+
+		// Entries ending here sort first.
+		i := lo
+		for i < hi && len(x.entries[i].key) == off {
+			i++
+		}
+		switch {
+		case prefix && best <= k:
+			top.addRange(lo, i, best)
+		case !prefix && row[m] <= k:
+			top.addRange(lo, i, row[m])
+		}
+
+		if len(rows) == d+1 {
+			rows = append(rows, make([]int, m+1))
+			path = append(path, 0)
+		}
+		next := rows[d+1]
+		for i < hi {
+			r, size := utf8.DecodeRuneInString(x.entries[i].key[off:])
+			letter := x.entries[i].key[off : off+size]
+			end := i + sort.Search(hi-i, func(j int) bool {
+				return !strings.HasPrefix(x.entries[i+j].key[off:], letter)
+			})
+
+			// One more row of the optimal string alignment distance.
+			next[0] = row[0] + 1
+			for j := 1; j <= m; j++ {
+				cost := 1
+				if query[j-1] == r {
+					cost = 0
+				}
+				next[j] = min(row[j]+1, next[j-1]+1, row[j-1]+cost)
+				if d > 0 && j > 1 && r == query[j-2] && path[d-1] == query[j-1] {
+					next[j] = min(next[j], rows[d-1][j-2]+1)
+				}
+			}
+			path[d] = r
+			visit(i, end, off+size, d+1, best)
+			i = end
+		}
+	}
+	visit(0, len(x.entries), 0, 0, k+1)
+}
+
+// topMatches keeps the best matches seen, one per page, best first.
+type topMatches struct {
+	x     *TitleIndex
+	query string
+	limit int
+	items []topMatch
+}
+
+type topMatch struct {
+	entry *titleEntry
+	edits int
+	exact bool
+}
+
+func (x *TitleIndex) newTopMatches(query string, limit int) *topMatches {
+	return &topMatches{x: x, query: query, limit: limit}
+}
+
+func (t *topMatches) full() bool {
+	return len(t.items) == t.limit
+}
+
+// compareMatches orders by typos, then exact titles first, then most
+// linked, then own titles before redirects, then by index order.
+func compareMatches(p, q topMatch) int {
+	if c := cmp.Compare(p.edits, q.edits); c != 0 {
+		return c
+	}
+	if p.exact != q.exact {
+		if p.exact {
+			return -1
+		}
+		return 1
+	}
+	if c := cmp.Compare(q.entry.links, p.entry.links); c != 0 {
+		return c
+	}
+	if (p.entry.alias == "") != (q.entry.alias == "") {
+		if p.entry.alias == "" {
+			return -1
+		}
+		return 1
+	}
+	if c := strings.Compare(p.entry.key, q.entry.key); c != 0 {
+		return c
+	}
+	return strings.Compare(p.entry.alias, q.entry.alias)
+}
+
+func (t *topMatches) addRange(lo, hi, edits int) {
+	for i := lo; i < hi; i++ {
+		e := &t.x.entries[i]
+		t.add(topMatch{entry: e, edits: edits, exact: e.key == t.query})
+	}
+}
+
+func (t *topMatches) add(m topMatch) {
+	if t.full() && compareMatches(m, t.items[len(t.items)-1]) >= 0 {
+		return
+	}
+	if i := slices.IndexFunc(t.items, func(o topMatch) bool { return o.entry.page == m.entry.page }); i >= 0 {
+		if compareMatches(m, t.items[i]) >= 0 {
+			return
+		}
+		t.items = slices.Delete(t.items, i, i+1)
+	}
+	i, _ := slices.BinarySearchFunc(t.items, m, compareMatches)
+	t.items = slices.Insert(t.items, i, m)
+	if len(t.items) > t.limit {
+		t.items = t.items[:t.limit]
+	}
+}
+
+func (t *topMatches) matches() []TitleMatch {
+	out := make([]TitleMatch, len(t.items))
+	for i, m := range t.items {
+		out[i] = TitleMatch{
+			Title:   t.x.a.Title(m.entry.page),
+			Matched: m.entry.alias,
+			Edits:   m.edits,
+			InLinks: int(m.entry.links),
+		}
+	}
+	return out
+}
+
+// foldTitle returns the form titles are matched in: normalized, then
+// lower-cased.
+func foldTitle(title string) string {
+	return strings.ToLower(wikititle.Normalize(title))
+}
+
+// redirectsOf returns the redirects a view resolves.
+func redirectsOf(v View) *Redirects {
+	switch v := v.(type) {
+	case *Graph:
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		return v.redirects
+	case *CSR:
+		return v.redirects
+	case *Overlay:
+		return v.base.redirects
+	default:
+		return nil
+	}
+}
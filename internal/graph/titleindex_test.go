@@ -0,0 +1,171 @@
+package graph
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"testing"
+)
+
+func TestTitleIndex(t *testing.T) {
+	c := buildCSR([][2]string{
+		{"Cat", "Albert Einstein"}, {"Dog", "Albert Einstein"}, {"Cat", "Albert Camus"},
+		{"Cat", "Alberta"}, {"Dog", "Alberta"}, {"Fish", "Alberta"},
+		{"Cat", "Dog"},
+	})
+	c.redirects, _ = ResolveRedirects(map[string]string{"Einstein": "Albert Einstein"})
+	x, err := NewTitleIndex(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Len() != c.NodeCount()+1 {
+		t.Errorf("Len() = %d, want %d", x.Len(), c.NodeCount()+1)
+	}
+
+	// Prefix matches ignore case; the most linked come first.
+	got := titlesOf(x.Complete("alber", 10))
+	if !equalSlices(got, []string{"Alberta", "Albert Einstein", "Albert Camus"}) {
+		t.Errorf("Complete(alber) = %v", got)
+	}
+	// An exact title comes before longer ones even when less linked.
+	got = titlesOf(x.Complete("albert", 10))
+	if len(got) == 0 || got[0] != "Alberta" {
+		t.Errorf("Complete(albert) = %v, want Alberta first", got)
+	}
+	if got := x.Complete("albert_e", 10); len(got) == 0 || got[0].Title != "Albert Einstein" || got[0].Edits != 0 {
+		t.Errorf("Complete(albert_e) = %+v, want Albert Einstein first", got)
+	}
+
+	// Redirect titles lead to their page.
+	if got := x.Complete("einst", 10); len(got) != 1 || got[0].Title != "Albert Einstein" || got[0].Matched != "Einstein" {
+		t.Errorf("Complete(einst) = %+v, want Albert Einstein via Einstein", got)
+	}
+
+	// Typos fill in when nothing starts with the query.
+	if got := x.Complete("albret ein", 10); len(got) != 1 || got[0].Title != "Albert Einstein" || got[0].Edits != 1 {
+		t.Errorf("Complete(albret ein) = %+v, want Albert Einstein with 1 typo", got)
+	}
+	if got := x.Complete("xyz", 10); len(got) != 0 {
+		t.Errorf("Complete(xyz) = %+v, want none", got)
+	}
+	if got := x.Complete("al", 1); len(got) != 1 {
+		t.Errorf("Complete(al, 1) = %+v, want 1 match", got)
+	}
+
+	if page, ok := x.Lookup("albert einstein"); !ok || page != "Albert Einstein" {
+		t.Errorf("Lookup(albert einstein) = %q, %v, want Albert Einstein", page, ok)
+	}
+	if page, ok := x.Lookup("EINSTEIN"); !ok || page != "Albert Einstein" {
+		t.Errorf("Lookup(EINSTEIN) = %q, %v, want Albert Einstein", page, ok)
+	}
+	if _, ok := x.Lookup("albert"); ok {
+		t.Error("Lookup(albert): want no match")
+	}
+
+	got = titlesOf(x.Suggest("albert einstien", 5))
+	if len(got) == 0 || got[0] != "Albert Einstein" {
+		t.Errorf("Suggest(albert einstien) = %v, want Albert Einstein first", got)
+	}
+	if got := titlesOf(x.Suggest("Dgo", 5)); !equalSlices(got, []string{"Dog"}) {
+		t.Errorf("Suggest(Dgo) = %v, want [Dog]", got)
+	}
+}
+
+func titlesOf(matches []TitleMatch) []string {
+	var titles []string
+	for _, m := range matches {
+		titles = append(titles, m.Title)
+	}
+	return titles
+}
+
+func TestTitleIndexMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewPCG(3, 3))
+	letters := []rune("abcdé")
+	word := func(n int) string {
+		var b strings.Builder
+		for range n {
+			b.WriteRune(letters[rng.IntN(len(letters))])
+		}
+		return b.String()
+	}
+
+	b := NewCSRBuilder(0, 0)
+	for range 400 {
+		b.AddEdge(word(1+rng.IntN(7)), word(1+rng.IntN(7)))
+	}
+	c := b.Build()
+	x, err := NewTitleIndex(context.Background(), c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range 200 {
+		q := word(1 + rng.IntN(7))
+		k := maxTypos(len([]rune(q)))
+
+		// Every page within k typos, as a whole and by its closest start.
+		wantWhole := make(map[string]int)
+		wantPrefix := make(map[string]int)
+		for u := range uint32(c.NodeCount()) {
+			key := []rune(foldTitle(c.Title(u)))
+			if d := osaDistance(key, []rune(q)); d <= k {
+				wantWhole[c.Title(u)] = d
+			}
+			best := k + 1
+			for n := 0; n <= len(key); n++ {
+				best = min(best, osaDistance(key[:n], []rune(q)))
+			}
+			if best <= k {
+				wantPrefix[c.Title(u)] = best
+			}
+		}
+
+		top := x.newTopMatches(q, c.NodeCount())
+		x.fuzzySearch(top, q, false)
+		checkMatches(t, "whole "+q, top.matches(), wantWhole)
+
+		checkMatches(t, "prefix "+q, x.Complete(q, c.NodeCount()), wantPrefix)
+	}
+}
+
+func checkMatches(t *testing.T, name string, got []TitleMatch, want map[string]int) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("%s: %d matches, want %d", name, len(got), len(want))
+		return
+	}
+	for i, m := range got {
+		if d, ok := want[m.Title]; !ok || d != m.Edits {
+			t.Errorf("%s: %s with %d typos, want %d (%v)", name, m.Title, m.Edits, d, ok)
+		}
+		if i > 0 && got[i-1].Edits > m.Edits {
+			t.Errorf("%s: matches not ordered by typos: %+v", name, got)
+		}
+	}
+}
+
+// osaDistance is the optimal string alignment distance, computed directly.
+func osaDistance(a, b []rune) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
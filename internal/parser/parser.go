@@ -5,13 +5,31 @@ import (
 	"bytes"
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+
+	"github.com/Thinh-nguyen-03/wikigraph/internal/wikititle"
 )
 
+// Link is a link from an article to another article. Position orders the
+// links of a page: it counts every link to an article in document order,
+// and a target linked several times keeps the position of its first
+// plain occurrence, if it has one, or else of its first occurrence.
 type Link struct {
-	Title string
+	Title     string
+	Position  int
+	InParens  bool // inside parentheses in the surrounding text
+	InItalics bool // inside <i> or <em>
+	InProse   bool // in a paragraph or list of the article text, not in an infobox, table, hatnote or navigation box
+}
+
+// Plain reports whether the link is in the article's prose and outside
+// parentheses and italics, as the "first link" of a page must be.
+func (l Link) Plain() bool {
+	return l.InProse && !l.InParens && !l.InItalics
 }
 
 var excludedNamespaces = map[string]bool{
@@ -32,28 +50,134 @@ var excludedNamespaces = map[string]bool{
 	"Module":         true,
 }
 
+// blockElements start a new run of text: parentheses opened in one do not
+// carry over into the next.
+var blockElements = map[string]bool{
+	"p": true, "li": true, "dd": true, "dt": true, "div": true, "blockquote": true,
+	"table": true, "td": true, "th": true, "caption": true, "figure": true, "figcaption": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+}
+
+// asideClasses mark page furniture that is not part of the article text.
+var asideClasses = []string{
+	"infobox", "sidebar", "navbox", "hatnote", "thumb", "metadata",
+	"reflist", "references", "mw-references-wrap", "shortdescription", "toc",
+}
+
 func ExtractLinks(doc *goquery.Document) []Link {
-	seen := make(map[string]bool)
-	var links []Link
+	e := &linkExtractor{seen: make(map[string]int)}
+	for _, root := range doc.Find("#mw-content-text").Nodes {
+		e.walk(root, linkContext{parens: new(int)})
+	}
+	return e.links
+}
+
+// linkContext is what the walk knows about the surroundings of a node.
+type linkContext struct {
+	parens  *int // parentheses open in the enclosing block's text so far
+	italics bool
+	prose   bool
+	aside   bool
+}
+
+type linkExtractor struct {
+	links    []Link
+	seen     map[string]int // index in links by title
+	position int
+}
 
-	doc.Find("#mw-content-text a[href^='/wiki/']").Each(func(_ int, s *goquery.Selection) {
-		href, exists := s.Attr("href")
-		if !exists {
+// walk visits n and its descendants in document order.
+func (e *linkExtractor) walk(n *html.Node, ctx linkContext) {
+	switch n.Type {
+	case html.TextNode:
+		for _, r := range n.Data {
+			switch {
+			case r == '(':
+				*ctx.parens++
+			case r == ')' && *ctx.parens > 0:
+				*ctx.parens--
+			}
+		}
+		return
+	case html.ElementNode:
+		switch n.Data {
+		case "script", "style":
 			return
+		case "i", "em":
+			ctx.italics = true
+		case "table", "figure":
+			ctx.aside = true
+		}
+		if hasAsideClass(n) {
+			ctx.aside = true
 		}
+		if blockElements[n.Data] {
+			ctx.parens = new(int)
+		}
+		switch n.Data {
+		case "p", "li", "dd":
+			ctx.prose = true
+		}
+		if ctx.aside {
+			ctx.prose = false
+		}
+		if n.Data == "a" {
+			e.link(n, ctx)
+		}
+	}
 
-		title := extractTitle(href)
-		if title == "" || seen[title] || shouldExclude(title) {
-			return
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		e.walk(c, ctx)
+	}
+}
+
+// link records the anchor n if it points to an article.
+func (e *linkExtractor) link(n *html.Node, ctx linkContext) {
+	href := attr(n, "href")
+	if !strings.HasPrefix(href, "/wiki/") {
+		return
+	}
+	title := extractTitle(href)
+	if title == "" || shouldExclude(title) {
+		return
+	}
+
+	link := Link{
+		Title:     title,
+		Position:  e.position,
+		InParens:  *ctx.parens > 0,
+		InItalics: ctx.italics,
+		InProse:   ctx.prose,
+	}
+	e.position++
+
+	if i, ok := e.seen[title]; ok {
+		if !e.links[i].Plain() && link.Plain() {
+			e.links[i] = link
 		}
+		return
+	}
+	e.seen[title] = len(e.links)
+	e.links = append(e.links, link)
+}
 
-		seen[title] = true
-		links = append(links, Link{
-			Title: title,
-		})
-	})
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
 
-	return links
+func hasAsideClass(n *html.Node) bool {
+	classes := strings.Fields(attr(n, "class"))
+	for _, aside := range asideClasses {
+		if slices.Contains(classes, aside) {
+			return true
+		}
+	}
+	return false
 }
 
 func ExtractLinksFromHTML(html string) ([]Link, error) {
@@ -88,7 +212,7 @@ func extractTitle(href string) string {
 		return path
 	}
 
-	return strings.ReplaceAll(decoded, "_", " ")
+	return wikititle.Normalize(decoded)
 }
 
 func shouldExclude(title string) bool {
@@ -166,3 +166,48 @@ func TestExtractLinksFromHTML(t *testing.T) {
 		t.Errorf("got %d links, want 1", len(links))
 	}
 }
+
+func TestExtractLinks_RecordsOrderAndContext(t *testing.T) {
+	html := `
+	<div id="mw-content-text"><div class="mw-parser-output">
+		<div class="hatnote">For other uses, see <a href="/wiki/Hat">Hat</a>.</div>
+		<table class="infobox"><tr><td><a href="/wiki/Mammal">Mammal</a></td></tr></table>
+		<p>The <b>cat</b> (<i>Felis catus</i>, see <a href="/wiki/Latin">Latin</a>) is a
+		<i><a href="/wiki/Italic">small</a></i> domestic <a href="/wiki/Species">species</a>
+		of <a href="/wiki/Mammal">mammal</a>.</p>
+		<p>Unclosed ( <a href="/wiki/Other">other</a></p>
+		<p><a href="/wiki/Next">next</a></p>
+	</div></div>`
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	links := ExtractLinks(doc)
+
+	want := []Link{
+		{Title: "Hat", Position: 0},
+		{Title: "Mammal", Position: 5, InProse: true},
+		{Title: "Latin", Position: 2, InParens: true, InProse: true},
+		{Title: "Italic", Position: 3, InItalics: true, InProse: true},
+		{Title: "Species", Position: 4, InProse: true},
+		{Title: "Other", Position: 6, InParens: true, InProse: true},
+		{Title: "Next", Position: 7, InProse: true},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("got %+v, want %+v", links, want)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("link %d = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+
+	// The first plain link is the one a "first link" chain follows.
+	first := -1
+	for i, l := range links {
+		if l.Plain() && (first < 0 || l.Position < links[first].Position) {
+			first = i
+		}
+	}
+	if first < 0 || links[first].Title != "Species" {
+		t.Errorf("first plain link = %d, want Species", first)
+	}
+}
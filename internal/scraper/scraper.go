@@ -3,6 +3,7 @@ package scraper
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
 	"sync"
@@ -258,6 +259,10 @@ func (s *Scraper) processPageWorker(ctx context.Context, page *cache.Page) (targ
 	for i, link := range result.Links {
 		cacheLinks[i] = cache.Link{
 			TargetTitle: link.Title,
+			Position:    sql.NullInt64{Int64: int64(link.Position), Valid: true},
+			InParens:    link.InParens,
+			InItalics:   link.InItalics,
+			InProse:     link.InProse,
 		}
 		targetTitles[i] = link.Title
 	}
@@ -276,6 +281,16 @@ func (s *Scraper) processPageWorker(ctx context.Context, page *cache.Page) (targ
 }
 
 func (s *Scraper) FetchSingle(ctx context.Context, title string) (*Stats, error) {
+	return s.fetchOne(ctx, title, false)
+}
+
+// Refetch fetches a page whatever its status, rewriting its links if its
+// content changed since the last fetch.
+func (s *Scraper) Refetch(ctx context.Context, title string) (*Stats, error) {
+	return s.fetchOne(ctx, title, true)
+}
+
+func (s *Scraper) fetchOne(ctx context.Context, title string, force bool) (*Stats, error) {
 	start := time.Now()
 	stats := &Stats{}
 
@@ -284,7 +299,7 @@ func (s *Scraper) FetchSingle(ctx context.Context, title string) (*Stats, error)
 		return stats, fmt.Errorf("creating page: %w", err)
 	}
 
-	if page.FetchStatus != cache.StatusPending {
+	if !force && page.FetchStatus != cache.StatusPending {
 		slog.Info("page already fetched", "title", title, "status", page.FetchStatus)
 		stats.PagesSkipped = 1
 		stats.Duration = time.Since(start)
@@ -0,0 +1,67 @@
+// Package wikititle normalizes page titles the way MediaWiki does, so that
+// "albert einstein", "Albert_Einstein" and "Albert Einstein" all name the
+// same page.
+package wikititle
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize returns the canonical form of a title: Unicode NFC, underscores
+// turned into spaces, runs of spaces collapsed, surrounding spaces trimmed
+// and the first letter capitalized. The rest of the title keeps its case,
+// since MediaWiki titles are case-sensitive after the first letter.
+func Normalize(title string) string {
+	if isNormalASCII(title) {
+		return title
+	}
+
+	title = norm.NFC.String(title)
+
+	var b strings.Builder
+	b.Grow(len(title))
+	space := false
+	for _, r := range title {
+		if r == '_' || unicode.IsSpace(r) {
+			space = b.Len() > 0
+			continue
+		}
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		if b.Len() == 0 {
+			r = unicode.ToUpper(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// isNormalASCII reports whether title is ASCII and already normalized, the
+// common case for titles taken from links.
+func isNormalASCII(title string) bool {
+	if title == "" {
+		return true
+	}
+	if c := title[0]; c == ' ' || ('a' <= c && c <= 'z') {
+		return false
+	}
+	if title[len(title)-1] == ' ' {
+		return false
+	}
+	for i := 0; i < len(title); i++ {
+		c := title[i]
+		if c >= utf8.RuneSelf || c == '_' || (c < ' ' || c == 0x7f) {
+			return false
+		}
+		if c == ' ' && title[i+1] == ' ' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,38 @@
+package wikititle
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Albert Einstein", "Albert Einstein"},
+		{"albert einstein", "Albert einstein"},
+		{"Albert_Einstein", "Albert Einstein"},
+		{"  Albert__Einstein ", "Albert Einstein"},
+		{"Albert \t Einstein", "Albert Einstein"},
+		{"iPhone", "IPhone"},
+		{"élan", "Élan"},
+		{"élan", "Élan"}, // decomposed é is composed first
+		{"Schrödinger's cat", "Schrödinger's cat"},
+		{"ǆungla", "Ǆungla"},
+		{"1984 (novel)", "1984 (novel)"},
+		{"_", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := Normalize(tt.in); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeIdempotent(t *testing.T) {
+	for _, s := range []string{"albert_einstein", " x  y ", "é", "Ωmega", "A"} {
+		once := Normalize(s)
+		if twice := Normalize(once); twice != once {
+			t.Errorf("Normalize(Normalize(%q)) = %q, want %q", s, twice, once)
+		}
+	}
+}